@@ -16,8 +16,10 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
 	"text/template"
@@ -25,6 +27,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/stats"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
@@ -78,6 +81,8 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	noCache := cmd.Bool([]string{"#no-cache", "-no-cache"}, false, "Do not use cache when building the image")
 	rm := cmd.Bool([]string{"#rm", "-rm"}, true, "Remove intermediate containers after a successful build")
 	forceRm := cmd.Bool([]string{"-force-rm"}, false, "Always remove intermediate containers, even after unsuccessful builds")
+	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
+	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -214,6 +219,19 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		v.Set("forcerm", "1")
 	}
 
+	buildArgs := map[string]string{}
+	for _, arg := range flBuildArg.GetAll() {
+		kv := strings.SplitN(arg, "=", 2)
+		buildArgs[kv[0]] = kv[1]
+	}
+	if len(buildArgs) > 0 {
+		buf, err := json.Marshal(buildArgs)
+		if err != nil {
+			return err
+		}
+		v.Set("buildargs", string(buf))
+	}
+
 	cli.LoadConfigFile()
 
 	headers := http.Header(make(map[string][]string))
@@ -731,6 +749,61 @@ func (cli *DockerCli) CmdUnpause(args ...string) error {
 	return encounteredError
 }
 
+func (cli *DockerCli) CmdUpdate(args ...string) error {
+	var (
+		cmd         = cli.Subcmd("update", "CONTAINER [CONTAINER...]", "Update the resource limits of one or more containers")
+		flMemory    = cmd.String([]string{"m", "-memory"}, "", "Memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flCpuShares = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
+		flCpuset    = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	// only send the limits which were given on the command line, the
+	// others are left unchanged
+	updateConfig := map[string]interface{}{}
+	var err error
+	cmd.Visit(func(f *flag.Flag) {
+		switch f.Names[0] {
+		case "m":
+			var memory int64
+			if *flMemory != "" {
+				if memory, err = units.RAMInBytes(*flMemory); err != nil {
+					return
+				}
+			}
+			updateConfig["Memory"] = memory
+		case "c":
+			updateConfig["CpuShares"] = *flCpuShares
+		case "-cpuset":
+			updateConfig["Cpuset"] = *flCpuset
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(updateConfig) == 0 {
+		return fmt.Errorf("You must provide one or more flags when using this command.")
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/containers/%s/update", name), updateConfig, false)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to update container named %s", name)
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
 func (cli *DockerCli) CmdPause(args ...string) error {
 	cmd := cli.Subcmd("pause", "CONTAINER", "Pause all processes within a container")
 	if err := cmd.Parse(args); err != nil {
@@ -754,6 +827,396 @@ func (cli *DockerCli) CmdPause(args ...string) error {
 	return encounteredError
 }
 
+func (cli *DockerCli) CmdRename(args ...string) error {
+	cmd := cli.Subcmd("rename", "OLD_NAME NEW_NAME", "Rename a container")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+	oldName := cmd.Arg(0)
+	newName := cmd.Arg(1)
+
+	if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/containers/%s/rename?name=%s", oldName, newName), nil, false)); err != nil {
+		fmt.Fprintf(cli.err, "%s\n", err)
+		return fmt.Errorf("Error: failed to rename container named %s", oldName)
+	}
+	return nil
+}
+
+// subcommandsUsage prints the usage of a command grouping subcommands, like
+// docker network, given the name and description of each of them.
+func (cli *DockerCli) subcommandsUsage(name, description string, args []string, commands [][]string) error {
+	cmd := cli.Subcmd(name, "COMMAND", description)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() > 0 {
+		fmt.Fprintf(cli.err, "Error: Command not found: %s %s\n", name, cmd.Arg(0))
+	}
+	fmt.Fprintf(cli.err, "\nUsage: docker %s COMMAND\n\n%s\n\nCommands:\n", name, description)
+	for _, command := range commands {
+		fmt.Fprintf(cli.err, "    %-10.10s%s\n", command[0], command[1])
+	}
+	fmt.Fprintf(cli.err, "\nRun 'docker %s COMMAND --help' for more information on a command.\n", name)
+	return nil
+}
+
+func (cli *DockerCli) CmdNetwork(args ...string) error {
+	return cli.subcommandsUsage("network", "Manage networks", args, [][]string{
+		{"connect", "Connect a container to a network"},
+		{"create", "Create a network"},
+		{"disconnect", "Disconnect a container from a network"},
+		{"ls", "List networks"},
+		{"rm", "Remove a network"},
+	})
+}
+
+func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
+	var (
+		cmd       = cli.Subcmd("network create", "NAME", "Create a new network")
+		flDriver  = cmd.String([]string{"d", "-driver"}, "bridge", "Driver to manage the network")
+		flSubnet  = cmd.String([]string{"-subnet"}, "", "Subnet in CIDR format, allocated automatically if empty")
+		flGateway = cmd.String([]string{"-gateway"}, "", "Gateway address for the subnet")
+		flIPRange = cmd.String([]string{"-ip-range"}, "", "Allocate container IPs from a sub-range of the subnet (CIDR)")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{
+		"Name":    cmd.Arg(0),
+		"Driver":  *flDriver,
+		"Subnet":  *flSubnet,
+		"Gateway": *flGateway,
+		"IPRange": *flIPRange,
+	}
+	stream, _, err := cli.call("POST", "/networks/create", config, false)
+	if err != nil {
+		return err
+	}
+	var out engine.Env
+	if err := out.Decode(stream); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", out.Get("Id"))
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkLs(args ...string) error {
+	var (
+		cmd     = cli.Subcmd("network ls", "", "List networks")
+		quiet   = cmd.Bool([]string{"q", "-quiet"}, false, "Only display numeric IDs")
+		noTrunc = cmd.Bool([]string{"-no-trunc"}, false, "Don't truncate output")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	body, _, err := readBody(cli.call("GET", "/networks", nil, false))
+	if err != nil {
+		return err
+	}
+	outs := engine.NewTable("", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "NETWORK ID\tNAME\tDRIVER\tSUBNET")
+	}
+	for _, out := range outs.Data {
+		outID := out.Get("Id")
+		if !*noTrunc {
+			outID = utils.TruncateID(outID)
+		}
+		if *quiet {
+			fmt.Fprintln(w, outID)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", outID, out.Get("Name"), out.Get("Driver"), out.Get("Subnet"))
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkRm(args ...string) error {
+	cmd := cli.Subcmd("network rm", "NETWORK [NETWORK...]", "Remove one or more networks")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		if _, _, err := readBody(cli.call("DELETE", "/networks/"+name, nil, false)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more networks")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
+func (cli *DockerCli) CmdNetworkConnect(args ...string) error {
+	cmd := cli.Subcmd("network connect", "NETWORK CONTAINER", "Connect a container to a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{"Container": cmd.Arg(1)}
+	if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/networks/%s/connect", cmd.Arg(0)), config, false)); err != nil {
+		fmt.Fprintf(cli.err, "%s\n", err)
+		return fmt.Errorf("Error: failed to connect container %s to network %s", cmd.Arg(1), cmd.Arg(0))
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkDisconnect(args ...string) error {
+	cmd := cli.Subcmd("network disconnect", "NETWORK CONTAINER", "Disconnect a container from a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{"Container": cmd.Arg(1)}
+	if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/networks/%s/disconnect", cmd.Arg(0)), config, false)); err != nil {
+		fmt.Fprintf(cli.err, "%s\n", err)
+		return fmt.Errorf("Error: failed to disconnect container %s from network %s", cmd.Arg(1), cmd.Arg(0))
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdVolume(args ...string) error {
+	return cli.subcommandsUsage("volume", "Manage volumes", args, [][]string{
+		{"create", "Create a volume"},
+		{"inspect", "Return low-level information on a volume"},
+		{"ls", "List volumes"},
+		{"prune", "Remove the volumes not used by any container"},
+		{"rm", "Remove a volume"},
+	})
+}
+
+func (cli *DockerCli) CmdVolumeCreate(args ...string) error {
+	var (
+		cmd      = cli.Subcmd("volume create", "", "Create a volume")
+		flName   = cmd.String([]string{"-name"}, "", "Name of the volume, generated if empty")
+		flDriver = cmd.String([]string{"d", "-driver"}, "local", "Driver to manage the volume")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{
+		"Name":   *flName,
+		"Driver": *flDriver,
+	}
+	stream, _, err := cli.call("POST", "/volumes/create", config, false)
+	if err != nil {
+		return err
+	}
+	var out engine.Env
+	if err := out.Decode(stream); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", out.Get("Name"))
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeLs(args ...string) error {
+	var (
+		cmd   = cli.Subcmd("volume ls", "", "List volumes")
+		quiet = cmd.Bool([]string{"q", "-quiet"}, false, "Only display volume names")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	body, _, err := readBody(cli.call("GET", "/volumes", nil, false))
+	if err != nil {
+		return err
+	}
+	outs := engine.NewTable("", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "DRIVER\tVOLUME NAME")
+	}
+	for _, out := range outs.Data {
+		if *quiet {
+			fmt.Fprintln(w, out.Get("Name"))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", out.Get("Driver"), out.Get("Name"))
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeInspect(args ...string) error {
+	cmd := cli.Subcmd("volume inspect", "VOLUME [VOLUME...]", "Return low-level information on a volume")
+	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template.")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var tmpl *template.Template
+	if *tmplStr != "" {
+		var err error
+		if tmpl, err = template.New("").Funcs(funcMap).Parse(*tmplStr); err != nil {
+			fmt.Fprintf(cli.err, "Template parsing error: %v\n", err)
+			return &utils.StatusError{StatusCode: 64,
+				Status: "Template parsing error: " + err.Error()}
+		}
+	}
+
+	var (
+		values []interface{}
+		status = 0
+	)
+	for _, name := range cmd.Args() {
+		obj, _, err := readBody(cli.call("GET", "/volumes/"+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(obj, &value); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		if tmpl == nil {
+			values = append(values, value)
+			continue
+		}
+		if err := tmpl.Execute(cli.out, value); err != nil {
+			return err
+		}
+		cli.out.Write([]byte{'\n'})
+	}
+
+	if tmpl == nil {
+		if values == nil {
+			values = []interface{}{}
+		}
+		b, err := json.MarshalIndent(values, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.out, "%s\n", b)
+	}
+
+	if status != 0 {
+		return &utils.StatusError{StatusCode: status}
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumePrune(args ...string) error {
+	var (
+		cmd    = cli.Subcmd("volume prune", "", "Remove the volumes not used by any container")
+		dryRun = cmd.Bool([]string{"n", "-dry-run"}, false, "Only list the volumes which would be removed")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	v := url.Values{}
+	if *dryRun {
+		v.Set("dryrun", "1")
+	}
+	body, _, err := readBody(cli.call("POST", "/volumes/prune?"+v.Encode(), nil, false))
+	if err != nil {
+		return err
+	}
+	var out struct {
+		Volumes []struct {
+			Name string
+			Size int64
+		}
+		SpaceReclaimed int64
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return err
+	}
+
+	for _, volume := range out.Volumes {
+		fmt.Fprintf(cli.out, "%s\n", volume.Name)
+	}
+	if *dryRun {
+		fmt.Fprintf(cli.out, "Total space which would be reclaimed: %s\n", units.HumanSize(out.SpaceReclaimed))
+	} else {
+		fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(out.SpaceReclaimed))
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeRm(args ...string) error {
+	cmd := cli.Subcmd("volume rm", "VOLUME [VOLUME...]", "Remove one or more volumes")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		if _, _, err := readBody(cli.call("DELETE", "/volumes/"+name, nil, false)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more volumes")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
 func (cli *DockerCli) CmdInspect(args ...string) error {
 	cmd := cli.Subcmd("inspect", "CONTAINER|IMAGE [CONTAINER|IMAGE...]", "Return low-level information on a container or image")
 	tmplStr := cmd.String([]string{"f", "#format", "-format"}, "", "Format the output using the given go template.")
@@ -869,6 +1332,176 @@ func (cli *DockerCli) CmdTop(args ...string) error {
 	return nil
 }
 
+type containerStats struct {
+	Name             string
+	CpuPercentage    float64
+	Memory           float64
+	MemoryLimit      float64
+	MemoryPercentage float64
+	NetworkRx        float64
+	NetworkTx        float64
+	mu               sync.RWMutex
+	err              error
+}
+
+func (s *containerStats) Collect(cli *DockerCli) {
+	stream, _, err := cli.call("GET", "/containers/"+s.Name+"/stats", nil, false)
+	if err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return
+	}
+	defer stream.Close()
+	var (
+		previousCpu    uint64
+		previousSystem uint64
+		start          = true
+		dec            = json.NewDecoder(stream)
+		u              = make(chan error, 1)
+	)
+	go func() {
+		for {
+			var v *stats.Stats
+			if err := dec.Decode(&v); err != nil {
+				u <- err
+				return
+			}
+			var (
+				memPercent = 0.0
+				cpuPercent = 0.0
+			)
+			if v.MemoryStats.Limit > 0 {
+				memPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
+			}
+			if !start {
+				cpuPercent = calculateCpuPercent(previousCpu, previousSystem, v)
+			}
+			start = false
+			s.mu.Lock()
+			s.CpuPercentage = cpuPercent
+			s.Memory = float64(v.MemoryStats.Usage)
+			s.MemoryLimit = float64(v.MemoryStats.Limit)
+			s.MemoryPercentage = memPercent
+			s.NetworkRx = float64(v.Network.RxBytes)
+			s.NetworkTx = float64(v.Network.TxBytes)
+			s.mu.Unlock()
+			previousCpu = v.CpuStats.CpuUsage.TotalUsage
+			previousSystem = v.CpuStats.SystemUsage
+			u <- nil
+		}
+	}()
+	for {
+		select {
+		case <-time.After(2 * time.Second):
+			// zero out the values if we have not received an update within
+			// the specified duration.
+			s.mu.Lock()
+			s.CpuPercentage = 0
+			s.Memory = 0
+			s.MemoryPercentage = 0
+			s.mu.Unlock()
+		case err := <-u:
+			if err != nil {
+				s.mu.Lock()
+				s.err = err
+				s.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+func (s *containerStats) Display(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.err != nil {
+		return s.err
+	}
+	fmt.Fprintf(w, "%s\t%.2f%%\t%s/%s\t%.2f%%\t%s/%s\n",
+		s.Name,
+		s.CpuPercentage,
+		units.BytesSize(s.Memory), units.BytesSize(s.MemoryLimit),
+		s.MemoryPercentage,
+		units.BytesSize(s.NetworkRx), units.BytesSize(s.NetworkTx))
+	return nil
+}
+
+func (cli *DockerCli) CmdStats(args ...string) error {
+	cmd := cli.Subcmd("stats", "CONTAINER [CONTAINER...]", "Display a live stream of one or more containers' resource usage statistics")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	names := cmd.Args()
+	sort.Strings(names)
+	var (
+		cStats []*containerStats
+		w      = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	)
+	printHeader := func() {
+		fmt.Fprint(cli.out, "\033[2J")
+		fmt.Fprint(cli.out, "\033[H")
+		fmt.Fprintln(w, "CONTAINER\tCPU %\tMEM USAGE/LIMIT\tMEM %\tNET I/O")
+	}
+	for _, n := range names {
+		s := &containerStats{Name: n}
+		cStats = append(cStats, s)
+		go s.Collect(cli)
+	}
+	// do a quick pause so that any failed connections for containers that do not exist are able to be
+	// evicted before we display the initial or default values.
+	time.Sleep(500 * time.Millisecond)
+	var errs []string
+	for _, c := range cStats {
+		c.mu.Lock()
+		if c.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", c.Name, c.err))
+		}
+		c.mu.Unlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	for _ = range time.Tick(500 * time.Millisecond) {
+		printHeader()
+		toRemove := []int{}
+		for i, s := range cStats {
+			if err := s.Display(w); err != nil {
+				toRemove = append(toRemove, i)
+			}
+		}
+		for j := len(toRemove) - 1; j >= 0; j-- {
+			i := toRemove[j]
+			cStats = append(cStats[:i], cStats[i+1:]...)
+		}
+		if len(cStats) == 0 {
+			return nil
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func calculateCpuPercent(previousCpu, previousSystem uint64, v *stats.Stats) float64 {
+	var (
+		cpuPercent = 0.0
+		// calculate the change for the cpu usage of the container in between readings
+		cpuDelta = float64(v.CpuStats.CpuUsage.TotalUsage - previousCpu)
+		// calculate the change for the entire system between readings
+		systemDelta = float64(v.CpuStats.SystemUsage - previousSystem)
+	)
+
+	if systemDelta > 0.0 && cpuDelta > 0.0 {
+		cpuPercent = (cpuDelta / systemDelta) * float64(len(v.CpuStats.CpuUsage.PercpuUsage)) * 100.0
+	}
+	return cpuPercent
+}
+
 func (cli *DockerCli) CmdPort(args ...string) error {
 	cmd := cli.Subcmd("port", "CONTAINER [PRIVATE_PORT[/PROTO]]", "List port mappings for the CONTAINER, or lookup the public-facing port that is NAT-ed to the PRIVATE_PORT")
 	if err := cmd.Parse(args); err != nil {
@@ -1094,6 +1727,8 @@ func (cli *DockerCli) CmdKill(args ...string) error {
 
 func (cli *DockerCli) CmdImport(args ...string) error {
 	cmd := cli.Subcmd("import", "URL|- [REPOSITORY[:TAG]]", "Create an empty filesystem image and import the contents of the tarball (.tar, .tar.gz, .tgz, .bzip, .tar.xz, .txz) into it, then optionally tag it.")
+	flChanges := opts.NewListOpts(nil)
+	cmd.Var(&flChanges, []string{"c", "-change"}, "Apply Dockerfile instruction to the created image")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1111,6 +1746,9 @@ func (cli *DockerCli) CmdImport(args ...string) error {
 
 	v.Set("fromSrc", src)
 	v.Set("repo", repository)
+	for _, change := range flChanges.GetAll() {
+		v.Add("changes", change)
+	}
 
 	if cmd.NArg() == 3 {
 		fmt.Fprintf(cli.err, "[DEPRECATED] The format 'URL|- [REPOSITORY [TAG]]' as been deprecated. Please use URL|- [REPOSITORY[:TAG]]\n")
@@ -1275,7 +1913,7 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 	flTree := cmd.Bool([]string{"#t", "#tree", "#-tree"}, false, "Output graph in tree format")
 
 	flFilter := opts.NewListOpts(nil)
-	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true')")
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true', 'label=<key>=<value>')")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1515,7 +2153,7 @@ func (cli *DockerCli) CmdPs(args ...string) error {
 		flFilter = opts.NewListOpts(nil)
 	)
 
-	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values. Valid filters:\nexited=<int> - containers with exit code of <int>\nstatus=(restarting|running|paused|exited)")
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values. Valid filters:\nexited=<int> - containers with exit code of <int>\nstatus=(restarting|running|paused|exited)\nlabel=<key> or label=<key>=<value>\nancestor=(<image-name>[:tag]|<image-id>)\nname=<regexp> - containers with a matching name\nid=<prefix> - containers with an ID starting with <prefix>")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1654,6 +2292,8 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	flPause := cmd.Bool([]string{"p", "-pause"}, true, "Pause container during commit")
 	flComment := cmd.String([]string{"m", "-message"}, "", "Commit message")
 	flAuthor := cmd.String([]string{"a", "#author", "-author"}, "", "Author (e.g., \"John Hannibal Smith <hannibal@a-team.com>\")")
+	flChanges := opts.NewListOpts(nil)
+	cmd.Var(&flChanges, []string{"c", "-change"}, "Apply Dockerfile instruction to the created image")
 	// FIXME: --run is deprecated, it will be replaced with inline Dockerfile commands.
 	flConfig := cmd.String([]string{"#run", "#-run"}, "", "This option is deprecated and will be removed in a future version in favor of inline Dockerfile-compatible commands")
 	if err := cmd.Parse(args); err != nil {
@@ -1683,6 +2323,9 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	v.Set("tag", tag)
 	v.Set("comment", *flComment)
 	v.Set("author", *flAuthor)
+	for _, change := range flChanges.GetAll() {
+		v.Add("changes", change)
+	}
 
 	if *flPause != true {
 		v.Set("pause", "0")
@@ -2363,7 +3006,7 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 }
 
 func (cli *DockerCli) CmdCp(args ...string) error {
-	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTPATH", "Copy files/folders from the PATH to the HOSTPATH")
+	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTPATH|-\n       docker cp HOSTPATH|- CONTAINER:PATH", "Copy files/folders between a container and the host.\nUse '-' as the host path to write a tar archive of the files of the container to STDOUT,\nor to extract a tar archive read from STDIN into a directory of the container.")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -2373,35 +3016,110 @@ func (cli *DockerCli) CmdCp(args ...string) error {
 		return nil
 	}
 
-	var copyData engine.Env
-	info := strings.Split(cmd.Arg(0), ":")
+	src, dst := cmd.Arg(0), cmd.Arg(1)
+	srcContainer, srcPath := splitCpArg(src)
+	dstContainer, dstPath := splitCpArg(dst)
+	switch {
+	case srcContainer != "" && dstContainer != "":
+		return fmt.Errorf("Error: copying between containers is not supported")
+	case srcContainer != "":
+		return cli.copyFromContainer(srcContainer, srcPath, dst)
+	case dstContainer != "":
+		return cli.copyToContainer(src, dstContainer, dstPath)
+	}
+	return fmt.Errorf("Error: Path not specified")
+}
 
-	if len(info) != 2 {
-		return fmt.Errorf("Error: Path not specified")
+// splitCpArg splits a CONTAINER:PATH argument of cp. The container is empty
+// for the paths of the host, which can contain colons once they start with a
+// dot or a slash.
+func splitCpArg(arg string) (string, string) {
+	if filepath.IsAbs(arg) || strings.HasPrefix(arg, ".") {
+		return "", arg
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", arg
 	}
+	return parts[0], parts[1]
+}
 
-	copyData.Set("Resource", info[1])
-	copyData.Set("HostPath", cmd.Arg(1))
+func (cli *DockerCli) copyFromContainer(container, srcPath, dstPath string) error {
+	var copyData engine.Env
+	copyData.Set("Resource", srcPath)
+	copyData.Set("HostPath", dstPath)
 
-	stream, statusCode, err := cli.call("POST", "/containers/"+info[0]+"/copy", copyData, false)
+	stream, statusCode, err := cli.call("POST", "/containers/"+container+"/copy", copyData, false)
 	if stream != nil {
 		defer stream.Close()
 	}
 	if statusCode == 404 {
-		return fmt.Errorf("No such container: %v", info[0])
+		return fmt.Errorf("No such container: %v", container)
 	}
 	if err != nil {
 		return err
 	}
 
 	if statusCode == 200 {
-		if err := archive.Untar(stream, copyData.Get("HostPath"), &archive.TarOptions{NoLchown: true}); err != nil {
+		if dstPath == "-" {
+			_, err := io.Copy(cli.out, stream)
+			return err
+		}
+		if err := archive.Untar(stream, dstPath, &archive.TarOptions{NoLchown: true}); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// copyToContainer extracts srcPath into dstPath when it is a directory of the
+// container, or creates or replaces the file or directory dstPath otherwise.
+func (cli *DockerCli) copyToContainer(srcPath, container, dstPath string) error {
+	var (
+		content io.Reader
+		dstDir  = dstPath
+	)
+	if srcPath == "-" {
+		content = cli.in
+	} else {
+		srcPath, err := filepath.Abs(srcPath)
+		if err != nil {
+			return err
+		}
+		srcStat, err := os.Stat(srcPath)
+		if err != nil {
+			return err
+		}
+
+		options := &archive.TarOptions{
+			Compression: archive.Uncompressed,
+			Includes:    []string{filepath.Base(srcPath)},
+		}
+		dstStat, statusCode, err := cli.statContainerPath(container, dstPath)
+		switch {
+		case err == nil && os.FileMode(dstStat.GetInt64("Mode")).IsDir():
+		case err == nil && srcStat.IsDir():
+			return fmt.Errorf("Error: cannot copy the directory %s to the file %s", srcPath, dstPath)
+		case err == nil || statusCode == 404:
+			dstDir = path.Dir(dstPath)
+			options.Name = path.Base(dstPath)
+		default:
+			return err
+		}
+
+		tar, err := archive.TarWithOptions(filepath.Dir(srcPath), options)
+		if err != nil {
+			return err
+		}
+		defer tar.Close()
+		content = tar
+	}
+
+	v := url.Values{}
+	v.Set("path", dstDir)
+	return cli.stream("PUT", "/containers/"+container+"/archive?"+v.Encode(), content, nil, nil)
+}
+
 func (cli *DockerCli) CmdSave(args ...string) error {
 	cmd := cli.Subcmd("save", "IMAGE [IMAGE...]", "Save an image(s) to a tar archive (streamed to STDOUT by default)")
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
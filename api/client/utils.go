@@ -113,6 +113,41 @@ func (cli *DockerCli) call(method, path string, data interface{}, passAuthInfo b
 	return resp.Body, resp.StatusCode, nil
 }
 
+// statContainerPath returns the stat of a path of a container, sent in a
+// header of the response to a HEAD request.
+func (cli *DockerCli) statContainerPath(container, containerPath string) (*engine.Env, int, error) {
+	v := url.Values{}
+	v.Set("path", containerPath)
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("/v%s/containers/%s/archive?%s", api.APIVERSION, container, v.Encode()), nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	req.Header.Set("User-Agent", "Docker-Client/"+dockerversion.VERSION)
+	req.URL.Host = cli.addr
+	req.URL.Scheme = cli.scheme
+	resp, err := cli.HTTPClient().Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "connection refused") {
+			return nil, -1, ErrConnectionRefused
+		}
+		return nil, -1, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, resp.StatusCode, fmt.Errorf("Error: can't stat %s in container %s: %s", containerPath, container, http.StatusText(resp.StatusCode))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(resp.Header.Get("X-Docker-Container-Path-Stat"))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	stat := &engine.Env{}
+	if err := stat.Decode(bytes.NewReader(data)); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return stat, resp.StatusCode, nil
+}
+
 func (cli *DockerCli) stream(method, path string, in io.Reader, out io.Writer, headers map[string][]string) error {
 	return cli.streamHelper(method, path, true, in, out, nil, headers)
 }
@@ -158,7 +193,8 @@ func (cli *DockerCli) streamHelper(method, path string, setRawTerminal bool, in
 		return fmt.Errorf("Error: %s", bytes.TrimSpace(body))
 	}
 
-	if api.MatchesContentType(resp.Header.Get("Content-Type"), "application/json") {
+	// the responses without a body have no content type
+	if ct := resp.Header.Get("Content-Type"); ct != "" && api.MatchesContentType(ct, "application/json") {
 		return utils.DisplayJSONMessagesStream(resp.Body, stdout, cli.outFd, cli.isTerminalOut)
 	}
 	if stdout != nil || stderr != nil {
@@ -12,11 +12,14 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/nat"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -48,6 +51,69 @@ func env(b *Builder, args []string, attributes map[string]bool, original string)
 	return b.commit("", b.Config.Cmd, fmt.Sprintf("ENV %s", fullEnv))
 }
 
+// LABEL foo=bar [key=value...]
+//
+// Sets the label foo to bar in the image metadata, later LABEL statements
+// override labels of the same name set earlier or by the parent image.
+//
+func label(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("LABEL requires at least one argument")
+	}
+	if len(args)%2 != 0 {
+		// the parser always emits name/value pairs, but just in case
+		return fmt.Errorf("Bad input to LABEL, too many args")
+	}
+
+	commitStr := "LABEL"
+
+	if b.Config.Labels == nil {
+		b.Config.Labels = map[string]string{}
+	}
+
+	for j := 0; j < len(args); j += 2 {
+		// name  ==> args[j]
+		// value ==> args[j+1]
+		newVar := args[j] + "=" + args[j+1]
+		commitStr += " " + newVar
+
+		b.Config.Labels[args[j]] = args[j+1]
+	}
+	return b.commit("", b.Config.Cmd, commitStr)
+}
+
+// ARG name[=value]
+//
+// Declares the build-time variable name, which can then be given a value
+// with --build-arg and used by RUN and for variable substitution. The
+// optional value is used when the variable is not given.
+//
+func arg(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("ARG requires exactly one argument definition")
+	}
+
+	var (
+		name       = args[0]
+		value      string
+		hasDefault bool
+	)
+	if strings.Contains(name, "=") {
+		parts := strings.SplitN(name, "=", 2)
+		name, value, hasDefault = parts[0], parts[1], true
+	}
+	if name == "" {
+		return fmt.Errorf("ARG names can not be blank")
+	}
+
+	b.allowedBuildArgs[name] = true
+	if _, ok := b.BuildArgs[name]; !ok && hasDefault {
+		b.BuildArgs[name] = value
+	}
+
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("ARG %s", args[0]))
+}
+
 // MAINTAINER some text <maybe@an.email.address>
 //
 // Sets the maintainer metadata.
@@ -197,6 +263,21 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 
 	log.Debugf("[BUILDER] Command to be executed: %v", b.Config.Cmd)
 
+	// The build args are set in the environment of the command but are not
+	// kept in the image. They are prepended to the command recorded in the
+	// image instead, so that the cache is only hit with the same values.
+	var (
+		execCmd  = b.Config.Cmd
+		saveCmd  = b.Config.Cmd
+		env      = b.Config.Env
+		buildEnv = b.buildArgsEnv()
+	)
+	if len(buildEnv) > 0 {
+		saveCmd = append([]string{fmt.Sprintf("|%d", len(buildEnv))}, buildEnv...)
+		saveCmd = append(saveCmd, execCmd...)
+		b.Config.Cmd = saveCmd
+	}
+
 	hit, err := b.probeCache()
 	if err != nil {
 		return err
@@ -205,6 +286,12 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 		return nil
 	}
 
+	// the container shares the builder's config, which is restored once
+	// the command has run
+	b.Config.Cmd = execCmd
+	b.Config.Env = append(append([]string{}, env...), buildEnv...)
+	defer func() { b.Config.Env = env }()
+
 	c, err := b.create()
 	if err != nil {
 		return err
@@ -219,6 +306,7 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	if err != nil {
 		return err
 	}
+	b.Config.Cmd, b.Config.Env = saveCmd, env
 	if err := b.commit(c.ID, cmd, "run"); err != nil {
 		return err
 	}
@@ -285,6 +373,79 @@ func entrypoint(b *Builder, args []string, attributes map[string]bool, original
 	return nil
 }
 
+// HEALTHCHECK [--interval=30s] [--timeout=30s] [--retries=3] CMD command
+// HEALTHCHECK NONE
+//
+// Set the command run periodically inside the container to check that it is
+// healthy, or disable the check inherited from the base image with NONE. The
+// command is handled like the one of RUN.
+//
+func healthcheck(b *Builder, args []string, attributes map[string]bool, original string) error {
+	health := &runconfig.HealthConfig{}
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		parts := strings.SplitN(strings.TrimPrefix(args[0], "--"), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("HEALTHCHECK option %s requires a value", args[0])
+		}
+		var err error
+		switch parts[0] {
+		case "interval":
+			health.Interval, err = parseHealthDuration(parts[1])
+		case "timeout":
+			health.Timeout, err = parseHealthDuration(parts[1])
+		case "retries":
+			health.Retries, err = strconv.Atoi(parts[1])
+			if err == nil && health.Retries < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		default:
+			return fmt.Errorf("Unknown HEALTHCHECK option: %s", args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid HEALTHCHECK option %s: %s", args[0], err)
+		}
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("HEALTHCHECK requires a type of check, CMD or NONE")
+	}
+	switch typ := strings.ToUpper(args[0]); typ {
+	case "NONE":
+		if len(args) > 1 || health.Interval != 0 || health.Timeout != 0 || health.Retries != 0 {
+			return fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		health.Test = []string{typ}
+	case "CMD":
+		cmd := handleJsonArgs(args[1:], attributes)
+		if len(cmd) == 0 {
+			return fmt.Errorf("HEALTHCHECK CMD requires a command")
+		}
+		if attributes["json"] {
+			health.Test = append([]string{"CMD"}, cmd...)
+		} else {
+			health.Test = []string{"CMD-SHELL", cmd[0]}
+		}
+	default:
+		return fmt.Errorf("Unknown type of HEALTHCHECK: %s", args[0])
+	}
+
+	b.Config.Healthcheck = health
+
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("HEALTHCHECK %v interval=%s timeout=%s retries=%d", health.Test, health.Interval, health.Timeout, health.Retries))
+}
+
+func parseHealthDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
 // EXPOSE 6667/tcp 7000/tcp
 //
 // Expose ports for links and port mappings. This all ends up in
@@ -347,6 +508,24 @@ func volume(b *Builder, args []string, attributes map[string]bool, original stri
 	return nil
 }
 
+// STOPSIGNAL signal
+//
+// Set the signal sent to stop the containers of the image, given as a number
+// or a name like SIGQUIT.
+//
+func stopSignal(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("STOPSIGNAL requires exactly one argument")
+	}
+
+	if _, err := signal.ParseSignal(args[0]); err != nil {
+		return err
+	}
+
+	b.Config.StopSignal = args[0]
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("STOPSIGNAL %v", args))
+}
+
 // INSERT is no longer accepted, but we still parse it.
 func insert(b *Builder, args []string, attributes map[string]bool, original string) error {
 	return fmt.Errorf("INSERT has been deprecated. Please use ADD instead")
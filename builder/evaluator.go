@@ -25,6 +25,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
@@ -43,33 +44,39 @@ var (
 
 // Environment variable interpolation will happen on these statements only.
 var replaceEnvAllowed = map[string]struct{}{
-	"env":     {},
-	"add":     {},
-	"copy":    {},
-	"workdir": {},
-	"expose":  {},
-	"volume":  {},
-	"user":    {},
+	"env":        {},
+	"label":      {},
+	"add":        {},
+	"copy":       {},
+	"workdir":    {},
+	"expose":     {},
+	"volume":     {},
+	"user":       {},
+	"stopsignal": {},
 }
 
 var evaluateTable map[string]func(*Builder, []string, map[string]bool, string) error
 
 func init() {
 	evaluateTable = map[string]func(*Builder, []string, map[string]bool, string) error{
-		"env":        env,
-		"maintainer": maintainer,
-		"add":        add,
-		"copy":       dispatchCopy, // copy() is a go builtin
-		"from":       from,
-		"onbuild":    onbuild,
-		"workdir":    workdir,
-		"run":        run,
-		"cmd":        cmd,
-		"entrypoint": entrypoint,
-		"expose":     expose,
-		"volume":     volume,
-		"user":       user,
-		"insert":     insert,
+		"env":         env,
+		"arg":         arg,
+		"label":       label,
+		"maintainer":  maintainer,
+		"add":         add,
+		"copy":        dispatchCopy, // copy() is a go builtin
+		"from":        from,
+		"onbuild":     onbuild,
+		"workdir":     workdir,
+		"run":         run,
+		"cmd":         cmd,
+		"entrypoint":  entrypoint,
+		"healthcheck": healthcheck,
+		"expose":      expose,
+		"volume":      volume,
+		"user":        user,
+		"stopsignal":  stopSignal,
+		"insert":      insert,
 	}
 }
 
@@ -100,6 +107,10 @@ type Builder struct {
 
 	Config *runconfig.Config // runconfig for cmd, run, entrypoint etc.
 
+	// build-time variables given by the user, only the ones declared with
+	// ARG in the Dockerfile are used.
+	BuildArgs map[string]string
+
 	// both of these are controlled by the Remove and ForceRemove options in BuildOpts
 	TmpContainers map[string]struct{} // a map of containers used for removes
 
@@ -110,6 +121,9 @@ type Builder struct {
 	context     tarsum.TarSum // the context is a tarball that is uploaded by the client
 	contextPath string        // the path of the temporary directory the local context is unpacked to (server side)
 
+	allowedBuildArgs map[string]bool // build args declared with ARG so far
+	disableCommit    bool            // set when only the config is built, see BuildFromConfig
+
 }
 
 // Run the builder with the context. This is the lynchpin of this package. This
@@ -162,6 +176,10 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 	// some initializations that would not have been supplied by the caller.
 	b.Config = &runconfig.Config{}
 	b.TmpContainers = map[string]struct{}{}
+	b.allowedBuildArgs = map[string]bool{}
+	if b.BuildArgs == nil {
+		b.BuildArgs = map[string]string{}
+	}
 
 	for i, n := range b.dockerfile.Children {
 		if err := b.dispatch(i, n); err != nil {
@@ -176,6 +194,18 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 		}
 	}
 
+	// check that all the build args given by the user were declared
+	var unusedBuildArgs []string
+	for arg := range b.BuildArgs {
+		if !b.allowedBuildArgs[arg] {
+			unusedBuildArgs = append(unusedBuildArgs, arg)
+		}
+	}
+	if len(unusedBuildArgs) > 0 {
+		sort.Strings(unusedBuildArgs)
+		return "", fmt.Errorf("One or more build-args %v were not consumed, failing build.", unusedBuildArgs)
+	}
+
 	if b.image == "" {
 		return "", fmt.Errorf("No image was generated. Is your Dockerfile empty?\n")
 	}
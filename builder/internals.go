@@ -23,6 +23,7 @@ import (
 	"github.com/docker/docker/daemon"
 	imagepkg "github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
@@ -54,6 +55,9 @@ func (b *Builder) readContext(context io.Reader) error {
 }
 
 func (b *Builder) commit(id string, autoCmd []string, comment string) error {
+	if b.disableCommit {
+		return nil
+	}
 	if b.image == "" {
 		return fmt.Errorf("Please provide a source image with `from` prior to commit")
 	}
@@ -597,8 +601,11 @@ func (b *Builder) addContext(container *daemon.Container, orig, dest string, dec
 		return err
 	}
 
+	// the files are owned by the root of the containers
+	rootUID, rootGID := b.Daemon.GetRemappedUIDGID()
+
 	if fi.IsDir() {
-		return copyAsDirectory(origPath, destPath, destExists)
+		return copyAsDirectory(origPath, destPath, destExists, rootUID, rootGID)
 	}
 
 	// If we are adding a remote file (or we've been told not to decompress), do not try to untar it
@@ -613,14 +620,14 @@ func (b *Builder) addContext(container *daemon.Container, orig, dest string, dec
 		}
 
 		// try to successfully untar the orig
-		if err := archive.UntarPath(origPath, tarDest); err == nil {
+		if err := b.untarPath(origPath, tarDest); err == nil {
 			return nil
 		} else if err != io.EOF {
 			log.Debugf("Couldn't untar %s to %s: %s", origPath, tarDest, err)
 		}
 	}
 
-	if err := os.MkdirAll(path.Dir(destPath), 0755); err != nil {
+	if err := idtools.MkdirAllAs(path.Dir(destPath), 0755, rootUID, rootGID); err != nil {
 		return err
 	}
 	if err := archive.CopyWithTar(origPath, destPath); err != nil {
@@ -632,10 +639,41 @@ func (b *Builder) addContext(container *daemon.Container, orig, dest string, dec
 		resPath = path.Join(destPath, path.Base(origPath))
 	}
 
-	return fixPermissions(resPath, 0, 0)
+	return fixPermissions(resPath, rootUID, rootGID)
+}
+
+// untarPath extracts the archive src to dst, with the owners of its files
+// mapped to the users of the host when user namespaces are enabled.
+func (b *Builder) untarPath(src, dst string) error {
+	uidMaps, gidMaps := b.Daemon.GetIDMappings()
+	if uidMaps == nil {
+		return archive.UntarPath(src, dst)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	remapped, err := archive.ChownArchive(f, func(uid, gid int) (int, int, error) {
+		hostUID, err := idtools.ToHost(uid, uidMaps)
+		if err != nil {
+			return -1, -1, err
+		}
+		hostGID, err := idtools.ToHost(gid, gidMaps)
+		if err != nil {
+			return -1, -1, err
+		}
+		return hostUID, hostGID, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer remapped.Close()
+	return archive.Untar(remapped, dst, nil)
 }
 
-func copyAsDirectory(source, destination string, destinationExists bool) error {
+func copyAsDirectory(source, destination string, destinationExists bool, uid, gid int) error {
 	if err := archive.CopyWithTar(source, destination); err != nil {
 		return err
 	}
@@ -647,14 +685,14 @@ func copyAsDirectory(source, destination string, destinationExists bool) error {
 		}
 
 		for _, file := range files {
-			if err := fixPermissions(filepath.Join(destination, file.Name()), 0, 0); err != nil {
+			if err := fixPermissions(filepath.Join(destination, file.Name()), uid, gid); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
 
-	return fixPermissions(destination, 0, 0)
+	return fixPermissions(destination, uid, gid)
 }
 
 func fixPermissions(destination string, uid, gid int) error {
@@ -1,20 +1,42 @@
 package builder
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 
+	"github.com/docker/docker/builder/parser"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
+// The instructions which only change the config of the image, applied by
+// the --change option of commit and import.
+var validCommitCommands = map[string]bool{
+	"cmd":         true,
+	"entrypoint":  true,
+	"env":         true,
+	"expose":      true,
+	"healthcheck": true,
+	"label":       true,
+	"onbuild":     true,
+	"stopsignal":  true,
+	"user":        true,
+	"volume":      true,
+	"workdir":     true,
+}
+
 type BuilderJob struct {
 	Engine *engine.Engine
 	Daemon *daemon.Daemon
@@ -22,6 +44,7 @@ type BuilderJob struct {
 
 func (b *BuilderJob) Install() {
 	b.Engine.Register("build", b.CmdBuild)
+	b.Engine.Register("build_config", b.CmdBuildConfig)
 }
 
 func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
@@ -37,11 +60,17 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 		forceRm        = job.GetenvBool("forcerm")
 		authConfig     = &registry.AuthConfig{}
 		configFile     = &registry.ConfigFile{}
+		buildArgs      = map[string]string{}
 		tag            string
 		context        io.ReadCloser
 	)
 	job.GetenvJson("authConfig", authConfig)
 	job.GetenvJson("configFile", configFile)
+	if job.Getenv("buildargs") != "" {
+		if err := job.GetenvJson("buildargs", &buildArgs); err != nil {
+			return job.Errorf("Invalid build args: %s", err)
+		}
+	}
 
 	repoName, tag = parsers.ParseRepositoryTag(repoName)
 	if repoName != "" {
@@ -115,6 +144,7 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 		StreamFormatter: sf,
 		AuthConfig:      authConfig,
 		AuthConfigFile:  configFile,
+		BuildArgs:       buildArgs,
 	}
 
 	id, err := builder.Run(context)
@@ -127,3 +157,63 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// CmdBuildConfig applies the Dockerfile instructions of the "changes" of the
+// job to its "config", and writes the resulting config to its stdout.
+func (b *BuilderJob) CmdBuildConfig(job *engine.Job) engine.Status {
+	if len(job.Args) != 0 {
+		return job.Errorf("Usage: %s\n", job.Name)
+	}
+
+	config := &runconfig.Config{}
+	if err := job.GetenvJson("config", config); err != nil {
+		return job.Error(err)
+	}
+
+	newConfig, err := BuildFromConfig(b.Daemon, b.Engine, config, job.GetenvList("changes"))
+	if err != nil {
+		return job.Error(err)
+	}
+
+	if err := json.NewEncoder(job.Stdout).Encode(newConfig); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// BuildFromConfig applies the Dockerfile instructions of changes, one per
+// change, to the config. Only the instructions changing the config of the
+// image are accepted.
+func BuildFromConfig(d *daemon.Daemon, e *engine.Engine, c *runconfig.Config, changes []string) (*runconfig.Config, error) {
+	var nodes []*parser.Node
+	for _, change := range changes {
+		ast, err := parser.Parse(bytes.NewBufferString(change))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, ast.Children...)
+	}
+
+	for _, n := range nodes {
+		if !validCommitCommands[n.Value] {
+			return nil, fmt.Errorf("%s is not a valid change command", strings.ToUpper(n.Value))
+		}
+	}
+
+	builder := &Builder{
+		Daemon:        d,
+		Engine:        e,
+		Config:        c,
+		OutStream:     ioutil.Discard,
+		ErrStream:     ioutil.Discard,
+		disableCommit: true,
+	}
+
+	for i, n := range nodes {
+		if err := builder.dispatch(i, n); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.Config, nil
+}
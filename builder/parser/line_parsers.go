@@ -56,6 +56,116 @@ func parseEnv(rest string) (*Node, map[string]bool, error) {
 	return rootnode, nil, nil
 }
 
+// parseLabel parses LABEL statements. Labels are given as a list of
+// key=value pairs, where keys and values may be quoted with single or double
+// quotes and may contain backslash escaped characters:
+//
+// LABEL foo=bar "description"="a long value" version=1.0
+//
+// For consistency with ENV, a single pair in the form "LABEL key value" is
+// accepted as well. The resulting node list alternates keys and values.
+func parseLabel(rest string) (*Node, map[string]bool, error) {
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return nil, nil, fmt.Errorf("LABEL requires at least one argument")
+	}
+
+	words, err := splitLabelWords(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if words[0].eq < 0 {
+		strs := TOKEN_WHITESPACE.Split(rest, 2)
+		if len(strs) < 2 {
+			return nil, nil, fmt.Errorf("LABEL must have two arguments")
+		}
+		return &Node{Value: strs[0], Next: &Node{Value: strs[1]}}, nil, nil
+	}
+
+	rootnode := &Node{}
+	node := rootnode
+	for i, word := range words {
+		if word.eq < 0 {
+			return nil, nil, fmt.Errorf("LABEL names can not be blank and must be followed by '=': %s", word.raw)
+		}
+		key := word.value[:word.eq]
+		if key == "" {
+			return nil, nil, fmt.Errorf("LABEL names can not be blank")
+		}
+		node.Value = key
+		node.Next = &Node{Value: word.value[word.eq+1:]}
+		if i < len(words)-1 {
+			node.Next.Next = &Node{}
+			node = node.Next.Next
+		}
+	}
+
+	return rootnode, nil, nil
+}
+
+// labelWord is a single whitespace delimited word of a LABEL statement with
+// its quotes and escapes removed. eq is the position of the first unquoted
+// '=' in value, or -1 if there is none.
+type labelWord struct {
+	raw   string
+	value string
+	eq    int
+}
+
+func splitLabelWords(rest string) ([]labelWord, error) {
+	var (
+		words []labelWord
+		word  = labelWord{eq: -1}
+		value []rune
+		quote rune
+		start = -1
+		runes = []rune(rest)
+	)
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if quote == 0 && (ch == ' ' || ch == '\t') {
+			if start >= 0 {
+				word.raw = string(runes[start:i])
+				word.value = string(value)
+				words = append(words, word)
+				word, value, start = labelWord{eq: -1}, nil, -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		switch {
+		case ch == '\\' && quote != '\'':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("LABEL ends with an unfinished escape: %s", rest)
+			}
+			value = append(value, runes[i])
+		case quote == 0 && (ch == '"' || ch == '\''):
+			quote = ch
+		case ch == quote:
+			quote = 0
+		case quote == 0 && ch == '=' && word.eq < 0:
+			word.eq = len(value)
+			value = append(value, ch)
+		default:
+			value = append(value, ch)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("LABEL has an unterminated quote: %s", rest)
+	}
+	if start >= 0 {
+		word.raw = string(runes[start:])
+		word.value = string(value)
+		words = append(words, word)
+	}
+	return words, nil
+}
+
 // parses a whitespace-delimited set of arguments. The result is effectively a
 // linked list of string arguments.
 func parseStringsWhitespaceDelimited(rest string) (*Node, map[string]bool, error) {
@@ -153,3 +263,54 @@ func parseMaybeJSONToList(rest string) (*Node, map[string]bool, error) {
 
 	return parseStringsWhitespaceDelimited(rest)
 }
+
+// parseHealthConfig parses the options of HEALTHCHECK, which come before the
+// type of check, and the command to run, which is parsed like the one of
+// RUN.
+//
+// HEALTHCHECK --interval=5s CMD curl -f http://localhost/ ->
+// (healthcheck "--interval=5s" "CMD" "curl -f http://localhost/")
+//
+func parseHealthConfig(rest string) (*Node, map[string]bool, error) {
+	var (
+		rootnode *Node
+		prevnode *Node
+		attrs    map[string]bool
+	)
+	appendNode := func(node *Node) {
+		if rootnode == nil {
+			rootnode = node
+		} else {
+			prevnode.Next = node
+		}
+		for prevnode = node; prevnode.Next != nil; prevnode = prevnode.Next {
+		}
+	}
+
+	rest = strings.TrimSpace(rest)
+	for strings.HasPrefix(rest, "--") {
+		parts := TOKEN_WHITESPACE.Split(rest, 2)
+		appendNode(&Node{Value: parts[0]})
+		rest = ""
+		if len(parts) == 2 {
+			rest = parts[1]
+		}
+	}
+
+	parts := TOKEN_WHITESPACE.Split(rest, 2)
+	if parts[0] == "" {
+		return nil, nil, fmt.Errorf("HEALTHCHECK requires a type of check, CMD or NONE")
+	}
+	appendNode(&Node{Value: parts[0]})
+
+	if len(parts) == 2 {
+		node, cmdAttrs, err := parseMaybeJSON(parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		appendNode(node)
+		attrs = cmdAttrs
+	}
+
+	return rootnode, attrs, nil
+}
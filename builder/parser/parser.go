@@ -44,20 +44,24 @@ func init() {
 	// functions. Errors are propogated up by Parse() and the resulting AST can
 	// be incorporated directly into the existing AST as a next.
 	dispatch = map[string]func(string) (*Node, map[string]bool, error){
-		"user":       parseString,
-		"onbuild":    parseSubCommand,
-		"workdir":    parseString,
-		"env":        parseEnv,
-		"maintainer": parseString,
-		"from":       parseString,
-		"add":        parseStringsWhitespaceDelimited,
-		"copy":       parseStringsWhitespaceDelimited,
-		"run":        parseMaybeJSON,
-		"cmd":        parseMaybeJSON,
-		"entrypoint": parseMaybeJSON,
-		"expose":     parseStringsWhitespaceDelimited,
-		"volume":     parseMaybeJSONToList,
-		"insert":     parseIgnore,
+		"user":        parseString,
+		"onbuild":     parseSubCommand,
+		"workdir":     parseString,
+		"env":         parseEnv,
+		"label":       parseLabel,
+		"arg":         parseStringsWhitespaceDelimited,
+		"healthcheck": parseHealthConfig,
+		"maintainer":  parseString,
+		"from":        parseString,
+		"add":         parseStringsWhitespaceDelimited,
+		"copy":        parseStringsWhitespaceDelimited,
+		"run":         parseMaybeJSON,
+		"cmd":         parseMaybeJSON,
+		"entrypoint":  parseMaybeJSON,
+		"expose":      parseStringsWhitespaceDelimited,
+		"volume":      parseMaybeJSONToList,
+		"stopsignal":  parseString,
+		"insert":      parseIgnore,
 	}
 }
 
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -33,18 +34,45 @@ func (b *Builder) replaceEnv(str string) string {
 		match = match[strings.Index(match, "$"):]
 		matchKey := strings.Trim(match, "${}")
 
+		found := false
 		for _, keyval := range b.Config.Env {
 			tmp := strings.SplitN(keyval, "=", 2)
 			if tmp[0] == matchKey {
 				str = strings.Replace(str, match, tmp[1], -1)
+				found = true
 				break
 			}
 		}
+
+		// ENV takes precedence over the build args
+		if !found && b.allowedBuildArgs[matchKey] {
+			if value, ok := b.BuildArgs[matchKey]; ok {
+				str = strings.Replace(str, match, value, -1)
+			}
+		}
 	}
 
 	return str
 }
 
+// buildArgsEnv returns the declared build args as "key=value" pairs sorted
+// by key, leaving out the ones overridden by ENV.
+func (b *Builder) buildArgsEnv() []string {
+	envs := map[string]bool{}
+	for _, keyval := range b.Config.Env {
+		envs[strings.SplitN(keyval, "=", 2)[0]] = true
+	}
+
+	env := []string{}
+	for key, value := range b.BuildArgs {
+		if b.allowedBuildArgs[key] && !envs[key] {
+			env = append(env, key+"="+value)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
 func handleJsonArgs(args []string, attributes map[string]bool) []string {
 	if len(args) == 0 {
 		return []string{}
@@ -0,0 +1,254 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+)
+
+// ContainerStatPath returns the name, size, mode and modification time of a
+// path in the filesystem of a container.
+func (daemon *Daemon) ContainerStatPath(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	stat, err := container.StatPath(job.Args[1])
+	if err != nil {
+		return job.Error(err)
+	}
+	out := &engine.Env{}
+	out.Set("Name", filepath.Base(filepath.Join("/", job.Args[1])))
+	out.SetInt64("Size", stat.Size())
+	out.SetInt64("Mode", int64(stat.Mode()))
+	out.Set("Mtime", stat.ModTime().UTC().Format(time.RFC3339Nano))
+	if _, err := out.WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// ContainerExtractToDir extracts the tar archive read from the stdin of the
+// job into a directory of a container.
+func (daemon *Daemon) ContainerExtractToDir(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	if err := container.ExtractToDir(job.Args[1], job.Stdin); err != nil {
+		return job.Error(err)
+	}
+	container.LogEvent("extract-to-dir")
+	return engine.StatusOK
+}
+
+// StatPath returns the stat of a path in the filesystem of the container,
+// following symlinks.
+func (container *Container) StatPath(path string) (os.FileInfo, error) {
+	release, err := container.mountForArchive()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resolved, _, err := container.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(resolved)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("No such file or directory %s in container %s", path, container.ID)
+		}
+		return nil, err
+	}
+	return stat, nil
+}
+
+// ExtractToDir extracts a tar archive into a directory of the filesystem of
+// the container, or of the volume mounted on it.
+func (container *Container) ExtractToDir(path string, content io.Reader) error {
+	release, err := container.mountForArchive()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resolved, writable, err := container.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(resolved)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("No such directory %s in container %s", path, container.ID)
+		}
+		return err
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("Bad parameter: %s is not a directory in container %s", path, container.ID)
+	}
+	if !writable {
+		return fmt.Errorf("Impossible to extract to %s in container %s: read-only file system", path, container.ID)
+	}
+
+	remapped, err := container.daemon.remapArchiveToHost(content)
+	if err != nil {
+		return err
+	}
+	defer remapped.Close()
+
+	// the volumes are not mounted in the filesystem of the container, an
+	// entry crossing a mount point would be written under it
+	var mountPoints []string
+	for mountToPath := range container.VolumeMounts() {
+		mountPoints = append(mountPoints, mountToPath)
+	}
+	checked, err := rejectVolumeCrossing(remapped, filepath.Join("/", path), mountPoints)
+	if err != nil {
+		return err
+	}
+	defer checked.Close()
+	return chrootarchive.Untar(checked, resolved, nil)
+}
+
+// mountForArchive mounts the filesystem of the container, and its volumes
+// when it is stopped, and returns the function unmounting them. The volumes
+// are left mounted if the container was started in the meantime.
+func (container *Container) mountForArchive() (func(), error) {
+	container.Lock()
+	defer container.Unlock()
+
+	if err := container.Mount(); err != nil {
+		return nil, err
+	}
+	if !container.Running {
+		if err := container.mountVolumes(); err != nil {
+			container.unmountVolumes()
+			container.Unmount()
+			return nil, err
+		}
+	}
+	return func() {
+		container.Lock()
+		if !container.Running {
+			container.unmountVolumes()
+		}
+		container.Unlock()
+		container.Unmount()
+	}, nil
+}
+
+// resolvePath returns the path on the host of a path in the filesystem of
+// the container, in the volume mounted the deepest on it if any, and
+// whether it is writable. Symlinks are followed within the container or
+// the volume.
+func (container *Container) resolvePath(path string) (string, bool, error) {
+	path = filepath.Join("/", path)
+
+	mounts := container.VolumeMounts()
+	var mountPoints []string
+	for mountToPath := range mounts {
+		mountPoints = append(mountPoints, mountToPath)
+	}
+	if mnt := mounts[volumeMountPoint(path, mountPoints)]; mnt != nil {
+		rel, err := filepath.Rel(mnt.MountToPath, path)
+		if err != nil {
+			return "", false, err
+		}
+		resolved, err := symlink.FollowSymlinkInScope(filepath.Join(mnt.volume.Path, rel), mnt.volume.Path)
+		return resolved, mnt.Writable, err
+	}
+
+	resolved, err := container.getResourcePath(path)
+	return resolved, !container.hostConfig.ReadonlyRootfs, err
+}
+
+// volumeMountPoint returns the mount point, among mountPoints, of the volume
+// mounted the deepest on path, or "" when path is not in a volume.
+func volumeMountPoint(path string, mountPoints []string) string {
+	var found string
+	for _, m := range mountPoints {
+		if path != m && !strings.HasPrefix(path, m+"/") {
+			continue
+		}
+		if len(m) > len(found) {
+			found = m
+		}
+	}
+	return found
+}
+
+// rejectVolumeCrossing returns the archive to extract to path, uncompressed,
+// failing on the first entry which is not in the same volume as path, or in
+// the filesystem of the container when path is not in a volume.
+func rejectVolumeCrossing(arch archive.ArchiveReader, path string, mountPoints []string) (archive.Archive, error) {
+	decompressed, err := archive.DecompressStream(arch)
+	if err != nil {
+		return nil, err
+	}
+
+	mountPoint := volumeMountPoint(path, mountPoints)
+	check := func(name string) error {
+		// the entries are extracted in a chroot to path, they cannot
+		// leave it
+		entry := filepath.Join(path, filepath.Join("/", name))
+		if m := volumeMountPoint(entry, mountPoints); m != mountPoint {
+			return fmt.Errorf("Impossible to extract %s to %s: it crosses the volume mounted on %s", name, path, m)
+		}
+		return nil
+	}
+
+	pipeR, pipeW := io.Pipe()
+	go func() {
+		tr := tar.NewReader(decompressed)
+		tw := tar.NewWriter(pipeW)
+		err := func() error {
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					return tw.Close()
+				}
+				if err != nil {
+					return err
+				}
+				if err := check(hdr.Name); err != nil {
+					return err
+				}
+				if hdr.Typeflag == tar.TypeLink {
+					if err := check(hdr.Linkname); err != nil {
+						return err
+					}
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				if _, err := io.Copy(tw, tr); err != nil {
+					return err
+				}
+			}
+		}()
+		decompressed.Close()
+		pipeW.CloseWithError(err)
+	}()
+	return pipeR, nil
+}
@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+)
+
+func TestVolumeMountPoint(t *testing.T) {
+	mountPoints := []string{"/data", "/data/sub", "/var/lib"}
+	for path, expected := range map[string]string{
+		"/":                "",
+		"/dat":             "",
+		"/database":        "",
+		"/data":            "/data",
+		"/data/file":       "/data",
+		"/data/sub/file":   "/data/sub",
+		"/data/subway":     "/data",
+		"/var/lib/x/y":     "/var/lib",
+		"/var/library/x/y": "",
+	} {
+		if m := volumeMountPoint(path, mountPoints); m != expected {
+			t.Fatalf("Expected %s to be in the volume mounted on %q, got %q", path, expected, m)
+		}
+	}
+}
+
+func testArchive(t *testing.T, names ...string) io.Reader {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestRejectVolumeCrossing(t *testing.T) {
+	mountPoints := []string{"/data", "/data/sub"}
+	for _, c := range []struct {
+		path  string
+		names []string
+		valid bool
+	}{
+		{"/", []string{"etc/file", "database"}, true},
+		{"/", []string{"etc/file", "data/file"}, false},
+		{"/", []string{"./data"}, false},
+		{"/data", []string{"file", "dir/file"}, true},
+		{"/data", []string{"file", "sub/file"}, false},
+		{"/data", []string{"../etc/file"}, true},
+		{"/data/sub", []string{"file"}, true},
+	} {
+		checked, err := rejectVolumeCrossing(testArchive(t, c.names...), c.path, mountPoints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.Copy(ioutil.Discard, checked)
+		checked.Close()
+		if c.valid && err != nil {
+			t.Fatalf("Expected %v to be extracted to %s, got %v", c.names, c.path, err)
+		}
+		if !c.valid && err == nil {
+			t.Fatalf("Expected %v to be rejected when extracted to %s", c.names, c.path)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/links"
@@ -29,9 +31,11 @@ import (
 	"github.com/docker/docker/pkg/networkfs/etchosts"
 	"github.com/docker/docker/pkg/networkfs/resolvconf"
 	"github.com/docker/docker/pkg/promise"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
+	"github.com/docker/docker/volumes"
 )
 
 const DefaultPathEnv = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
@@ -80,6 +84,7 @@ type Container struct {
 	daemon                   *Daemon
 	MountLabel, ProcessLabel string
 	AppArmorProfile          string
+	SeccompProfile           string
 	RestartCount             int
 
 	// Maps container paths to volume paths.  The key in this is the path to which
@@ -90,10 +95,15 @@ type Container struct {
 	// Easier than migrating older container configs :)
 	VolumesRW  map[string]bool
 	hostConfig *runconfig.HostConfig
+	// volumes mounted by their drivers while the container runs
+	mountedVolumes map[string]*volumes.Volume
 
 	activeLinks  map[string]*links.Link
 	monitor      *containerMonitor
 	execCommands *execStore
+	// logDriver for closing
+	logDriver logger.Logger
+	logCopier *logger.Copier
 }
 
 func (container *Container) FromDisk() error {
@@ -208,17 +218,6 @@ func populateCommand(c *Container, env []string) error {
 	case "none":
 	case "host":
 		en.HostNetworking = true
-	case "bridge", "": // empty string to support existing containers
-		if !c.Config.NetworkDisabled {
-			network := c.NetworkSettings
-			en.Interface = &execdriver.NetworkInterface{
-				Gateway:     network.Gateway,
-				Bridge:      network.Bridge,
-				IPAddress:   network.IPAddress,
-				IPPrefixLen: network.IPPrefixLen,
-				MacAddress:  network.MacAddress,
-			}
-		}
 	case "container":
 		nc, err := c.getNetworkedContainer()
 		if err != nil {
@@ -226,7 +225,21 @@ func populateCommand(c *Container, env []string) error {
 		}
 		en.ContainerID = nc.ID
 	default:
-		return fmt.Errorf("invalid network mode: %s", c.hostConfig.NetworkMode)
+		// "bridge", a user-defined network or an empty string to support
+		// existing containers
+		if !c.Config.NetworkDisabled {
+			network := c.NetworkSettings
+			en.Interface = &execdriver.NetworkInterface{
+				Gateway:             network.Gateway,
+				Bridge:              network.Bridge,
+				IPAddress:           network.IPAddress,
+				IPPrefixLen:         network.IPPrefixLen,
+				MacAddress:          network.MacAddress,
+				GlobalIPv6Address:   network.GlobalIPv6Address,
+				GlobalIPv6PrefixLen: network.GlobalIPv6PrefixLen,
+				IPv6Gateway:         network.IPv6Gateway,
+			}
+		}
 	}
 
 	// Build lists of devices allowed and created within the container.
@@ -280,6 +293,11 @@ func populateCommand(c *Container, env []string) error {
 		MountLabel:         c.GetMountLabel(),
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
+		SeccompProfile:     c.SeccompProfile,
+		UidMapping:         c.daemon.uidMaps,
+		GidMapping:         c.daemon.gidMaps,
+		LiveRestore:        c.daemon.config.LiveRestore,
+		ReadonlyRootfs:     c.hostConfig.ReadonlyRootfs,
 	}
 
 	return nil
@@ -337,6 +355,47 @@ func (container *Container) Start() (err error) {
 	return container.waitForStart()
 }
 
+// Restore reattaches to the process of the container left running by a
+// previous daemon with live restore, and allocates again the resources of
+// the daemon it was using.
+func (container *Container) Restore() (err error) {
+	container.Lock()
+	defer container.Unlock()
+
+	defer func() {
+		if err != nil {
+			container.setError(err)
+			container.toDisk()
+			container.cleanup()
+		}
+	}()
+
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	if err := container.RestoreNetwork(); err != nil {
+		return err
+	}
+	if err := container.mountVolumes(); err != nil {
+		return err
+	}
+	linkedEnv, err := container.setupLinkedContainers()
+	if err != nil {
+		return err
+	}
+	env := container.createDaemonEnvironment(linkedEnv)
+	if err := populateCommand(container, env); err != nil {
+		return err
+	}
+	if err := container.setupMounts(); err != nil {
+		return err
+	}
+
+	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	container.monitor.restore = true
+	return container.waitForMonitor()
+}
+
 func (container *Container) Run() error {
 	if err := container.Start(); err != nil {
 		return err
@@ -452,11 +511,16 @@ func (container *Container) AllocateNetwork() error {
 
 	var (
 		env *engine.Env
-		err error
 		eng = container.daemon.eng
 	)
 
+	network, err := container.primaryNetworkID()
+	if err != nil {
+		return err
+	}
+
 	job := eng.Job("allocate_interface", container.ID)
+	job.Setenv("Network", network)
 	if env, err = job.Stdout.AddEnv(); err != nil {
 		return err
 	}
@@ -470,12 +534,12 @@ func (container *Container) AllocateNetwork() error {
 
 	if container.Config.PortSpecs != nil {
 		if err = migratePortMappings(container.Config, container.hostConfig); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			container.releaseInterface(network)
 			return err
 		}
 		container.Config.PortSpecs = nil
 		if err = container.WriteHostConfig(); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			container.releaseInterface(network)
 			return err
 		}
 	}
@@ -504,8 +568,8 @@ func (container *Container) AllocateNetwork() error {
 	container.NetworkSettings.PortMapping = nil
 
 	for port := range portSpecs {
-		if err = container.allocatePort(eng, port, bindings); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+		if err = container.allocatePort(eng, network, port, bindings); err != nil {
+			container.releaseInterface(network)
 			return err
 		}
 	}
@@ -517,6 +581,9 @@ func (container *Container) AllocateNetwork() error {
 	container.NetworkSettings.IPPrefixLen = env.GetInt("IPPrefixLen")
 	container.NetworkSettings.MacAddress = env.Get("MacAddress")
 	container.NetworkSettings.Gateway = env.Get("Gateway")
+	container.NetworkSettings.GlobalIPv6Address = env.Get("GlobalIPv6")
+	container.NetworkSettings.GlobalIPv6PrefixLen = env.GetInt("GlobalIPv6PrefixLen")
+	container.NetworkSettings.IPv6Gateway = env.Get("IPv6Gateway")
 
 	return nil
 }
@@ -525,10 +592,28 @@ func (container *Container) ReleaseNetwork() {
 	if container.Config.NetworkDisabled {
 		return
 	}
-	eng := container.daemon.eng
 
-	eng.Job("release_interface", container.ID).Run()
-	container.NetworkSettings = &NetworkSettings{}
+	container.unpublishNetworks()
+	network, _ := container.primaryNetworkID()
+	container.releaseInterface(network)
+
+	// the networks the container was connected to are kept for its next
+	// start
+	networks := container.NetworkSettings.Networks
+	for _, ep := range networks {
+		if n := container.daemon.networks.Get(ep.NetworkID); n != nil && ep.IPAddress != "" {
+			container.releaseEndpoint(n, ep)
+		}
+	}
+	container.NetworkSettings = &NetworkSettings{Networks: networks}
+}
+
+// releaseInterface releases the interface of the container on the network
+// of its network mode.
+func (container *Container) releaseInterface(network string) {
+	job := container.daemon.eng.Job("release_interface", container.ID)
+	job.Setenv("Network", network)
+	job.Run()
 }
 
 func (container *Container) isNetworkAllocated() bool {
@@ -545,21 +630,46 @@ func (container *Container) RestoreNetwork() error {
 	}
 
 	eng := container.daemon.eng
+	network, err := container.primaryNetworkID()
+	if err != nil {
+		return err
+	}
 
 	// Re-allocate the interface with the same IP and MAC address.
 	job := eng.Job("allocate_interface", container.ID)
+	job.Setenv("Network", network)
 	job.Setenv("RequestedIP", container.NetworkSettings.IPAddress)
 	job.Setenv("RequestedMac", container.NetworkSettings.MacAddress)
+	job.Setenv("RequestedIPv6", container.NetworkSettings.GlobalIPv6Address)
 	if err := job.Run(); err != nil {
 		return err
 	}
 
 	// Re-allocate any previously allocated ports.
 	for port := range container.NetworkSettings.Ports {
-		if err := container.allocatePort(eng, port, container.NetworkSettings.Ports); err != nil {
+		if err := container.allocatePort(eng, network, port, container.NetworkSettings.Ports); err != nil {
 			return err
 		}
 	}
+
+	// Re-allocate the interfaces on the networks the container was connected to.
+	for name, ep := range container.NetworkSettings.Networks {
+		n := container.daemon.networks.Get(ep.NetworkID)
+		if n == nil || ep.IPAddress == "" {
+			continue
+		}
+		id, err := n.driverID()
+		if err != nil {
+			return err
+		}
+		job := eng.Job("allocate_interface", container.ID)
+		job.Setenv("Network", id)
+		job.Setenv("RequestedIP", ep.IPAddress)
+		job.Setenv("RequestedMac", ep.MacAddress)
+		if err := job.Run(); err != nil {
+			return fmt.Errorf("Failed to restore the interface on network %s: %s", name, err)
+		}
+	}
 	return nil
 }
 
@@ -575,6 +685,8 @@ func (container *Container) cleanup() {
 		}
 	}
 
+	container.unmountVolumes()
+
 	if err := container.Unmount(); err != nil {
 		log.Errorf("%v: Failed to umount filesystem: %v", container.ID, err)
 	}
@@ -658,9 +770,10 @@ func (container *Container) Stop(seconds int) error {
 		return nil
 	}
 
-	// 1. Send a SIGTERM
-	if err := container.KillSig(15); err != nil {
-		log.Infof("Failed to send SIGTERM to the process, force killing")
+	// 1. Send the stop signal, SIGTERM unless the container sets another one
+	stopSignal := container.StopSignal()
+	if err := container.KillSig(stopSignal); err != nil {
+		log.Infof("Failed to send signal %d to the process, force killing", stopSignal)
 		if err := container.KillSig(9); err != nil {
 			return err
 		}
@@ -668,7 +781,7 @@ func (container *Container) Stop(seconds int) error {
 
 	// 2. Wait for the process to exit on its own
 	if _, err := container.WaitStop(time.Duration(seconds) * time.Second); err != nil {
-		log.Infof("Container %v failed to exit within %d seconds of SIGTERM - using the force", container.ID, seconds)
+		log.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, stopSignal)
 		// 3. If it doesn't, then send SIGKILL
 		if err := container.Kill(); err != nil {
 			container.WaitStop(-1 * time.Second)
@@ -678,6 +791,18 @@ func (container *Container) Stop(seconds int) error {
 	return nil
 }
 
+// StopSignal returns the signal sent to stop the container.
+func (container *Container) StopSignal() int {
+	var stopSignal syscall.Signal
+	if container.Config.StopSignal != "" {
+		stopSignal, _ = signal.ParseSignal(container.Config.StopSignal)
+	}
+	if stopSignal <= 0 {
+		stopSignal, _ = signal.ParseSignal(signal.DefaultStopSignal)
+	}
+	return int(stopSignal)
+}
+
 func (container *Container) Restart(seconds int) error {
 	// Avoid unnecessarily unmounting and then directly mounting
 	// the container when the container stops and then starts
@@ -729,6 +854,11 @@ func (container *Container) Export() (archive.Archive, error) {
 		container.Unmount()
 		return nil, err
 	}
+	archive, err = container.daemon.remapArchive(archive)
+	if err != nil {
+		container.Unmount()
+		return nil, err
+	}
 	return ioutils.NewReadCloserWrapper(archive, func() error {
 			err := archive.Close()
 			container.Unmount()
@@ -1035,7 +1165,13 @@ func (container *Container) initializeNetworking() error {
 	if err := container.AllocateNetwork(); err != nil {
 		return err
 	}
-	return container.buildHostnameAndHostsFiles(container.NetworkSettings.IPAddress)
+	if err := container.allocateEndpoints(); err != nil {
+		return err
+	}
+	if err := container.buildHostnameAndHostsFiles(container.NetworkSettings.IPAddress); err != nil {
+		return err
+	}
+	return container.publishNetworks()
 }
 
 // Make sure the config is compatible with the current kernel
@@ -1160,27 +1296,77 @@ func (container *Container) setupWorkingDirectory() error {
 	return nil
 }
 
-func (container *Container) startLoggingToDisk() error {
-	// Setup logging of stdout and stderr to disk
-	pth, err := container.logPath("json")
+func (container *Container) getLogConfig() runconfig.LogConfig {
+	cfg := container.hostConfig.LogConfig
+	if cfg.Type != "" || len(cfg.Config) > 0 { // container has log driver configured
+		if cfg.Type == "" {
+			cfg.Type = container.daemon.config.LogConfig.Type
+		}
+		return cfg
+	}
+	// Use daemon's default log config for containers
+	return container.daemon.config.LogConfig
+}
+
+func (container *Container) getLogger() (logger.Logger, error) {
+	cfg := container.getLogConfig()
+	c, err := logger.GetLogDriver(cfg.Type)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("Failed to get logging factory: %v", err)
+	}
+	ctx := logger.Context{
+		Config:        cfg.Config,
+		ContainerID:   container.ID,
+		ContainerName: container.Name,
 	}
 
-	if err := container.daemon.LogToDisk(container.stdout, pth, "stdout"); err != nil {
+	// Set logging file for "json-logger"
+	if cfg.Type == jsonfilelog.Name {
+		ctx.LogPath, err = container.logPath("json")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c(ctx)
+}
+
+func (container *Container) startLogging() error {
+	cfg := container.getLogConfig()
+	if cfg.Type == "none" {
+		return nil // do not start logging routines
+	}
+
+	l, err := container.getLogger()
+	if err != nil {
+		return fmt.Errorf("Failed to initialize logging driver: %v", err)
+	}
+
+	stdout, err := container.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := container.StderrPipe()
+	if err != nil {
 		return err
 	}
 
-	if err := container.daemon.LogToDisk(container.stderr, pth, "stderr"); err != nil {
+	copier, err := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": stdout, "stderr": stderr}, l)
+	if err != nil {
 		return err
 	}
+	container.logCopier = copier
+	copier.Run()
+	container.logDriver = l
 
 	return nil
 }
 
 func (container *Container) waitForStart() error {
 	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	return container.waitForMonitor()
+}
 
+func (container *Container) waitForMonitor() error {
 	// block until we either receive an error from the initial start of the container's
 	// process or until the process is running in the container
 	select {
@@ -1192,7 +1378,7 @@ func (container *Container) waitForStart() error {
 	return nil
 }
 
-func (container *Container) allocatePort(eng *engine.Engine, port nat.Port, bindings nat.PortMap) error {
+func (container *Container) allocatePort(eng *engine.Engine, network string, port nat.Port, bindings nat.PortMap) error {
 	binding := bindings[port]
 	if container.hostConfig.PublishAllPorts && len(binding) == 0 {
 		binding = append(binding, nat.PortBinding{})
@@ -1202,6 +1388,7 @@ func (container *Container) allocatePort(eng *engine.Engine, port nat.Port, bind
 		b := binding[i]
 
 		job := eng.Job("allocate_port", container.ID)
+		job.Setenv("Network", network)
 		job.Setenv("HostIP", b.HostIp)
 		job.Setenv("HostPort", b.HostPort)
 		job.Setenv("Proto", port.Proto())
@@ -1255,3 +1442,7 @@ func (container *Container) getNetworkedContainer() (*Container, error) {
 		return nil, fmt.Errorf("network mode not set to container")
 	}
 }
+
+func (container *Container) Stats() (*execdriver.ResourceStats, error) {
+	return container.daemon.Stats(container)
+}
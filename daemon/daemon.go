@@ -15,11 +15,14 @@ import (
 	"github.com/docker/libcontainer/label"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api"
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/daemon/execdriver/execdrivers"
 	"github.com/docker/docker/daemon/execdriver/lxc"
+	"github.com/docker/docker/daemon/execdriver/native/seccomp"
 	"github.com/docker/docker/daemon/graphdriver"
 	_ "github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/daemon/logger"
 	_ "github.com/docker/docker/daemon/networkdriver/bridge"
 	"github.com/docker/docker/daemon/networkdriver/portallocator"
 	"github.com/docker/docker/dockerversion"
@@ -29,10 +32,12 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/broadcastwriter"
 	"github.com/docker/docker/pkg/graphdb"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/truncindex"
 	"github.com/docker/docker/runconfig"
@@ -98,36 +103,55 @@ type Daemon struct {
 	driver         graphdriver.Driver
 	execDriver     execdriver.Driver
 	trustStore     *trust.TrustStore
+	statsCollector *statsCollector
+	networks       *networkStore
+	uidMaps        []idtools.IDMap
+	gidMaps        []idtools.IDMap
 }
 
 // Install installs daemon capabilities to eng.
 func (daemon *Daemon) Install(eng *engine.Engine) error {
 	// FIXME: remove ImageDelete's dependency on Daemon, then move to graph/
 	for name, method := range map[string]engine.Handler{
-		"attach":            daemon.ContainerAttach,
-		"commit":            daemon.ContainerCommit,
-		"container_changes": daemon.ContainerChanges,
-		"container_copy":    daemon.ContainerCopy,
-		"container_inspect": daemon.ContainerInspect,
-		"containers":        daemon.Containers,
-		"create":            daemon.ContainerCreate,
-		"rm":                daemon.ContainerRm,
-		"export":            daemon.ContainerExport,
-		"info":              daemon.CmdInfo,
-		"kill":              daemon.ContainerKill,
-		"logs":              daemon.ContainerLogs,
-		"pause":             daemon.ContainerPause,
-		"resize":            daemon.ContainerResize,
-		"restart":           daemon.ContainerRestart,
-		"start":             daemon.ContainerStart,
-		"stop":              daemon.ContainerStop,
-		"top":               daemon.ContainerTop,
-		"unpause":           daemon.ContainerUnpause,
-		"wait":              daemon.ContainerWait,
-		"image_delete":      daemon.ImageDelete, // FIXME: see above
-		"execCreate":        daemon.ContainerExecCreate,
-		"execStart":         daemon.ContainerExecStart,
-		"execResize":        daemon.ContainerExecResize,
+		"attach":             daemon.ContainerAttach,
+		"commit":             daemon.ContainerCommit,
+		"container_changes":  daemon.ContainerChanges,
+		"container_copy":     daemon.ContainerCopy,
+		"container_extract":  daemon.ContainerExtractToDir,
+		"container_stat":     daemon.ContainerStatPath,
+		"container_inspect":  daemon.ContainerInspect,
+		"container_stats":    daemon.ContainerStats,
+		"containers":         daemon.Containers,
+		"create":             daemon.ContainerCreate,
+		"rm":                 daemon.ContainerRm,
+		"export":             daemon.ContainerExport,
+		"info":               daemon.CmdInfo,
+		"kill":               daemon.ContainerKill,
+		"logs":               daemon.ContainerLogs,
+		"pause":              daemon.ContainerPause,
+		"container_rename":   daemon.ContainerRename,
+		"resize":             daemon.ContainerResize,
+		"restart":            daemon.ContainerRestart,
+		"start":              daemon.ContainerStart,
+		"stop":               daemon.ContainerStop,
+		"top":                daemon.ContainerTop,
+		"unpause":            daemon.ContainerUnpause,
+		"container_update":   daemon.ContainerUpdate,
+		"wait":               daemon.ContainerWait,
+		"image_delete":       daemon.ImageDelete, // FIXME: see above
+		"execCreate":         daemon.ContainerExecCreate,
+		"execStart":          daemon.ContainerExecStart,
+		"execResize":         daemon.ContainerExecResize,
+		"network_create":     daemon.NetworkCreate,
+		"networks":           daemon.Networks,
+		"network_rm":         daemon.NetworkRm,
+		"network_connect":    daemon.NetworkConnect,
+		"network_disconnect": daemon.NetworkDisconnect,
+		"volume_create":      daemon.VolumeCreate,
+		"volumes":            daemon.Volumes,
+		"volume_inspect":     daemon.VolumeInspect,
+		"volume_rm":          daemon.VolumeRm,
+		"volume_prune":       daemon.VolumePrune,
 	} {
 		if err := eng.Register(name, method); err != nil {
 			return err
@@ -226,47 +250,61 @@ func (daemon *Daemon) register(container *Container, updateSuffixarray bool) err
 
 	// FIXME: if the container is supposed to be running but is not, auto restart it?
 	//        if so, then we need to restart monitor and init a new lock
-	// If the container is supposed to be running, make sure of it
-	if container.IsRunning() {
-		log.Debugf("killing old running container %s", container.ID)
+	// If the container is supposed to be running, make sure of it, unless
+	// it is restored once all the containers are registered
+	if container.IsRunning() && !daemon.canRestore(container) {
+		return daemon.stopStaleContainer(container)
+	}
+	return nil
+}
 
-		existingPid := container.Pid
-		container.SetStopped(0)
+// canRestore returns whether the daemon reattaches to the container, when
+// it was left running by a previous daemon.
+func (daemon *Daemon) canRestore(container *Container) bool {
+	return daemon.config.LiveRestore && strings.HasPrefix(container.ExecDriver, "native")
+}
 
-		// We only have to handle this for lxc because the other drivers will ensure that
-		// no processes are left when docker dies
-		if container.ExecDriver == "" || strings.Contains(container.ExecDriver, "lxc") {
-			lxc.KillLxc(container.ID, 9)
-		} else {
-			// use the current driver and ensure that the container is dead x.x
-			cmd := &execdriver.Command{
-				ID: container.ID,
-			}
-			var err error
-			cmd.ProcessConfig.Process, err = os.FindProcess(existingPid)
-			if err != nil {
-				log.Debugf("cannot find existing process for %d", existingPid)
-			}
-			daemon.execDriver.Terminate(cmd)
-		}
+// stopStaleContainer kills the process of a container left running by a
+// previous daemon and marks it as stopped.
+func (daemon *Daemon) stopStaleContainer(container *Container) error {
+	log.Debugf("killing old running container %s", container.ID)
 
-		if err := container.Unmount(); err != nil {
-			log.Debugf("unmount error %s", err)
+	existingPid := container.Pid
+	container.SetStopped(0)
+
+	// We only have to handle this for lxc because the other drivers will ensure that
+	// no processes are left when docker dies
+	if container.ExecDriver == "" || strings.Contains(container.ExecDriver, "lxc") {
+		lxc.KillLxc(container.ID, 9)
+	} else {
+		// use the current driver and ensure that the container is dead x.x
+		cmd := &execdriver.Command{
+			ID: container.ID,
 		}
-		if err := container.ToDisk(); err != nil {
-			log.Debugf("saving stopped state to disk %s", err)
+		var err error
+		cmd.ProcessConfig.Process, err = os.FindProcess(existingPid)
+		if err != nil {
+			log.Debugf("cannot find existing process for %d", existingPid)
 		}
+		daemon.execDriver.Terminate(cmd)
+	}
+
+	if err := container.Unmount(); err != nil {
+		log.Debugf("unmount error %s", err)
+	}
+	if err := container.ToDisk(); err != nil {
+		log.Debugf("saving stopped state to disk %s", err)
+	}
 
-		info := daemon.execDriver.Info(container.ID)
-		if !info.IsRunning() {
-			log.Debugf("Container %s was supposed to be running but is not.", container.ID)
+	info := daemon.execDriver.Info(container.ID)
+	if !info.IsRunning() {
+		log.Debugf("Container %s was supposed to be running but is not.", container.ID)
 
-			log.Debugf("Marking as stopped")
+		log.Debugf("Marking as stopped")
 
-			container.SetStopped(-127)
-			if err := container.ToDisk(); err != nil {
-				return err
-			}
+		container.SetStopped(-127)
+		if err := container.ToDisk(); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -287,15 +325,6 @@ func (daemon *Daemon) ensureName(container *Container) error {
 	return nil
 }
 
-func (daemon *Daemon) LogToDisk(src *broadcastwriter.BroadcastWriter, dst, stream string) error {
-	log, err := os.OpenFile(dst, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
-	if err != nil {
-		return err
-	}
-	src.AddWriter(log, stream)
-	return nil
-}
-
 func (daemon *Daemon) restore() error {
 	var (
 		debug         = (os.Getenv("DEBUG") != "" || os.Getenv("TEST") != "")
@@ -370,6 +399,20 @@ func (daemon *Daemon) restore() error {
 		registeredContainers = append(registeredContainers, container)
 	}
 
+	// reattach to the containers left running by the previous daemon
+	for _, container := range registeredContainers {
+		if !container.IsRunning() || !daemon.canRestore(container) {
+			continue
+		}
+		log.Debugf("Restoring container %s", container.ID)
+		if err := container.Restore(); err != nil {
+			log.Errorf("Failed to restore container %s: %s", container.ID, err)
+			if err := daemon.stopStaleContainer(container); err != nil {
+				log.Errorf("Failed to stop container %s: %s", container.ID, err)
+			}
+		}
+	}
+
 	// check the restart policy on the containers and restart any container with
 	// the restart policy of "always"
 	if daemon.config.AutoRestart {
@@ -425,6 +468,11 @@ func (daemon *Daemon) mergeAndVerifyConfig(config *runconfig.Config, img *image.
 	if len(config.Entrypoint) == 0 && len(config.Cmd) == 0 {
 		return nil, fmt.Errorf("No command specified")
 	}
+	if config.StopSignal != "" {
+		if _, err := signal.ParseSignal(config.StopSignal); err != nil {
+			return nil, err
+		}
+	}
 	return warnings, nil
 }
 
@@ -537,6 +585,9 @@ func parseSecurityOpt(container *Container, config *runconfig.Config) error {
 
 	for _, opt := range config.SecurityOpt {
 		con := strings.SplitN(opt, ":", 2)
+		if strings.HasPrefix(opt, "seccomp=") {
+			con = strings.SplitN(opt, "=", 2)
+		}
 		if len(con) == 1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -545,6 +596,13 @@ func parseSecurityOpt(container *Container, config *runconfig.Config) error {
 			label_opts = append(label_opts, con[1])
 		case "apparmor":
 			container.AppArmorProfile = con[1]
+		case "seccomp":
+			if con[1] != "unconfined" {
+				if _, err := seccomp.LoadProfile(con[1]); err != nil {
+					return err
+				}
+			}
+			container.SeccompProfile = con[1]
 		default:
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -589,11 +647,16 @@ func (daemon *Daemon) newContainer(name string, config *runconfig.Config, img *i
 }
 
 func (daemon *Daemon) createRootfs(container *Container, img *image.Image) error {
+	rootUID, rootGID := daemon.GetRemappedUIDGID()
 	// Step 1: create the container directory.
 	// This doubles as a barrier to avoid race conditions.
 	if err := os.Mkdir(container.root, 0700); err != nil {
 		return err
 	}
+	// the files of the directory are mounted in the container
+	if err := os.Chown(container.root, rootUID, rootGID); err != nil {
+		return err
+	}
 	initID := fmt.Sprintf("%s-init", container.ID)
 	if err := daemon.driver.Create(initID, img.ID); err != nil {
 		return err
@@ -604,7 +667,7 @@ func (daemon *Daemon) createRootfs(container *Container, img *image.Image) error
 	}
 	defer daemon.driver.Put(initID)
 
-	if err := graph.SetupInitLayer(initPath); err != nil {
+	if err := graph.SetupInitLayer(initPath, rootUID, rootGID); err != nil {
 		return err
 	}
 
@@ -614,6 +677,19 @@ func (daemon *Daemon) createRootfs(container *Container, img *image.Image) error
 	return nil
 }
 
+// GetRemappedUIDGID returns the ids of the host the root of the containers
+// is mapped to, 0 when it is not remapped.
+func (daemon *Daemon) GetRemappedUIDGID() (int, int) {
+	uid, gid, _ := idtools.GetRootUIDGID(daemon.uidMaps, daemon.gidMaps)
+	return uid, gid
+}
+
+// GetIDMappings returns the mappings of the users and groups of the
+// containers to the ones of the host, nil when they are not remapped.
+func (daemon *Daemon) GetIDMappings() ([]idtools.IDMap, []idtools.IDMap) {
+	return daemon.uidMaps, daemon.gidMaps
+}
+
 func GetFullContainerName(name string) (string, error) {
 	if name == "" {
 		return "", fmt.Errorf("Container name cannot be empty")
@@ -735,6 +811,14 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		config.EnableIpMasq = false
 	}
 	config.DisableNetwork = config.BridgeIface == disableNetworkBridge
+	if config.LiveRestore && config.ExecDriver != "native" {
+		return nil, fmt.Errorf("Live restore is only supported by the native execution driver")
+	}
+
+	// Make sure the default logging driver exists and accepts its options
+	if err := logger.ValidateLogOpts(config.LogConfig.Type, config.LogConfig.Config); err != nil {
+		return nil, fmt.Errorf("Invalid logging configuration: %s", err)
+	}
 
 	// Claim the pidfile first, to avoid any and all unexpected race conditions.
 	// Some of the init doesn't need a pidfile lock - but let's not try to be smart.
@@ -784,8 +868,17 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		}
 	}
 	config.Root = realRoot
+
+	uidMaps, gidMaps, err := setupRemappedRoot(config)
+	if err != nil {
+		return nil, err
+	}
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return nil, err
+	}
 	// Create the root directory if it doesn't exists
-	if err := os.MkdirAll(config.Root, 0700); err != nil && !os.IsExist(err) {
+	if err := setupDaemonRoot(config, rootUID, rootGID); err != nil {
 		return nil, err
 	}
 
@@ -806,7 +899,7 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 
 	daemonRepo := path.Join(config.Root, "containers")
 
-	if err := os.MkdirAll(daemonRepo, 0700); err != nil && !os.IsExist(err) {
+	if err := idtools.MkdirAllAs(daemonRepo, 0700, rootUID, rootGID); err != nil && !os.IsExist(err) {
 		return nil, err
 	}
 
@@ -815,6 +908,12 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		return nil, err
 	}
 
+	if uidMaps != nil {
+		if driver, err = graphdriver.NewRemappedDriver(driver, config.Root, uidMaps, gidMaps); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Debugf("Creating images graph")
 	g, err := graph.NewGraph(path.Join(config.Root, "graph"), driver)
 	if err != nil {
@@ -825,14 +924,33 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 	if err != nil {
 		return nil, err
 	}
+	if uidMaps != nil {
+		if volumesDriver, err = graphdriver.NewRemappedDriver(volumesDriver, config.Root, uidMaps, gidMaps); err != nil {
+			return nil, err
+		}
+	}
 
-	volumes, err := volumes.NewRepository(path.Join(config.Root, "volumes"), volumesDriver)
+	// The directories of the vfs driver hold the images too when it is the
+	// storage driver, the ones of the volumes can't be told apart then
+	var volumesData string
+	if driver.String() != "vfs" {
+		volumesData = path.Join(config.Root, "vfs", "dir")
+	}
+	volumes, err := volumes.NewRepository(path.Join(config.Root, "volumes"), volumesData, volumesDriver)
 	if err != nil {
 		return nil, err
 	}
 
 	log.Debugf("Creating repository list")
-	repositories, err := graph.NewTagStore(path.Join(config.Root, "repositories-"+driver.String()), g, config.Mirrors, config.InsecureRegistries)
+	if config.TrustKeyPath == "" {
+		config.TrustKeyPath = path.Join(config.Root, "key.json")
+	}
+	trustKey, err := api.LoadOrCreateTrustKey(config.TrustKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	repositories, err := graph.NewTagStore(path.Join(config.Root, "repositories-"+driver.String()), g, trustKey, config.Mirrors, config.InsecureRegistries)
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't create Tag store: %s", err)
 	}
@@ -856,6 +974,8 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		job.Setenv("BridgeIface", config.BridgeIface)
 		job.Setenv("BridgeIP", config.BridgeIP)
 		job.Setenv("FixedCIDR", config.FixedCIDR)
+		job.SetenvBool("EnableIPv6", config.EnableIPv6)
+		job.Setenv("FixedCIDRv6", config.FixedCIDRv6)
 		job.Setenv("DefaultBindingIP", config.DefaultIp.String())
 
 		if err := job.Run(); err != nil {
@@ -863,6 +983,11 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		}
 	}
 
+	networks, err := newNetworkStore(path.Join(config.Root, "networks"))
+	if err != nil {
+		return nil, err
+	}
+
 	graphdbPath := path.Join(config.Root, "linkgraph.db")
 	graph, err := graphdb.NewSqliteConn(graphdbPath)
 	if err != nil {
@@ -877,7 +1002,7 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 
 	if sysInitPath != localCopy {
 		// When we find a suitable dockerinit binary (even if it's our local binary), we copy it into config.Root at localCopy for future use (so that the original can go away without that being a problem, for example during a package upgrade).
-		if err := os.Mkdir(path.Dir(localCopy), 0700); err != nil && !os.IsExist(err) {
+		if err := idtools.MkdirAllAs(path.Dir(localCopy), 0700, rootUID, rootGID); err != nil && !os.IsExist(err) {
 			return nil, err
 		}
 		if _, err := utils.CopyFile(sysInitPath, localCopy); err != nil {
@@ -886,6 +1011,10 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		if err := os.Chmod(localCopy, 0700); err != nil {
 			return nil, err
 		}
+		// the init is executed by the root of the containers
+		if err := os.Chown(localCopy, rootUID, rootGID); err != nil {
+			return nil, err
+		}
 		sysInitPath = localCopy
 	}
 
@@ -911,7 +1040,12 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		execDriver:     ed,
 		eng:            eng,
 		trustStore:     t,
+		statsCollector: newStatsCollector(1 * time.Second),
+		networks:       networks,
+		uidMaps:        uidMaps,
+		gidMaps:        gidMaps,
 	}
+	daemon.restoreNetworks()
 	if err := daemon.restore(); err != nil {
 		return nil, err
 	}
@@ -927,8 +1061,11 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		if err := portallocator.ReleaseAll(); err != nil {
 			log.Errorf("portallocator.ReleaseAll(): %s", err)
 		}
-		if err := daemon.driver.Cleanup(); err != nil {
-			log.Errorf("daemon.driver.Cleanup(): %s", err.Error())
+		// the file systems of the containers left running stay mounted
+		if !daemon.config.LiveRestore {
+			if err := daemon.driver.Cleanup(); err != nil {
+				log.Errorf("daemon.driver.Cleanup(): %s", err.Error())
+			}
 		}
 		if err := daemon.containerGraph.Close(); err != nil {
 			log.Errorf("daemon.containerGraph.Close(): %s", err.Error())
@@ -939,6 +1076,10 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 }
 
 func (daemon *Daemon) shutdown() error {
+	if daemon.config.LiveRestore {
+		log.Debugf("leaving the containers running for live restore")
+		return nil
+	}
 	group := sync.WaitGroup{}
 	log.Debugf("starting clean shutdown of all containers...")
 	for _, container := range daemon.List() {
@@ -949,8 +1090,8 @@ func (daemon *Daemon) shutdown() error {
 
 			go func() {
 				defer group.Done()
-				if err := c.KillSig(15); err != nil {
-					log.Debugf("kill 15 error for %s - %s", c.ID, err)
+				if err := c.KillSig(c.StopSignal()); err != nil {
+					log.Debugf("kill %d error for %s - %s", c.StopSignal(), c.ID, err)
 				}
 				c.WaitStop(-1 * time.Second)
 				log.Debugf("container stopped %s", c.ID)
@@ -992,10 +1133,14 @@ func (daemon *Daemon) Diff(container *Container) (archive.Archive, error) {
 	return daemon.driver.Diff(container.ID, initID)
 }
 
-func (daemon *Daemon) Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (int, error) {
+func (daemon *Daemon) Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
 	return daemon.execDriver.Run(c.command, pipes, startCallback)
 }
 
+func (daemon *Daemon) Restore(c *Container, pipes *execdriver.Pipes, restoreCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	return daemon.execDriver.Restore(c.command, pipes, restoreCallback)
+}
+
 func (daemon *Daemon) Pause(c *Container) error {
 	if err := daemon.execDriver.Pause(c.command); err != nil {
 		return err
@@ -1016,6 +1161,10 @@ func (daemon *Daemon) Kill(c *Container, sig int) error {
 	return daemon.execDriver.Kill(c.command, sig)
 }
 
+func (daemon *Daemon) Stats(c *Container) (*execdriver.ResourceStats, error) {
+	return daemon.execDriver.Stats(c.ID)
+}
+
 // Nuke kills all containers then removes all content
 // from the content root, including images, volumes and
 // container filesystems.
@@ -71,6 +71,11 @@ func (daemon *Daemon) ContainerRm(job *engine.Job) engine.Status {
 
 func (daemon *Daemon) DeleteVolumes(volumeIDs map[string]struct{}) {
 	for id := range volumeIDs {
+		// Named volumes outlive their containers, they are only removed
+		// with docker volume rm
+		if v := daemon.volumes.Get(id); v != nil && v.Name != "" {
+			continue
+		}
 		if err := daemon.volumes.Delete(id); err != nil {
 			log.Infof("%s", err)
 			continue
@@ -24,6 +24,7 @@ type execConfig struct {
 	sync.Mutex
 	ID            string
 	Running       bool
+	ExitCode      int
 	ProcessConfig execdriver.ProcessConfig
 	StreamConfig
 	OpenStdin  bool
@@ -279,6 +280,9 @@ func (container *Container) monitorExec(execConfig *execConfig, callback execdri
 	}
 
 	log.Debugf("Exec task in container %s exited with code %d", container.ID, exitCode)
+	execConfig.Lock()
+	execConfig.ExitCode = exitCode
+	execConfig.Unlock()
 	if execConfig.OpenStdin {
 		if err := execConfig.StreamConfig.stdin.Close(); err != nil {
 			log.Errorf("Error closing stdin while running in %s: %s", container.ID, err)
@@ -5,7 +5,10 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"time"
 
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/devices"
 )
 
@@ -22,6 +25,15 @@ var (
 
 type StartCallback func(*ProcessConfig, int)
 
+// ExitStatus provides exit reasons for a container.
+type ExitStatus struct {
+	// The exit code with which the container exited.
+	ExitCode int
+
+	// Whether the container encountered an OOM.
+	OOMKilled bool
+}
+
 // Driver specific information based on
 // processes registered with the driver
 type Info interface {
@@ -41,7 +53,10 @@ type TtyTerminal interface {
 }
 
 type Driver interface {
-	Run(c *Command, pipes *Pipes, startCallback StartCallback) (int, error) // Run executes the process and blocks until the process exits and returns the exit code
+	Run(c *Command, pipes *Pipes, startCallback StartCallback) (ExitStatus, error) // Run executes the process and blocks until the process exits and returns the exit status
+	// Restore reattaches to the process of a container run with LiveRestore by a previous daemon,
+	// blocks until the process exits and returns the exit status
+	Restore(c *Command, pipes *Pipes, restoreCallback StartCallback) (ExitStatus, error)
 	// Exec executes the process in a running container, blocks until the process exits and returns the exit code
 	Exec(c *Command, processConfig *ProcessConfig, pipes *Pipes, startCallback StartCallback) (int, error)
 	Kill(c *Command, sig int) error
@@ -52,6 +67,12 @@ type Driver interface {
 	GetPidsForContainer(id string) ([]int, error) // Returns a list of pids for the given container.
 	Terminate(c *Command) error                   // kill it with fire
 	Clean(id string) error                        // clean all traces of container exec
+	Stats(id string) (*ResourceStats, error)      // Get resource stats for a running container
+	Update(c *Command) error                      // Apply the Resources of the command to the running container
+	// ConnectInterface adds an interface on the bridge of iface to the running container
+	ConnectInterface(c *Command, iface *NetworkInterface) error
+	// DisconnectInterface removes the interface with the mac address of iface from the running container
+	DisconnectInterface(c *Command, iface *NetworkInterface) error
 }
 
 // Network settings of the container
@@ -63,11 +84,14 @@ type Network struct {
 }
 
 type NetworkInterface struct {
-	Gateway     string `json:"gateway"`
-	IPAddress   string `json:"ip"`
-	IPPrefixLen int    `json:"ip_prefix_len"`
-	MacAddress  string `json:"mac_address"`
-	Bridge      string `json:"bridge"`
+	Gateway             string `json:"gateway"`
+	IPAddress           string `json:"ip"`
+	IPPrefixLen         int    `json:"ip_prefix_len"`
+	MacAddress          string `json:"mac_address"`
+	Bridge              string `json:"bridge"`
+	GlobalIPv6Address   string `json:"global_ipv6"`
+	GlobalIPv6PrefixLen int    `json:"global_ipv6_prefix_len"`
+	IPv6Gateway         string `json:"ipv6_gateway"`
 }
 
 type Resources struct {
@@ -77,12 +101,25 @@ type Resources struct {
 	Cpuset     string `json:"cpuset"`
 }
 
+// ResourceStats is a snapshot of the resource usage of a running container
+// as reported by the execution driver.
+type ResourceStats struct {
+	*libcontainer.ContainerStats
+	Read        time.Time `json:"read"`
+	MemoryLimit int64     `json:"memory_limit"`
+	SystemUsage uint64    `json:"system_usage"`
+}
+
 type Mount struct {
 	Source      string `json:"source"`
 	Destination string `json:"destination"`
 	Writable    bool   `json:"writable"`
 	Private     bool   `json:"private"`
 	Slave       bool   `json:"slave"`
+	// Type is "tmpfs" for tmpfs mounts, which have no source and whose
+	// options are given by Data, or empty for bind mounts
+	Type string `json:"type"`
+	Data string `json:"data"`
 }
 
 // Describes a process that will be run inside a container.
@@ -118,4 +155,9 @@ type Command struct {
 	MountLabel         string            `json:"mount_label"`
 	LxcConfig          []string          `json:"lxc_config"`
 	AppArmorProfile    string            `json:"apparmor_profile"`
+	SeccompProfile     string            `json:"seccomp_profile"` // JSON profile, "unconfined" or "" for the default one
+	ReadonlyRootfs     bool              `json:"readonly_rootfs"`
+	UidMapping         []idtools.IDMap   `json:"uid_mapping"` // user namespace mappings, nil when disabled
+	GidMapping         []idtools.IDMap   `json:"gid_mapping"`
+	LiveRestore        bool              `json:"live_restore"` // run the process under a shim outliving the daemon
 }
@@ -103,6 +103,71 @@ func TestCustomLxcConfig(t *testing.T) {
 	grepFile(t, p, "lxc.cgroup.cpuset.cpus = 0,1")
 }
 
+func TestReadonlyRootfsLxcConfig(t *testing.T) {
+	root, err := ioutil.TempDir("", "TestReadonlyRootfsLxcConfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(path.Join(root, "containers", "1"), 0777)
+
+	driver, err := NewDriver(root, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := &execdriver.Command{
+		ID: "1",
+		Network: &execdriver.Network{
+			Mtu:       1500,
+			Interface: nil,
+		},
+		ReadonlyRootfs: true,
+	}
+
+	p, err := driver.generateLXCConfig(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grepFile(t, p, "lxc.rootfs.options = ro")
+}
+
+func TestTmpfsLxcConfig(t *testing.T) {
+	root, err := ioutil.TempDir("", "TestTmpfsLxcConfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(path.Join(root, "containers", "1"), 0777)
+
+	driver, err := NewDriver(root, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := &execdriver.Command{
+		ID:     "1",
+		Rootfs: "/rootfs",
+		Network: &execdriver.Network{
+			Mtu:       1500,
+			Interface: nil,
+		},
+		Mounts: []execdriver.Mount{
+			{Destination: "/run", Type: "tmpfs"},
+			{Destination: "/tmp", Type: "tmpfs", Data: "exec,size=64m"},
+		},
+	}
+
+	p, err := driver.generateLXCConfig(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grepFile(t, p, "lxc.mount.entry = tmpfs /rootfs//run tmpfs noexec,nosuid,nodev 0 0")
+	grepFile(t, p, "lxc.mount.entry = tmpfs /rootfs//tmp tmpfs noexec,nosuid,nodev,exec,size=64m 0 0")
+}
+
 func grepFile(t *testing.T, path string, pattern string) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -9,7 +9,9 @@ import (
 	"path/filepath"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/execdriver/native/seccomp"
 	"github.com/docker/docker/daemon/execdriver/native/template"
+	dockermount "github.com/docker/docker/pkg/mount"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/apparmor"
 	"github.com/docker/libcontainer/devices"
@@ -31,6 +33,7 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 	container.Cgroups.AllowedDevices = c.AllowedDevices
 	container.MountConfig.DeviceNodes = c.AutoCreatedDevices
 	container.RootFs = c.Rootfs
+	container.MountConfig.ReadonlyFs = c.ReadonlyRootfs
 
 	// check to see if we are running in ramdisk to disable pivot root
 	container.MountConfig.NoPivotRoot = os.Getenv("DOCKER_RAMDISK") != ""
@@ -40,6 +43,8 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 		return nil, err
 	}
 
+	d.setupUserNamespace(container, c)
+
 	if c.ProcessConfig.Privileged {
 		if err := d.setPrivileged(container); err != nil {
 			return nil, err
@@ -54,6 +59,10 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
+	if err := d.setupSeccomp(container, c); err != nil {
+		return nil, err
+	}
+
 	if err := d.setupCgroups(container, c); err != nil {
 		return nil, err
 	}
@@ -76,6 +85,25 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 	return container, nil
 }
 
+func (d *driver) setupUserNamespace(container *libcontainer.Config, c *execdriver.Command) {
+	if c.UidMapping == nil {
+		return
+	}
+	container.Namespaces["NEWUSER"] = true
+
+	// device nodes can't be created in a user namespace, the ones of the
+	// host are bind mounted instead
+	for _, node := range container.MountConfig.DeviceNodes {
+		container.MountConfig.Mounts = append(container.MountConfig.Mounts, &mount.Mount{
+			Type:        "bind",
+			Source:      node.Path,
+			Destination: node.Path,
+			Writable:    true,
+		})
+	}
+	container.MountConfig.DeviceNodes = nil
+}
+
 func (d *driver) createNetwork(container *libcontainer.Config, c *execdriver.Command) error {
 	if c.Network.HostNetworking {
 		container.Namespaces["NEWNET"] = false
@@ -101,6 +129,10 @@ func (d *driver) createNetwork(container *libcontainer.Config, c *execdriver.Com
 			Bridge:     c.Network.Interface.Bridge,
 			VethPrefix: "veth",
 		}
+		if c.Network.Interface.GlobalIPv6Address != "" {
+			vethNetwork.IPv6Address = fmt.Sprintf("%s/%d", c.Network.Interface.GlobalIPv6Address, c.Network.Interface.GlobalIPv6PrefixLen)
+			vethNetwork.IPv6Gateway = c.Network.Interface.IPv6Gateway
+		}
 		container.Networks = append(container.Networks, &vethNetwork)
 	}
 
@@ -148,6 +180,19 @@ func (d *driver) setCapabilities(container *libcontainer.Config, c *execdriver.C
 	return err
 }
 
+func (d *driver) setupSeccomp(container *libcontainer.Config, c *execdriver.Command) error {
+	// the vendored libcontainer cannot install seccomp filters yet, the
+	// default profile is skipped like the AppArmor one where it is not
+	// supported, but a profile asked for explicitly must not be ignored
+	if c.ProcessConfig.Privileged || c.SeccompProfile == "" || c.SeccompProfile == "unconfined" {
+		return nil
+	}
+	if _, err := seccomp.LoadProfile(c.SeccompProfile); err != nil {
+		return err
+	}
+	return fmt.Errorf("seccomp profiles are not supported by the native driver yet")
+}
+
 func (d *driver) setupCgroups(container *libcontainer.Config, c *execdriver.Command) error {
 	if c.Resources != nil {
 		container.Cgroups.CpuShares = c.Resources.CpuShares
@@ -162,6 +207,19 @@ func (d *driver) setupCgroups(container *libcontainer.Config, c *execdriver.Comm
 
 func (d *driver) setupMounts(container *libcontainer.Config, c *execdriver.Command) error {
 	for _, m := range c.Mounts {
+		if m.Type == "tmpfs" {
+			flags, data, err := dockermount.ParseTmpfsOptions(m.Data)
+			if err != nil {
+				return err
+			}
+			container.MountConfig.Mounts = append(container.MountConfig.Mounts, &mount.Mount{
+				Type:        "tmpfs",
+				Destination: m.Destination,
+				Flags:       flags,
+				Data:        data,
+			})
+			continue
+		}
 		container.MountConfig.Mounts = append(container.MountConfig.Mounts, &mount.Mount{
 			Type:        "bind",
 			Source:      m.Source,
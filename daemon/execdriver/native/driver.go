@@ -13,8 +13,12 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/idtools"
+	sysinfo "github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/apparmor"
@@ -31,6 +35,13 @@ const (
 	Version    = "0.2"
 )
 
+// idMappings are the user namespace mappings of a container, saved in its
+// directory for the shim.
+type idMappings struct {
+	Uid []idtools.IDMap `json:"uid"`
+	Gid []idtools.IDMap `json:"gid"`
+}
+
 type activeContainer struct {
 	container *libcontainer.Config
 	cmd       *exec.Cmd
@@ -40,6 +51,7 @@ type driver struct {
 	root             string
 	initPath         string
 	activeContainers map[string]*activeContainer
+	machineMemory    int64
 	sync.Mutex
 }
 
@@ -53,18 +65,28 @@ func NewDriver(root, initPath string) (*driver, error) {
 		return nil, err
 	}
 
+	meminfo, err := sysinfo.ReadMemInfo()
+	if err != nil {
+		return nil, err
+	}
+
 	return &driver{
 		root:             root,
 		initPath:         initPath,
 		activeContainers: make(map[string]*activeContainer),
+		machineMemory:    meminfo.MemTotal,
 	}, nil
 }
 
-func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (int, error) {
+func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	if c.LiveRestore {
+		return d.runWithShim(c, pipes, startCallback)
+	}
+
 	// take the Command and populate the libcontainer.Config from it
 	container, err := d.createContainer(c)
 	if err != nil {
-		return -1, err
+		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
 	var term execdriver.Terminal
@@ -75,7 +97,7 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		term, err = execdriver.NewStdConsole(&c.ProcessConfig, pipes)
 	}
 	if err != nil {
-		return -1, err
+		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 	c.ProcessConfig.Terminal = term
 
@@ -92,40 +114,135 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 	)
 
 	if err := d.createContainerRoot(c.ID); err != nil {
-		return -1, err
+		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 	defer d.cleanContainer(c.ID)
 
 	if err := d.writeContainerFile(container, c.ID); err != nil {
-		return -1, err
-	}
-
-	return namespaces.Exec(container, c.ProcessConfig.Stdin, c.ProcessConfig.Stdout, c.ProcessConfig.Stderr, c.ProcessConfig.Console, dataPath, args, func(container *libcontainer.Config, console, dataPath, init string, child *os.File, args []string) *exec.Cmd {
-		c.ProcessConfig.Path = d.initPath
-		c.ProcessConfig.Args = append([]string{
-			DriverName,
-			"-console", console,
-			"-pipe", "3",
-			"-root", filepath.Join(d.root, c.ID),
-			"--",
-		}, args...)
-
-		// set this to nil so that when we set the clone flags anything else is reset
-		c.ProcessConfig.SysProcAttr = &syscall.SysProcAttr{
-			Cloneflags: uintptr(namespaces.GetNamespaceFlags(container.Namespaces)),
-		}
-		c.ProcessConfig.ExtraFiles = []*os.File{child}
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	// the configuration is passed as a file descriptor, the root of a
+	// container in a user namespace can't read the directory of the driver
+	configFile, err := os.Open(filepath.Join(dataPath, "container.json"))
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	defer configFile.Close()
+
+	mappings := newIDMappings(c)
+	if err := setupConsole(c.ProcessConfig.Console, mappings); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
 
-		c.ProcessConfig.Env = container.Env
-		c.ProcessConfig.Dir = container.RootFs
+	var oomKillNotification <-chan struct{}
 
+	exitCode, err := namespaces.Exec(container, c.ProcessConfig.Stdin, c.ProcessConfig.Stdout, c.ProcessConfig.Stderr, c.ProcessConfig.Console, dataPath, args, func(container *libcontainer.Config, console, dataPath, init string, child *os.File, args []string) *exec.Cmd {
+		setupInitCommand(&c.ProcessConfig.Cmd, d.initPath, dataPath, container, mappings, console, child, configFile, args)
 		return &c.ProcessConfig.Cmd
 	}, func() {
+		// the cgroups of the container exist once it is started
+		oomKillNotification = notifyOnOOM(container)
+
 		if startCallback != nil {
 			c.ContainerPid = c.ProcessConfig.Process.Pid
 			startCallback(&c.ProcessConfig, c.ContainerPid)
 		}
 	})
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: exitCode}, err
+	}
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKilled(oomKillNotification)}, nil
+}
+
+// setupInitCommand sets up cmd to execute the init of the container in its
+// namespaces, with the configuration read from configFile.
+func setupInitCommand(cmd *exec.Cmd, initPath, dataPath string, container *libcontainer.Config, mappings *idMappings, console string, child, configFile *os.File, args []string) {
+	cmd.Path = initPath
+	cmd.Args = append([]string{
+		DriverName,
+		"-console", console,
+		"-pipe", "3",
+		"-config", "4",
+		"-root", dataPath,
+		"--",
+	}, args...)
+
+	// set this to nil so that when we set the clone flags anything else is reset
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: uintptr(namespaces.GetNamespaceFlags(container.Namespaces)),
+	}
+	if mappings != nil {
+		setupIDMappings(cmd.SysProcAttr, mappings)
+	}
+	cmd.ExtraFiles = []*os.File{child, configFile}
+
+	cmd.Env = container.Env
+	cmd.Dir = container.RootFs
+}
+
+// newIDMappings returns the user namespace mappings of the command, nil
+// when user namespaces are disabled.
+func newIDMappings(c *execdriver.Command) *idMappings {
+	if c.UidMapping == nil {
+		return nil
+	}
+	return &idMappings{Uid: c.UidMapping, Gid: c.GidMapping}
+}
+
+// setupIDMappings has the mappings written for the init before it executes,
+// the init is root in its user namespace from there on.
+func setupIDMappings(attr *syscall.SysProcAttr, mappings *idMappings) {
+	for _, m := range mappings.Uid {
+		attr.UidMappings = append(attr.UidMappings, syscall.SysProcIDMap{ContainerID: m.ContainerID, HostID: m.HostID, Size: m.Size})
+	}
+	for _, m := range mappings.Gid {
+		attr.GidMappings = append(attr.GidMappings, syscall.SysProcIDMap{ContainerID: m.ContainerID, HostID: m.HostID, Size: m.Size})
+	}
+	enableSetgroups(attr)
+	attr.Credential = &syscall.Credential{Uid: 0, Gid: 0}
+}
+
+// setupConsole gives the console to the root of the container, which can't
+// change the owner of a file of the host in a user namespace.
+func setupConsole(console string, mappings *idMappings) error {
+	if console == "" || mappings == nil {
+		return nil
+	}
+	uid, gid, err := idtools.GetRootUIDGID(mappings.Uid, mappings.Gid)
+	if err != nil {
+		return err
+	}
+	return os.Chown(console, uid, gid)
+}
+
+// notifyOnOOM registers for the OOM notifications of the memory cgroup of the
+// container, it returns nil when the kernel or the cgroup driver does not
+// support them.
+func notifyOnOOM(container *libcontainer.Config) <-chan struct{} {
+	if container.Cgroups == nil {
+		return nil
+	}
+	if systemd.UseSystemd() {
+		log.Debugf("OOM notifications are not supported with the systemd cgroup driver")
+		return nil
+	}
+	ch, err := fs.NotifyOnOOM(container.Cgroups)
+	if err != nil {
+		log.Warnf("Your kernel does not support OOM notifications: %s", err)
+		return nil
+	}
+	return ch
+}
+
+// oomKilled reports whether an OOM notification was received before the memory
+// cgroup of the container was removed, which closes the channel.
+func oomKilled(ch <-chan struct{}) bool {
+	if ch == nil {
+		return false
+	}
+	_, ok := <-ch
+	return ok
 }
 
 func (d *driver) Kill(p *execdriver.Command, sig int) error {
@@ -242,6 +359,39 @@ func (d *driver) Clean(id string) error {
 	return os.RemoveAll(filepath.Join(d.root, id))
 }
 
+func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+
+	if active == nil {
+		return nil, execdriver.ErrNotRunning
+	}
+	state, err := libcontainer.GetState(filepath.Join(d.root, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, execdriver.ErrNotRunning
+		}
+		return nil, err
+	}
+	now := time.Now()
+	stats, err := libcontainer.GetStats(active.container, state)
+	if err != nil {
+		return nil, err
+	}
+	memoryLimit := active.container.Cgroups.Memory
+	// if the container does not have any memory limit specified set the
+	// limit to the machines memory
+	if memoryLimit == 0 {
+		memoryLimit = d.machineMemory
+	}
+	return &execdriver.ResourceStats{
+		Read:           now,
+		ContainerStats: stats,
+		MemoryLimit:    memoryLimit,
+	}, nil
+}
+
 func getEnv(key string, env []string) string {
 	for _, pair := range env {
 		parts := strings.Split(pair, "=")
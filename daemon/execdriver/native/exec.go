@@ -44,6 +44,11 @@ func (d *driver) Exec(c *execdriver.Command, processConfig *execdriver.ProcessCo
 	if active == nil {
 		return -1, fmt.Errorf("No active container exists with ID %s", c.ID)
 	}
+	// nsenter can't join the user namespace, the process would run as the
+	// root of the host
+	if active.container.Namespaces["NEWUSER"] {
+		return -1, fmt.Errorf("exec is not supported in containers with a user namespace")
+	}
 	state, err := libcontainer.GetState(filepath.Join(d.root, c.ID))
 	if err != nil {
 		return -1, fmt.Errorf("State unavailable for container with ID %s. The container may have been cleaned up already. Error: %s", c.ID, err)
@@ -26,14 +26,20 @@ func initializer() {
 	var (
 		pipe    = flag.Int("pipe", 0, "sync pipe fd")
 		console = flag.String("console", "", "console (pty slave) path")
+		config  = flag.Int("config", 0, "configuration fd")
 		root    = flag.String("root", ".", "root path for configuration files")
 	)
 
 	flag.Parse()
 
-	var container *libcontainer.Config
-	f, err := os.Open(filepath.Join(*root, "container.json"))
-	if err != nil {
+	var (
+		container *libcontainer.Config
+		f         *os.File
+		err       error
+	)
+	if *config != 0 {
+		f = os.NewFile(uintptr(*config), "container.json")
+	} else if f, err = os.Open(filepath.Join(*root, "container.json")); err != nil {
 		writeError(err)
 	}
 
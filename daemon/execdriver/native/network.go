@@ -0,0 +1,191 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/netlink"
+	"github.com/docker/libcontainer/network"
+	"github.com/docker/libcontainer/system"
+	"github.com/docker/libcontainer/utils"
+)
+
+// ConnectInterface creates a veth pair on the bridge of iface and moves its
+// peer into the network namespace of the running container, where it is
+// configured as the first free ethN interface. The default route of the
+// container is left unchanged.
+func (d *driver) ConnectInterface(c *execdriver.Command, iface *execdriver.NetworkInterface) error {
+	pid, err := d.networkPid(c)
+	if err != nil {
+		return err
+	}
+
+	hostName, childName, err := createVethPair("veth")
+	if err != nil {
+		return err
+	}
+	if err := setupHostVeth(hostName, childName, iface.Bridge, c.Network.Mtu, pid); err != nil {
+		netlink.NetworkLinkDel(hostName)
+		return err
+	}
+
+	err = inNetNamespace(pid, func() error {
+		name, err := freeInterfaceName()
+		if err != nil {
+			return err
+		}
+		if err := network.ChangeInterfaceName(childName, name); err != nil {
+			return fmt.Errorf("change %s to %s %s", childName, name, err)
+		}
+		if iface.MacAddress != "" {
+			if err := network.SetInterfaceMac(name, iface.MacAddress); err != nil {
+				return fmt.Errorf("set %s mac %s", name, err)
+			}
+		}
+		if err := network.SetInterfaceIp(name, fmt.Sprintf("%s/%d", iface.IPAddress, iface.IPPrefixLen)); err != nil {
+			return fmt.Errorf("set %s ip %s", name, err)
+		}
+		if err := network.SetMtu(name, c.Network.Mtu); err != nil {
+			return fmt.Errorf("set %s mtu to %d %s", name, c.Network.Mtu, err)
+		}
+		if err := network.InterfaceUp(name); err != nil {
+			return fmt.Errorf("%s up %s", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		// deleting the host end removes the pair
+		netlink.NetworkLinkDel(hostName)
+	}
+	return err
+}
+
+// DisconnectInterface deletes the interface of the running container which
+// has the mac address of iface, along with its veth peer on the host.
+func (d *driver) DisconnectInterface(c *execdriver.Command, iface *execdriver.NetworkInterface) error {
+	pid, err := d.networkPid(c)
+	if err != nil {
+		return err
+	}
+	mac, err := net.ParseMAC(iface.MacAddress)
+	if err != nil {
+		return err
+	}
+
+	return inNetNamespace(pid, func() error {
+		ifaces, err := net.Interfaces()
+		if err != nil {
+			return err
+		}
+		for _, i := range ifaces {
+			if i.HardwareAddr.String() == mac.String() {
+				return netlink.NetworkLinkDel(i.Name)
+			}
+		}
+		return fmt.Errorf("no interface with mac address %s in container %s", mac, c.ID)
+	})
+}
+
+// networkPid returns the pid of the init process of the running container,
+// its network namespace is the one of the container.
+func (d *driver) networkPid(c *execdriver.Command) (int, error) {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+
+	if active == nil || c.ProcessConfig.Process == nil {
+		return 0, fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	return c.ProcessConfig.Process.Pid, nil
+}
+
+func createVethPair(prefix string) (string, string, error) {
+	for i := 0; i < 10; i++ {
+		name1, err := utils.GenerateRandomName(prefix, 7)
+		if err != nil {
+			return "", "", err
+		}
+		name2, err := utils.GenerateRandomName(prefix, 7)
+		if err != nil {
+			return "", "", err
+		}
+		if err := network.CreateVethPair(name1, name2, 0); err != nil {
+			if err == netlink.ErrInterfaceExists {
+				continue
+			}
+			return "", "", err
+		}
+		return name1, name2, nil
+	}
+	return "", "", fmt.Errorf("could not find a free name for a veth pair")
+}
+
+func setupHostVeth(hostName, childName, bridge string, mtu, pid int) error {
+	if err := network.SetInterfaceMaster(hostName, bridge); err != nil {
+		return err
+	}
+	if err := network.SetMtu(hostName, mtu); err != nil {
+		return err
+	}
+	if err := network.InterfaceUp(hostName); err != nil {
+		return err
+	}
+	return network.SetInterfaceInNamespacePid(childName, pid)
+}
+
+// freeInterfaceName returns the first ethN name, eth0 excluded, which is not
+// used in the current network namespace.
+func freeInterfaceName() (string, error) {
+	for i := 1; i < 100; i++ {
+		name := fmt.Sprintf("eth%d", i)
+		if _, err := net.InterfaceByName(name); err != nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no free interface name")
+}
+
+// inNetNamespace runs f with the current thread in the network namespace of
+// the process pid.
+func inNetNamespace(pid int, f func() error) error {
+	runtime.LockOSThread()
+	restored := true
+	defer func() {
+		// a thread left in the namespace of the container must not run
+		// other goroutines, it stays locked to this one
+		if restored {
+			runtime.UnlockOSThread()
+		}
+	}()
+
+	origin, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", syscall.Gettid()))
+	if err != nil {
+		return err
+	}
+	defer origin.Close()
+
+	ns, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	if err := system.Setns(ns.Fd(), syscall.CLONE_NEWNET); err != nil {
+		return fmt.Errorf("failed to join the network namespace of %d: %v", pid, err)
+	}
+	defer func() {
+		if err := system.Setns(origin.Fd(), syscall.CLONE_NEWNET); err != nil {
+			restored = false
+			log.Errorf("failed to restore the network namespace of the daemon: %v", err)
+		}
+	}()
+
+	return f()
+}
@@ -0,0 +1,272 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+// runWithShim starts the container under a shim, which outlives the daemon,
+// and blocks until it exits.
+func (d *driver) runWithShim(c *execdriver.Command, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	container, err := d.createContainer(c)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	d.Lock()
+	d.activeContainers[c.ID] = &activeContainer{
+		container: container,
+		cmd:       &c.ProcessConfig.Cmd,
+	}
+	d.Unlock()
+
+	dataPath := filepath.Join(d.root, c.ID)
+	if err := d.createContainerRoot(c.ID); err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	if err := d.writeContainerFile(container, c.ID); err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	mappings, err := json.Marshal(newIDMappings(c))
+	if err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dataPath, shimIDMappings), mappings, 0600); err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	if err := createShimFifos(dataPath); err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	statusR, statusW, err := os.Pipe()
+	if err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	defer statusR.Close()
+
+	cmd := exec.Command(d.initPath, append([]string{
+		"-root", dataPath,
+		"-init", d.initPath,
+		"-tty=" + strconv.FormatBool(c.ProcessConfig.Tty),
+		"-stdin=" + strconv.FormatBool(pipes.Stdin != nil),
+		"--",
+		c.ProcessConfig.Entrypoint,
+	}, c.ProcessConfig.Arguments...)...)
+	cmd.Args[0] = shimCommandName
+	// the shim must not receive the signals sent to the daemon
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.ExtraFiles = []*os.File{statusW}
+	err = cmd.Start()
+	statusW.Close()
+	if err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	// the daemon is not around to reap the shim if it is restarted
+	defer cmd.Wait()
+
+	var status shimStatus
+	if err := json.NewDecoder(statusR).Decode(&status); err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("Failed to start the shim of container %s: %s", c.ID, err)
+	}
+	if status.Error != "" {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("%s", status.Error)
+	}
+	return d.attachShim(c, status.Pid, pipes, startCallback)
+}
+
+// Restore reattaches to a container run under a shim by a previous daemon.
+func (d *driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, restoreCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	dataPath := filepath.Join(d.root, c.ID)
+	if _, err := os.Stat(filepath.Join(dataPath, shimExit)); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("Container %s was not started with live restore", c.ID)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dataPath, "container.json"))
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	var container *libcontainer.Config
+	if err := json.Unmarshal(data, &container); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	d.Lock()
+	d.activeContainers[c.ID] = &activeContainer{
+		container: container,
+		cmd:       &c.ProcessConfig.Cmd,
+	}
+	d.Unlock()
+
+	state, err := libcontainer.GetState(dataPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			d.cleanContainer(c.ID)
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+		// the container exited while the daemon was down
+		exitCode, err := d.waitShim(c.ID)
+		return execdriver.ExitStatus{ExitCode: exitCode}, err
+	}
+	return d.attachShim(c, state.InitPid, pipes, restoreCallback)
+}
+
+// attachShim connects the pipes of the daemon to the FIFOs of the shim of
+// the container whose init has the given pid, and blocks until it exits.
+func (d *driver) attachShim(c *execdriver.Command, pid int, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	dataPath := filepath.Join(d.root, c.ID)
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	c.ProcessConfig.Process = process
+	c.ContainerPid = pid
+
+	term, err := newShimTerminal(dataPath)
+	if err != nil {
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	c.ProcessConfig.Terminal = term
+
+	var wg sync.WaitGroup
+	for name, w := range map[string]io.Writer{shimStdout: pipes.Stdout, shimStderr: pipes.Stderr} {
+		if w == nil {
+			continue
+		}
+		f, err := openFifo(filepath.Join(dataPath, name), os.O_RDONLY)
+		if err != nil {
+			term.Close()
+			d.cleanContainer(c.ID)
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+		wg.Add(1)
+		go func(w io.Writer, f *os.File) {
+			defer wg.Done()
+			defer f.Close()
+			io.Copy(w, f)
+		}(w, f)
+	}
+	if pipes.Stdin != nil {
+		go func() {
+			io.Copy(term.stdin, pipes.Stdin)
+			term.closeStdin()
+		}()
+	}
+
+	d.Lock()
+	container := d.activeContainers[c.ID].container
+	d.Unlock()
+	oomKillNotification := notifyOnOOM(container)
+
+	if startCallback != nil {
+		startCallback(&c.ProcessConfig, pid)
+	}
+
+	exitCode, err := d.waitShim(c.ID)
+	wg.Wait()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: exitCode}, err
+	}
+	// the shim removes the cgroups of the container before it exits
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKilled(oomKillNotification)}, nil
+}
+
+// waitShim blocks until the shim of the container exits, and returns the
+// exit code of the container.
+func (d *driver) waitShim(id string) (int, error) {
+	defer d.cleanContainer(id)
+
+	dataPath := filepath.Join(d.root, id)
+	exit, err := openFifo(filepath.Join(dataPath, shimExit), os.O_RDONLY)
+	if err != nil {
+		return -1, err
+	}
+	// the FIFO is closed once the shim exits
+	io.Copy(ioutil.Discard, exit)
+	exit.Close()
+
+	data, err := ioutil.ReadFile(filepath.Join(dataPath, shimExitStatus))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return -1, fmt.Errorf("The shim of container %s exited without the exit code of the container", id)
+		}
+		return -1, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// shimTerminal writes the input of the container and the resize requests
+// to the FIFOs of its shim.
+type shimTerminal struct {
+	sync.Mutex
+	root    string
+	stdin   *os.File
+	control *os.File
+}
+
+func newShimTerminal(root string) (*shimTerminal, error) {
+	// opened for reading too, so that the shim always has a writer to read
+	// from once the FIFOs are opened
+	stdin, err := openFifo(filepath.Join(root, shimStdin), os.O_RDWR)
+	if err != nil {
+		return nil, err
+	}
+	control, err := openFifo(filepath.Join(root, shimControl), os.O_RDWR)
+	if err != nil {
+		stdin.Close()
+		return nil, err
+	}
+	return &shimTerminal{
+		root:    root,
+		stdin:   stdin,
+		control: control,
+	}, nil
+}
+
+func (t *shimTerminal) Resize(h, w int) error {
+	t.Lock()
+	defer t.Unlock()
+	_, err := fmt.Fprintf(t.control, "resize %d %d\n", h, w)
+	return err
+}
+
+// closeStdin tells the shim to close the stdin of the container, once the
+// data written so far is read.
+func (t *shimTerminal) closeStdin() error {
+	f, err := os.Create(filepath.Join(t.root, shimStdinClosed))
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return t.stdin.Close()
+}
+
+func (t *shimTerminal) Close() error {
+	t.stdin.Close()
+	return t.control.Close()
+}
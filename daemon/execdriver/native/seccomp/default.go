@@ -0,0 +1,78 @@
+package seccomp
+
+import (
+	"strings"
+)
+
+// blocked are the syscalls failing with EPERM in the default profile, with
+// the capability which allows them when the container has it.
+var blocked = []struct {
+	name       string
+	capability string
+}{
+	{"acct", "SYS_PACCT"},
+	{"add_key", ""},
+	{"adjtimex", "SYS_TIME"},
+	{"bpf", "SYS_ADMIN"},
+	{"clock_adjtime", "SYS_TIME"},
+	{"clock_settime", "SYS_TIME"},
+	{"create_module", "SYS_MODULE"},
+	{"delete_module", "SYS_MODULE"},
+	{"finit_module", "SYS_MODULE"},
+	{"get_kernel_syms", "SYS_MODULE"},
+	{"init_module", "SYS_MODULE"},
+	{"ioperm", "SYS_RAWIO"},
+	{"iopl", "SYS_RAWIO"},
+	{"kexec_file_load", "SYS_BOOT"},
+	{"kexec_load", "SYS_BOOT"},
+	{"keyctl", ""},
+	{"lookup_dcookie", "SYS_ADMIN"},
+	{"mount", "SYS_ADMIN"},
+	{"name_to_handle_at", "DAC_READ_SEARCH"},
+	{"nfsservctl", "SYS_ADMIN"},
+	{"open_by_handle_at", "DAC_READ_SEARCH"},
+	{"perf_event_open", "SYS_ADMIN"},
+	{"pivot_root", "SYS_ADMIN"},
+	{"process_vm_readv", "SYS_PTRACE"},
+	{"process_vm_writev", "SYS_PTRACE"},
+	{"ptrace", "SYS_PTRACE"},
+	{"query_module", "SYS_MODULE"},
+	{"quotactl", "SYS_ADMIN"},
+	{"reboot", "SYS_BOOT"},
+	{"request_key", ""},
+	{"setns", "SYS_ADMIN"},
+	{"settimeofday", "SYS_TIME"},
+	{"stime", "SYS_TIME"},
+	{"swapoff", "SYS_ADMIN"},
+	{"swapon", "SYS_ADMIN"},
+	{"_sysctl", ""},
+	{"umount", "SYS_ADMIN"},
+	{"umount2", "SYS_ADMIN"},
+	{"unshare", "SYS_ADMIN"},
+	{"uselib", ""},
+	{"ustat", ""},
+	{"vhangup", "SYS_TTY_CONFIG"},
+}
+
+// DefaultProfile returns the seccomp filter of the containers started
+// without a profile, given their capabilities. It blocks the syscalls
+// which could be used to break out of the container or act on the whole
+// host, except the ones allowed by the capabilities.
+func DefaultProfile(capabilities []string) *Filter {
+	caps := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		caps[strings.ToUpper(c)] = true
+	}
+
+	filter := &Filter{DefaultAction: Allow}
+	for _, b := range blocked {
+		if b.capability != "" && caps[b.capability] {
+			continue
+		}
+		filter.Rules = append(filter.Rules, &Rule{
+			Name:   b.name,
+			Action: Errno,
+		})
+	}
+	return filter
+}
@@ -0,0 +1,105 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Action is what happens when a process makes a syscall matched by a
+// filter.
+type Action int
+
+const (
+	// Allow lets the syscall through.
+	Allow Action = iota
+	// Errno fails the syscall with EPERM.
+	Errno
+	// Kill kills the process.
+	Kill
+	// Trap sends a SIGSYS to the process.
+	Trap
+)
+
+// Rule is the action taken for the syscall with the given name.
+type Rule struct {
+	Name   string
+	Action Action
+}
+
+// Filter is a seccomp filter, applying DefaultAction to the syscalls which
+// have no rule.
+type Filter struct {
+	DefaultAction Action
+	Rules         []*Rule
+}
+
+// Profile is the JSON format of the seccomp profiles given with
+// --security-opt seccomp=profile.json:
+//
+//	{
+//		"defaultAction": "SCMP_ACT_ALLOW",
+//		"syscalls": [
+//			{"name": "mount", "action": "SCMP_ACT_ERRNO"}
+//		]
+//	}
+type Profile struct {
+	DefaultAction string     `json:"defaultAction"`
+	Syscalls      []*Syscall `json:"syscalls"`
+}
+
+// Syscall is the action of a profile for one syscall. Filtering on the
+// arguments of the syscalls is not supported.
+type Syscall struct {
+	Name   string        `json:"name"`
+	Action string        `json:"action"`
+	Args   []interface{} `json:"args,omitempty"`
+}
+
+var actions = map[string]Action{
+	"SCMP_ACT_ALLOW": Allow,
+	"SCMP_ACT_ERRNO": Errno,
+	"SCMP_ACT_KILL":  Kill,
+	"SCMP_ACT_TRAP":  Trap,
+}
+
+func parseAction(action string) (Action, error) {
+	a, exists := actions[action]
+	if !exists {
+		return 0, fmt.Errorf("Invalid seccomp action %q", action)
+	}
+	return a, nil
+}
+
+// LoadProfile returns the seccomp filter of the JSON profile in body.
+func LoadProfile(body string) (*Filter, error) {
+	var profile Profile
+	if err := json.Unmarshal([]byte(body), &profile); err != nil {
+		return nil, fmt.Errorf("Invalid seccomp profile: %s", err)
+	}
+	if profile.DefaultAction == "" {
+		return nil, fmt.Errorf("Invalid seccomp profile: no defaultAction")
+	}
+	defaultAction, err := parseAction(profile.DefaultAction)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &Filter{DefaultAction: defaultAction}
+	for _, s := range profile.Syscalls {
+		if s == nil || s.Name == "" {
+			return nil, fmt.Errorf("Invalid seccomp profile: syscall without a name")
+		}
+		if len(s.Args) > 0 {
+			return nil, fmt.Errorf("Invalid seccomp profile: filtering the arguments of %s is not supported", s.Name)
+		}
+		action, err := parseAction(s.Action)
+		if err != nil {
+			return nil, err
+		}
+		filter.Rules = append(filter.Rules, &Rule{
+			Name:   s.Name,
+			Action: action,
+		})
+	}
+	return filter, nil
+}
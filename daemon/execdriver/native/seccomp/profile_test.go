@@ -0,0 +1,102 @@
+package seccomp
+
+import (
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	filter, err := LoadProfile(`{
+		"defaultAction": "SCMP_ACT_ERRNO",
+		"syscalls": [
+			{"name": "read", "action": "SCMP_ACT_ALLOW"},
+			{"name": "mount", "action": "SCMP_ACT_KILL"},
+			{"name": "reboot", "action": "SCMP_ACT_TRAP"}
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.DefaultAction != Errno {
+		t.Fatalf("Expected the default action to be Errno, got %d", filter.DefaultAction)
+	}
+	expected := []Rule{
+		{Name: "read", Action: Allow},
+		{Name: "mount", Action: Kill},
+		{Name: "reboot", Action: Trap},
+	}
+	if len(filter.Rules) != len(expected) {
+		t.Fatalf("Expected %d syscalls, got %d", len(expected), len(filter.Rules))
+	}
+	for i, s := range filter.Rules {
+		if *s != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected[i], *s)
+		}
+	}
+
+	filter, err = LoadProfile(`{"defaultAction": "SCMP_ACT_ALLOW"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.DefaultAction != Allow || len(filter.Rules) != 0 {
+		t.Fatalf("Expected an empty profile allowing everything, got %v", filter)
+	}
+}
+
+func TestLoadInvalidProfile(t *testing.T) {
+	invalid := []string{
+		``,
+		`not json`,
+		`{}`,
+		`{"defaultAction": "SCMP_ACT_DENY"}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"action": "SCMP_ACT_ERRNO"}]}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount"}]}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount", "action": "errno"}]}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mount", "action": "SCMP_ACT_ERRNO", "args": [{"index": 0}]}]}`,
+	}
+	for _, body := range invalid {
+		if _, err := LoadProfile(body); err == nil {
+			t.Fatalf("Expected an error loading %q", body)
+		}
+	}
+}
+
+func blockedRules(filter *Filter) map[string]bool {
+	blocked := make(map[string]bool)
+	for _, s := range filter.Rules {
+		if s.Action != Errno {
+			continue
+		}
+		blocked[s.Name] = true
+	}
+	return blocked
+}
+
+func TestDefaultProfile(t *testing.T) {
+	filter := DefaultProfile([]string{"CHOWN", "KILL"})
+	if filter.DefaultAction != Allow {
+		t.Fatalf("Expected the default action to be Allow, got %d", filter.DefaultAction)
+	}
+	blocked := blockedRules(filter)
+	for _, name := range []string{"kexec_load", "mount", "umount2", "init_module", "settimeofday", "ptrace"} {
+		if !blocked[name] {
+			t.Fatalf("Expected %s to be blocked", name)
+		}
+	}
+	if blocked["read"] || blocked["chown"] {
+		t.Fatalf("Expected read and chown to be allowed, got %v", blocked)
+	}
+}
+
+func TestDefaultProfileCapabilities(t *testing.T) {
+	blocked := blockedRules(DefaultProfile([]string{"sys_time", "SYS_ADMIN"}))
+	for _, name := range []string{"settimeofday", "clock_settime", "mount", "unshare"} {
+		if blocked[name] {
+			t.Fatalf("Expected %s to be allowed with its capability", name)
+		}
+	}
+	for _, name := range []string{"kexec_load", "init_module", "keyctl"} {
+		if !blocked[name] {
+			t.Fatalf("Expected %s to be blocked", name)
+		}
+	}
+}
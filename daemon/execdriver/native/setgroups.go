@@ -0,0 +1,11 @@
+// +build linux,cgo,go1.5
+
+package native
+
+import "syscall"
+
+// enableSetgroups keeps setgroups allowed in the user namespace, the init sets
+// the supplementary groups of the user of the container.
+func enableSetgroups(attr *syscall.SysProcAttr) {
+	attr.GidMappingsEnableSetgroups = true
+}
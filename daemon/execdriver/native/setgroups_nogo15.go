@@ -0,0 +1,10 @@
+// +build linux,cgo,!go1.5
+
+package native
+
+import "syscall"
+
+// enableSetgroups does nothing, os/exec leaves setgroups allowed in the user
+// namespace before go 1.5.
+func enableSetgroups(attr *syscall.SysProcAttr) {
+}
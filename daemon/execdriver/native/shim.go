@@ -0,0 +1,261 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/term"
+	"github.com/docker/libcontainer"
+	consolepkg "github.com/docker/libcontainer/console"
+	"github.com/docker/libcontainer/namespaces"
+)
+
+// The shim runs the init of a container in place of the daemon, so that the
+// container keeps running while the daemon is restarted. It talks to the
+// daemon through the FIFOs below, created in the directory of the container.
+const (
+	shimCommandName = "native-shim"
+
+	shimStdin   = "stdin"
+	shimStdout  = "stdout"
+	shimStderr  = "stderr"
+	shimControl = "control" // resize requests, "resize <height> <width>"
+	shimExit    = "exit"    // held open by the shim until it exits
+
+	shimExitStatus  = "exitStatus"    // exit code of the container
+	shimStdinClosed = "stdin-closed"  // created by the daemon to close stdin
+	shimIDMappings  = "mappings.json" // user namespace mappings, null without one
+)
+
+// shimStatus is sent by the shim to the daemon once the container started
+// or failed to start.
+type shimStatus struct {
+	Pid   int    `json:"pid"`
+	Error string `json:"error,omitempty"`
+}
+
+func init() {
+	reexec.Register(shimCommandName, shim)
+}
+
+func shim() {
+	var (
+		root     = flag.String("root", ".", "root path for configuration files")
+		initPath = flag.String("init", "", "path of the init of the container")
+		tty      = flag.Bool("tty", false, "allocate a tty")
+		stdin    = flag.Bool("stdin", false, "keep stdin open")
+	)
+	flag.Parse()
+
+	syscall.CloseOnExec(3)
+	status := os.NewFile(3, "status")
+
+	// the daemon sees the shim exit once this is closed, after the exit
+	// code of the container is written
+	exit, err := os.OpenFile(filepath.Join(*root, shimExit), os.O_RDWR, 0)
+	if err != nil {
+		json.NewEncoder(status).Encode(shimStatus{Error: err.Error()})
+		os.Exit(1)
+	}
+	defer exit.Close()
+
+	exitCode, err := runShim(*root, *initPath, *tty, *stdin, flag.Args(), func(pid int) {
+		json.NewEncoder(status).Encode(shimStatus{Pid: pid})
+		status.Close()
+	})
+	if err != nil {
+		// reported to the daemon if the container did not start yet
+		json.NewEncoder(status).Encode(shimStatus{Error: err.Error()})
+		os.Exit(1)
+	}
+
+	tmp := filepath.Join(*root, shimExitStatus+".tmp")
+	if err := ioutil.WriteFile(tmp, []byte(strconv.Itoa(exitCode)), 0600); err != nil {
+		os.Exit(1)
+	}
+	if err := os.Rename(tmp, filepath.Join(*root, shimExitStatus)); err != nil {
+		os.Exit(1)
+	}
+}
+
+func runShim(root, initPath string, tty, openStdin bool, args []string, startCallback func(int)) (int, error) {
+	f, err := os.Open(filepath.Join(root, "container.json"))
+	if err != nil {
+		return -1, err
+	}
+	var container *libcontainer.Config
+	err = json.NewDecoder(f).Decode(&container)
+	f.Close()
+	if err != nil {
+		return -1, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(root, shimIDMappings))
+	if err != nil {
+		return -1, err
+	}
+	var mappings *idMappings
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return -1, err
+	}
+
+	// the FIFOs are opened for reading too, so that the output is kept
+	// while no daemon reads it
+	stdout, err := os.OpenFile(filepath.Join(root, shimStdout), os.O_RDWR, 0)
+	if err != nil {
+		return -1, err
+	}
+	defer stdout.Close()
+	stderr, err := os.OpenFile(filepath.Join(root, shimStderr), os.O_RDWR, 0)
+	if err != nil {
+		return -1, err
+	}
+	defer stderr.Close()
+
+	var (
+		console         string
+		master          *os.File
+		containerStdin  io.Reader
+		containerStdout io.Writer = stdout
+		containerStderr io.Writer = stderr
+		outputDone      = make(chan struct{})
+	)
+	if tty {
+		if master, console, err = consolepkg.CreateMasterAndConsole(); err != nil {
+			return -1, err
+		}
+		defer master.Close()
+		if err := setupConsole(console, mappings); err != nil {
+			return -1, err
+		}
+		// the init uses the console for its stdio
+		containerStdout, containerStderr = nil, nil
+		go func() {
+			io.Copy(stdout, master)
+			close(outputDone)
+		}()
+		if openStdin {
+			go copyShimStdin(root, master, nil)
+		}
+	} else {
+		close(outputDone)
+		if openStdin {
+			r, w, err := os.Pipe()
+			if err != nil {
+				return -1, err
+			}
+			defer r.Close()
+			containerStdin = r
+			go copyShimStdin(root, w, w)
+		}
+	}
+	go readShimControl(root, master)
+
+	configFile, err := os.Open(filepath.Join(root, "container.json"))
+	if err != nil {
+		return -1, err
+	}
+	defer configFile.Close()
+
+	cmd := &exec.Cmd{}
+	exitCode, err := namespaces.Exec(container, containerStdin, containerStdout, containerStderr, console, root, args, func(container *libcontainer.Config, console, dataPath, init string, child *os.File, args []string) *exec.Cmd {
+		setupInitCommand(cmd, initPath, dataPath, container, mappings, console, child, configFile, args)
+		return cmd
+	}, func() {
+		startCallback(cmd.Process.Pid)
+	})
+	if err != nil {
+		return -1, err
+	}
+	// the output of the tty is copied until its last process exits
+	<-outputDone
+	return exitCode, nil
+}
+
+// copyShimStdin copies the stdin FIFO to the stdin of the container. The
+// FIFO is opened again when the daemon goes away, until the daemon asks for
+// stdin to be closed.
+func copyShimStdin(root string, w io.Writer, closer io.Closer) {
+	for {
+		// blocks until a daemon opens the FIFO
+		f, err := os.OpenFile(filepath.Join(root, shimStdin), os.O_RDONLY, 0)
+		if err != nil {
+			break
+		}
+		_, err = io.Copy(w, f)
+		f.Close()
+		if err != nil {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(root, shimStdinClosed)); err == nil {
+			break
+		}
+	}
+	if closer != nil {
+		closer.Close()
+	}
+}
+
+// readShimControl handles the requests of the daemon sent on the control
+// FIFO.
+func readShimControl(root string, master *os.File) {
+	for {
+		f, err := os.OpenFile(filepath.Join(root, shimControl), os.O_RDONLY, 0)
+		if err != nil {
+			return
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			var height, width uint16
+			if _, err := fmt.Sscanf(s.Text(), "resize %d %d", &height, &width); err != nil || master == nil {
+				continue
+			}
+			term.SetWinsize(master.Fd(), &term.Winsize{Height: height, Width: width})
+		}
+		f.Close()
+	}
+}
+
+// createShimFifos creates the FIFOs of the shim in the directory of the
+// container.
+func createShimFifos(root string) error {
+	for _, name := range []string{shimStdin, shimStdout, shimStderr, shimControl, shimExit} {
+		p := filepath.Join(root, name)
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := syscall.Mkfifo(p, 0600); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{shimExitStatus, shimStdinClosed} {
+		if err := os.Remove(filepath.Join(root, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// openFifo opens a FIFO without waiting for the other end to be opened.
+func openFifo(path string, flag int) (*os.File, error) {
+	fd, err := syscall.Open(path, flag|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
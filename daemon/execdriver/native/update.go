@@ -0,0 +1,175 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/systemd"
+)
+
+// Update rewrites the memory, cpu and cpuset cgroups of the running
+// container with the resources of the command and saves them to the
+// container's configuration.
+func (d *driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	if c.Resources == nil {
+		return nil
+	}
+	if systemd.UseSystemd() {
+		return fmt.Errorf("updating the resources of a running container is not supported with systemd cgroups")
+	}
+
+	current := active.container.Cgroups
+	cg := *current
+	cg.CpuShares = c.Resources.CpuShares
+	cg.Memory = c.Resources.Memory
+	cg.MemoryReservation = c.Resources.Memory
+	cg.MemorySwap = c.Resources.MemorySwap
+	cg.CpusetCpus = c.Resources.Cpuset
+
+	// the cpuset cgroup is only joined when the container starts with a cpuset
+	if cg.CpusetCpus != "" && current.CpusetCpus == "" {
+		return fmt.Errorf("cannot set a cpuset on a container started without one")
+	}
+
+	if err := updateMemory(&cg); err != nil {
+		return err
+	}
+	if err := updateCpu(&cg); err != nil {
+		return err
+	}
+	if current.CpusetCpus != "" {
+		if err := updateCpuset(&cg); err != nil {
+			return err
+		}
+	}
+
+	*active.container.Cgroups = cg
+	return d.writeContainerFile(active.container, c.ID)
+}
+
+// cgroupPath returns the directory of the container's cgroup in the
+// hierarchy of subsystem, where libcontainer placed it at start.
+func cgroupPath(c *cgroups.Cgroup, subsystem string) (string, error) {
+	mountpoint, err := cgroups.FindCgroupMountpoint(subsystem)
+	if err != nil {
+		return "", err
+	}
+
+	cgroup := c.Name
+	if c.Parent != "" {
+		cgroup = filepath.Join(c.Parent, cgroup)
+	}
+	// absolute cgroups are not relative to the cgroup of the daemon
+	if filepath.IsAbs(cgroup) {
+		return filepath.Join(mountpoint, cgroup), nil
+	}
+	initPath, err := cgroups.GetInitCgroupDir(subsystem)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mountpoint, initPath, cgroup), nil
+}
+
+func updateMemory(c *cgroups.Cgroup) error {
+	dir, err := cgroupPath(c, "memory")
+	if err != nil {
+		if c.Memory == 0 && cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// 0 means unlimited, which the kernel expects as -1
+	limit, swap := int64(-1), int64(-1)
+	if c.Memory != 0 {
+		limit = c.Memory
+		// By default, MemorySwap is set to twice the size of RAM.
+		swap = c.Memory * 2
+	}
+	// the swap limit is only available with swap accounting
+	writeSwap := c.MemorySwap != -1
+	if _, err := readCgroupUint(dir, "memory.memsw.limit_in_bytes"); err != nil {
+		writeSwap = false
+	}
+
+	// memory.limit_in_bytes can never exceed memory.memsw.limit_in_bytes,
+	// raise the swap limit first when the memory limit is raised
+	current, err := readCgroupUint(dir, "memory.limit_in_bytes")
+	if err != nil {
+		return err
+	}
+	swapFirst := limit == -1 || uint64(limit) > current
+	if writeSwap && swapFirst {
+		if err := writeCgroupFile(dir, "memory.memsw.limit_in_bytes", strconv.FormatInt(swap, 10)); err != nil {
+			return err
+		}
+	}
+	if err := writeCgroupFile(dir, "memory.limit_in_bytes", strconv.FormatInt(limit, 10)); err != nil {
+		return err
+	}
+	if writeSwap && !swapFirst {
+		if err := writeCgroupFile(dir, "memory.memsw.limit_in_bytes", strconv.FormatInt(swap, 10)); err != nil {
+			return err
+		}
+	}
+	return writeCgroupFile(dir, "memory.soft_limit_in_bytes", strconv.FormatInt(limit, 10))
+}
+
+func updateCpu(c *cgroups.Cgroup) error {
+	dir, err := cgroupPath(c, "cpu")
+	if err != nil {
+		return err
+	}
+	shares := c.CpuShares
+	if shares == 0 {
+		// the kernel's default weight
+		shares = 1024
+	}
+	return writeCgroupFile(dir, "cpu.shares", strconv.FormatInt(shares, 10))
+}
+
+func updateCpuset(c *cgroups.Cgroup) error {
+	dir, err := cgroupPath(c, "cpuset")
+	if err != nil {
+		return err
+	}
+	cpus := c.CpusetCpus
+	if cpus == "" {
+		// give the container back all the cpus of its parent
+		data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(dir), "cpuset.cpus"))
+		if err != nil {
+			return err
+		}
+		cpus = strings.TrimSpace(string(data))
+	}
+	return writeCgroupFile(dir, "cpuset.cpus", cpus)
+}
+
+func readCgroupUint(dir, file string) (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func writeCgroupFile(dir, file, data string) error {
+	if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(data), 0700); err != nil {
+		return fmt.Errorf("failed to write %s to %s: %v", data, file, err)
+	}
+	return nil
+}
@@ -77,6 +77,7 @@ var (
 	// Slice of drivers that should be used in an order
 	priority = []string{
 		"aufs",
+		"overlay",
 		"btrfs",
 		"devicemapper",
 		"vfs",
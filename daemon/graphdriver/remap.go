@@ -0,0 +1,142 @@
+package graphdriver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// remappedDriver wraps a Driver for user namespaces. The files of the layers
+// are owned by the users of the host the users of the containers are mapped
+// to, while the archives of the layers keep the users of the containers.
+type remappedDriver struct {
+	Driver
+	home             string
+	uidMaps, gidMaps []idtools.IDMap
+	rootUID, rootGID int
+}
+
+// NewRemappedDriver returns a driver storing the layers of driver, whose
+// data is under home, with the users and groups of the containers mapped to
+// the ones of the host by uidMaps and gidMaps.
+func NewRemappedDriver(driver Driver, home string, uidMaps, gidMaps []idtools.IDMap) (Driver, error) {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return nil, err
+	}
+	return &remappedDriver{
+		Driver:  driver,
+		home:    home,
+		uidMaps: uidMaps,
+		gidMaps: gidMaps,
+		rootUID: rootUID,
+		rootGID: rootGID,
+	}, nil
+}
+
+// Create creates the layer with its root directory owned by the root of the
+// containers, instead of the root of the host.
+func (d *remappedDriver) Create(id, parent string) error {
+	if err := d.Driver.Create(id, parent); err != nil {
+		return err
+	}
+	dir, err := d.Driver.Get(id, "")
+	if err != nil {
+		return err
+	}
+	defer d.Driver.Put(id)
+
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if st := fi.Sys().(*syscall.Stat_t); st.Uid != 0 || st.Gid != 0 {
+		return nil
+	}
+	return os.Lchown(dir, d.rootUID, d.rootGID)
+}
+
+// Get returns the mountpoint of the layer, making sure the root of the
+// containers can reach it.
+func (d *remappedDriver) Get(id, mountLabel string) (string, error) {
+	dir, err := d.Driver.Get(id, mountLabel)
+	if err != nil {
+		return "", err
+	}
+	// home is only accessible to the root of the containers, so the
+	// directories under it can be searchable by anyone
+	for p := filepath.Dir(dir); p != d.home && strings.HasPrefix(p, d.home+"/"); p = filepath.Dir(p) {
+		fi, err := os.Stat(p)
+		if err != nil {
+			d.Driver.Put(id)
+			return "", err
+		}
+		if fi.Mode()&0001 == 0 {
+			if err := os.Chmod(p, fi.Mode().Perm()|0001); err != nil {
+				d.Driver.Put(id)
+				return "", err
+			}
+		}
+	}
+	return dir, nil
+}
+
+// Diff produces the archive of the changes of the layer with the users of
+// the containers.
+func (d *remappedDriver) Diff(id, parent string) (archive.Archive, error) {
+	arch, err := d.Driver.Diff(id, parent)
+	if err != nil {
+		return nil, err
+	}
+	remapped, err := archive.ChownArchive(arch, d.toContainer)
+	if err != nil {
+		arch.Close()
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(remapped, func() error {
+		remapped.Close()
+		return arch.Close()
+	}), nil
+}
+
+// ApplyDiff extracts the archive of changes into the layer with the users
+// of the host.
+func (d *remappedDriver) ApplyDiff(id, parent string, diff archive.ArchiveReader) (int64, error) {
+	remapped, err := archive.ChownArchive(diff, d.toHost)
+	if err != nil {
+		return 0, err
+	}
+	defer remapped.Close()
+	return d.Driver.ApplyDiff(id, parent, remapped)
+}
+
+func (d *remappedDriver) toHost(uid, gid int) (int, int, error) {
+	hostUID, err := idtools.ToHost(uid, d.uidMaps)
+	if err != nil {
+		return -1, -1, err
+	}
+	hostGID, err := idtools.ToHost(gid, d.gidMaps)
+	if err != nil {
+		return -1, -1, err
+	}
+	return hostUID, hostGID, nil
+}
+
+// toContainer maps the files owned by users of the host outside of the
+// mappings, created by the daemon, to root.
+func (d *remappedDriver) toContainer(uid, gid int) (int, int, error) {
+	contUID, err := idtools.ToContainer(uid, d.uidMaps)
+	if err != nil {
+		contUID = 0
+	}
+	contGID, err := idtools.ToContainer(gid, d.gidMaps)
+	if err != nil {
+		contGID = 0
+	}
+	return contUID, contGID, nil
+}
@@ -1,8 +1,18 @@
 package vfs
 
 import (
-	"github.com/docker/docker/daemon/graphdriver/graphtest"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/graphtest"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
 )
 
 // This avoids creating a new driver for each test if all tests are run
@@ -26,3 +36,72 @@ func TestVfsCreateSnap(t *testing.T) {
 func TestVfsTeardown(t *testing.T) {
 	graphtest.PutDriver(t)
 }
+
+func TestVfsRemapped(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Remapping the owners of the layers requires root")
+	}
+	root, err := ioutil.TempDir("", "vfs-remapped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	driver, err := Init(filepath.Join(root, "vfs"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	d, err := graphdriver.NewRemappedDriver(driver, root, idMaps, idMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Create("base", ""); err != nil {
+		t.Fatal(err)
+	}
+	layer, err := d.Get("base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Put("base")
+	if fi, err := os.Stat(filepath.Join(root, "vfs", "dir")); err != nil || fi.Mode()&0001 == 0 {
+		t.Fatalf("Expected the parents of the layer to be searchable, got %v, %v", fi.Mode(), err)
+	}
+
+	diff, err := archive.Generate("file", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ApplyDiff("base", "", diff); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{layer, filepath.Join(layer, "file")} {
+		var st syscall.Stat_t
+		if err := syscall.Lstat(p, &st); err != nil {
+			t.Fatal(err)
+		}
+		if st.Uid != 100000 || st.Gid != 100000 {
+			t.Fatalf("Expected %s to be owned by 100000:100000, got %d:%d", p, st.Uid, st.Gid)
+		}
+	}
+
+	arch, err := d.Diff("base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arch.Close()
+	tr := tar.NewReader(arch)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != 0 || hdr.Gid != 0 {
+			t.Fatalf("Expected %s to be owned by root in the diff, got %d:%d", hdr.Name, hdr.Uid, hdr.Gid)
+		}
+	}
+}
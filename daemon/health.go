@@ -0,0 +1,272 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/execdriver/lxc"
+	"github.com/docker/docker/pkg/broadcastwriter"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/utils"
+)
+
+// Health statuses of a container with a health check
+const (
+	HealthStarting  = "starting"  // the check did not succeed yet
+	HealthHealthy   = "healthy"   // the last check succeeded
+	HealthUnhealthy = "unhealthy" // the check failed Retries times in a row
+)
+
+const (
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 30 * time.Second
+	defaultHealthRetries  = 3
+
+	// number of results kept in the log of the health status
+	maxHealthLogEntries = 5
+	// maximum number of bytes of output kept for each result
+	maxHealthOutputSize = 4096
+)
+
+// Health is the health status of a container as reported by its health
+// check.
+type Health struct {
+	Status        string
+	FailingStreak int // number of consecutive failed checks
+	Log           []*HealthcheckResult
+
+	stop chan struct{} // closed to stop the checks
+}
+
+// HealthcheckResult is the result of running the health check once.
+type HealthcheckResult struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	Output   string
+}
+
+// healthOutput collects the output of a check, up to maxHealthOutputSize
+// bytes.
+type healthOutput struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *healthOutput) Write(p []byte) (int, error) {
+	o.Lock()
+	defer o.Unlock()
+	if left := maxHealthOutputSize - o.buf.Len(); left > 0 {
+		if len(p) > left {
+			o.buf.Write(p[:left])
+		} else {
+			o.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (o *healthOutput) String() string {
+	o.Lock()
+	defer o.Unlock()
+	return o.buf.String()
+}
+
+// healthCmd returns the command to execute for the check, nil if the check
+// is disabled.
+func healthCmd(config *runconfig.HealthConfig) []string {
+	if config == nil || len(config.Test) < 2 {
+		return nil
+	}
+	switch config.Test[0] {
+	case "CMD":
+		return config.Test[1:]
+	case "CMD-SHELL":
+		return []string{"/bin/sh", "-c", strings.Join(config.Test[1:], " ")}
+	}
+	return nil
+}
+
+// initHealthMonitor starts running the health check of the container, if it
+// has one. The container lock must be held.
+func (container *Container) initHealthMonitor() {
+	container.stopHealthMonitorLocked()
+
+	if healthCmd(container.Config.Healthcheck) == nil {
+		container.State.Health = nil
+		return
+	}
+	if strings.HasPrefix(container.daemon.execDriver.Name(), lxc.DriverName) {
+		log.Warnf("Health check of container %s ignored: %s", container.ID, lxc.ErrExec)
+		container.State.Health = nil
+		return
+	}
+	if container.daemon.uidMaps != nil {
+		log.Warnf("Health check of container %s ignored: exec is not supported in containers with a user namespace", container.ID)
+		container.State.Health = nil
+		return
+	}
+
+	h := &Health{
+		Status: HealthStarting,
+		stop:   make(chan struct{}),
+	}
+	container.State.Health = h
+	go container.monitorHealth(container.Config.Healthcheck, h.stop)
+}
+
+// stopHealthMonitor stops the health check of the container, the last
+// status is kept.
+func (container *Container) stopHealthMonitor() {
+	container.Lock()
+	container.stopHealthMonitorLocked()
+	container.Unlock()
+}
+
+func (container *Container) stopHealthMonitorLocked() {
+	if h := container.State.Health; h != nil && h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
+}
+
+func (container *Container) monitorHealth(config *runconfig.HealthConfig, stop chan struct{}) {
+	interval := config.Interval
+	if interval == 0 {
+		interval = defaultHealthInterval
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+		result := container.daemon.runHealthCheck(container, config)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		container.handleHealthResult(result, config, stop)
+	}
+}
+
+// runHealthCheck executes the check in the container and returns its
+// result. A check running longer than the timeout is killed and counts as a
+// failure.
+func (daemon *Daemon) runHealthCheck(container *Container, config *runconfig.HealthConfig) *HealthcheckResult {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	entrypoint, args := daemon.getEntrypointAndArgs(nil, healthCmd(config))
+	execConfig := &execConfig{
+		ID:         utils.GenerateRandomID(),
+		OpenStdout: true,
+		OpenStderr: true,
+		ProcessConfig: execdriver.ProcessConfig{
+			Entrypoint: entrypoint,
+			Arguments:  args,
+		},
+		Container: container,
+	}
+	output := &healthOutput{}
+	execConfig.StreamConfig.stdout = broadcastwriter.New()
+	execConfig.StreamConfig.stdout.AddWriter(ioutils.NopWriteCloser(output), "")
+	execConfig.StreamConfig.stderr = broadcastwriter.New()
+	execConfig.StreamConfig.stderr.AddWriter(ioutils.NopWriteCloser(output), "")
+	execConfig.StreamConfig.stdinPipe = ioutils.NopWriteCloser(ioutil.Discard)
+
+	// the check is registered like any exec so it is visible and cleaned up
+	// with the container
+	daemon.registerExecCommand(execConfig)
+	defer daemon.unregisterExecCommand(execConfig)
+
+	var (
+		result = &HealthcheckResult{Start: time.Now().UTC()}
+		pidc   = make(chan int, 1)
+		done   = make(chan error, 1)
+	)
+	go func() {
+		done <- container.monitorExec(execConfig, func(_ *execdriver.ProcessConfig, pid int) {
+			pidc <- pid
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			result.ExitCode = -1
+			result.Output = fmt.Sprintf("Cannot run health check: %s", err)
+			break
+		}
+		execConfig.Lock()
+		result.ExitCode = execConfig.ExitCode
+		execConfig.Unlock()
+		result.Output = output.String()
+	case <-time.After(timeout):
+		select {
+		case pid := <-pidc:
+			if p, err := os.FindProcess(pid); err == nil {
+				p.Kill()
+			}
+		default:
+		}
+		result.ExitCode = -1
+		result.Output = fmt.Sprintf("Health check exceeded timeout (%s)", timeout)
+	}
+	result.End = time.Now().UTC()
+	return result
+}
+
+// handleHealthResult records the result of a check in the health status of
+// the container and logs an event when the status changes.
+func (container *Container) handleHealthResult(result *HealthcheckResult, config *runconfig.HealthConfig, stop chan struct{}) {
+	retries := config.Retries
+	if retries == 0 {
+		retries = defaultHealthRetries
+	}
+
+	container.Lock()
+	h := container.State.Health
+	if h == nil || h.stop != stop {
+		// the checks were stopped while this one was running
+		container.Unlock()
+		return
+	}
+
+	h.Log = append(h.Log, result)
+	if len(h.Log) > maxHealthLogEntries {
+		h.Log = h.Log[len(h.Log)-maxHealthLogEntries:]
+	}
+
+	oldStatus := h.Status
+	if result.ExitCode == 0 {
+		h.FailingStreak = 0
+		h.Status = HealthHealthy
+	} else {
+		h.FailingStreak++
+		if h.FailingStreak >= retries {
+			h.Status = HealthUnhealthy
+		}
+	}
+	status := h.Status
+
+	if err := container.toDisk(); err != nil {
+		log.Errorf("Error saving health status of %s: %s", container.ID, err)
+	}
+	container.Unlock()
+
+	if status != oldStatus {
+		container.LogEvent("health_status: " + status)
+	}
+}
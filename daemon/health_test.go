@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/runconfig"
+)
+
+func TestHealthCmd(t *testing.T) {
+	cases := []struct {
+		test     []string
+		expected []string
+	}{
+		{nil, nil},
+		{[]string{"NONE"}, nil},
+		{[]string{"CMD"}, nil},
+		{[]string{"CMD", "/bin/check", "-q"}, []string{"/bin/check", "-q"}},
+		{[]string{"CMD-SHELL", "exit 1"}, []string{"/bin/sh", "-c", "exit 1"}},
+		{[]string{"UNKNOWN", "exit 1"}, nil},
+	}
+	for _, c := range cases {
+		cmd := healthCmd(&runconfig.HealthConfig{Test: c.test})
+		if strings.Join(cmd, " ") != strings.Join(c.expected, " ") || len(cmd) != len(c.expected) {
+			t.Fatalf("healthCmd(%v) returned %v, expected %v", c.test, cmd, c.expected)
+		}
+	}
+	if cmd := healthCmd(nil); cmd != nil {
+		t.Fatalf("healthCmd(nil) returned %v, expected nil", cmd)
+	}
+}
+
+func TestHealthOutputLimit(t *testing.T) {
+	output := &healthOutput{}
+	data := []byte(strings.Repeat("a", maxHealthOutputSize-1) + "bc")
+	if n, err := output.Write(data); err != nil || n != len(data) {
+		t.Fatalf("Write returned %d, %v, expected %d, nil", n, err, len(data))
+	}
+	output.Write([]byte("d"))
+	if s := output.String(); len(s) != maxHealthOutputSize || !strings.HasSuffix(s, "ab") {
+		t.Fatalf("Output not truncated to %d bytes, got %d bytes", maxHealthOutputSize, len(s))
+	}
+}
+
+func TestStateStringHealth(t *testing.T) {
+	s := NewState()
+	s.SetRunning(42)
+	if str := s.String(); strings.Contains(str, "(") {
+		t.Fatalf("Unexpected health status in %q", str)
+	}
+	s.Health = &Health{Status: HealthUnhealthy}
+	if str := s.String(); !strings.HasSuffix(str, "(unhealthy)") {
+		t.Fatalf("Expected health status in %q", str)
+	}
+}
@@ -12,6 +12,25 @@ import (
 	"github.com/docker/docker/pkg/parsers/filters"
 )
 
+// acceptedPsFilters are the filters understood by the "containers" job.
+var acceptedPsFilters = map[string]bool{
+	"ancestor": true,
+	"exited":   true,
+	"id":       true,
+	"label":    true,
+	"name":     true,
+	"status":   true,
+}
+
+// acceptedPsStatuses are the values accepted by the "status" filter, as
+// returned by State.StateString.
+var acceptedPsStatuses = map[string]bool{
+	"restarting": true,
+	"running":    true,
+	"paused":     true,
+	"exited":     true,
+}
+
 // List returns an array of all containers registered in the daemon.
 func (daemon *Daemon) List() []*Container {
 	return daemon.containers.List()
@@ -28,6 +47,7 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 		size        = job.GetenvBool("size")
 		psFilters   filters.Args
 		filt_exited []int
+		ancestors   map[string]bool
 	)
 	outs := engine.NewTable("Created", 0)
 
@@ -35,6 +55,9 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 	if err != nil {
 		return job.Error(err)
 	}
+	if err := psFilters.Validate(acceptedPsFilters); err != nil {
+		return job.Error(err)
+	}
 	if i, ok := psFilters["exited"]; ok {
 		for _, value := range i {
 			code, err := strconv.Atoi(value)
@@ -44,6 +67,21 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 			filt_exited = append(filt_exited, code)
 		}
 	}
+	for _, value := range psFilters["status"] {
+		if !acceptedPsStatuses[value] {
+			return job.Errorf("Unrecognised filter value for status: %s", value)
+		}
+	}
+	if values, ok := psFilters["ancestor"]; ok {
+		ancestors = make(map[string]bool, len(values))
+		for _, value := range values {
+			img, err := daemon.Repositories().LookupImage(value)
+			if err != nil {
+				return job.Errorf("No such image: %s", value)
+			}
+			ancestors[img.ID] = true
+		}
+	}
 
 	names := map[string][]string{}
 	daemon.ContainerGraph().Walk("/", func(p string, e *graphdb.Entity) error {
@@ -78,7 +116,15 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 			return nil
 		}
 
-		if !psFilters.Match("id", container.ID) {
+		if !psFilters.MatchPrefix("id", container.ID) {
+			return nil
+		}
+
+		if !psFilters.MatchKVList("label", container.Config.Labels) {
+			return nil
+		}
+
+		if ancestors != nil && !daemon.hasAncestor(container, ancestors) {
 			return nil
 		}
 
@@ -162,3 +208,19 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// hasAncestor returns true if the image of the container, or one of its
+// parent images, is in the set of image IDs.
+func (daemon *Daemon) hasAncestor(container *Container, images map[string]bool) bool {
+	for id := container.Image; id != ""; {
+		if images[id] {
+			return true
+		}
+		img, err := daemon.graph.Get(id)
+		if err != nil {
+			return false
+		}
+		id = img.Parent
+	}
+	return false
+}
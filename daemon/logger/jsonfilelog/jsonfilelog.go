@@ -0,0 +1,188 @@
+package jsonfilelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/jsonlog"
+	"github.com/docker/docker/pkg/units"
+)
+
+const (
+	Name = "json-file"
+)
+
+// JSONFileLogger is Logger implementation for default docker logging:
+// JSON objects to file
+type JSONFileLogger struct {
+	buf      *bytes.Buffer
+	f        *os.File   // store for closing
+	mu       sync.Mutex // protects buffer and file rotation
+	path     string
+	capacity int64 // maximum size of each file, -1 means unlimited
+	n        int   // maximum number of files
+	size     int64 // size of the current file
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// New creates new JSONFileLogger which writes to filename
+func New(ctx logger.Context) (logger.Logger, error) {
+	capacity, maxFiles, err := parseLogOpt(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(ctx.LogPath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := log.Stat()
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	return &JSONFileLogger{
+		f:        log,
+		buf:      bytes.NewBuffer(nil),
+		path:     ctx.LogPath,
+		capacity: capacity,
+		n:        maxFiles,
+		size:     fi.Size(),
+	}, nil
+}
+
+// Log converts logger.Message to jsonlog.JSONLog and serializes it to file
+func (l *JSONFileLogger) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := (&jsonlog.JSONLog{Log: string(msg.Line) + "\n", Stream: msg.Source, Created: msg.Timestamp}).MarshalJSONBuf(l.buf)
+	if err != nil {
+		return err
+	}
+	l.buf.WriteByte('\n')
+	if l.capacity != -1 && l.size > 0 && l.size+int64(l.buf.Len()) > l.capacity {
+		if err := l.rotate(); err != nil {
+			l.buf.Reset()
+			return err
+		}
+	}
+	n, err := l.buf.WriteTo(l.f)
+	l.size += n
+	return err
+}
+
+// rotate shifts every existing log file one position, dropping the oldest
+// one, and reopens an empty file at the original path.
+func (l *JSONFileLogger) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	if l.n > 1 {
+		for i := l.n - 1; i > 1; i-- {
+			toPath := RotatedPath(l.path, i)
+			fromPath := RotatedPath(l.path, i-1)
+			if err := os.Rename(fromPath, toPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(l.path, RotatedPath(l.path, 1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.size = 0
+	return nil
+}
+
+// RotatedPath returns the path of the i-th rotated file of the log at path.
+func RotatedPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// LogFiles returns the existing log files for the log at path, the oldest
+// rotated file first and the live one last.
+func LogFiles(path string) []string {
+	var rotated []string
+	for i := 1; ; i++ {
+		p := RotatedPath(path, i)
+		if _, err := os.Stat(p); err != nil {
+			break
+		}
+		rotated = append(rotated, p)
+	}
+	files := make([]string, 0, len(rotated)+1)
+	for i := len(rotated) - 1; i >= 0; i-- {
+		files = append(files, rotated[i])
+	}
+	return append(files, path)
+}
+
+// ValidateLogOpt looks for json specific log options max-file & max-size.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "max-file":
+		case "max-size":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	_, _, err := parseLogOpt(cfg)
+	return err
+}
+
+func parseLogOpt(cfg map[string]string) (int64, int, error) {
+	var capacity int64 = -1
+	if capacityStr, ok := cfg["max-size"]; ok {
+		var err error
+		capacity, err = units.FromHumanSize(capacityStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid max-size %q: %v", capacityStr, err)
+		}
+		if capacity <= 0 {
+			return 0, 0, fmt.Errorf("max-size must be a positive size")
+		}
+	}
+	maxFiles := 1
+	if maxFileString, ok := cfg["max-file"]; ok {
+		var err error
+		maxFiles, err = strconv.Atoi(maxFileString)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid max-file %q: %v", maxFileString, err)
+		}
+		if maxFiles < 1 {
+			return 0, 0, fmt.Errorf("max-file cannot be less than 1")
+		}
+		if capacity == -1 {
+			return 0, 0, fmt.Errorf("max-file can only be used together with max-size")
+		}
+	}
+	return capacity, maxFiles, nil
+}
+
+// Close closes underlying file
+func (l *JSONFileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// Name returns name of this logger
+func (l *JSONFileLogger) Name() string {
+	return Name
+}
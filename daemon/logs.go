@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/jsonlog"
 	"github.com/docker/docker/pkg/tailfile"
@@ -44,8 +45,14 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	if container == nil {
 		return job.Errorf("No such container: %s", name)
 	}
-	cLog, err := container.ReadLog("json")
-	if err != nil && os.IsNotExist(err) {
+	if container.getLogConfig().Type != jsonfilelog.Name {
+		return job.Errorf("\"logs\" endpoint is supported only for \"%s\" logging driver", jsonfilelog.Name)
+	}
+	logPath, err := container.logPath("json")
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := os.Stat(logPath); err != nil && os.IsNotExist(err) {
 		// Legacy logs
 		log.Debugf("Old logs format")
 		if stdout {
@@ -76,9 +83,12 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 			}
 		}
 		if lines != 0 {
+			// the json-file driver may have rotated the log, read the
+			// rotated files first so that the output stays in order
+			var cLog io.Reader
+			files := jsonfilelog.LogFiles(logPath)
 			if lines > 0 {
-				f := cLog.(*os.File)
-				ls, err := tailfile.TailFile(f, lines)
+				ls, err := tailLogFiles(files, lines)
 				if err != nil {
 					return job.Error(err)
 				}
@@ -87,6 +97,21 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 					fmt.Fprintf(tmp, "%s\n", l)
 				}
 				cLog = tmp
+			} else {
+				readers := make([]io.Reader, 0, len(files))
+				for _, pth := range files {
+					f, err := os.Open(pth)
+					if err != nil {
+						if os.IsNotExist(err) {
+							// rotated away while we were listing the files
+							continue
+						}
+						return job.Error(err)
+					}
+					defer f.Close()
+					readers = append(readers, f)
+				}
+				cLog = io.MultiReader(readers...)
 			}
 			dec := json.NewDecoder(cLog)
 			l := &jsonlog.JSONLog{}
@@ -146,3 +171,25 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// tailLogFiles returns the last n lines of the given log files, which are
+// ordered from the oldest to the newest.
+func tailLogFiles(files []string, n int) ([][]byte, error) {
+	var lines [][]byte
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		f, err := os.Open(files[i])
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		ls, err := tailfile.TailFile(f, n-len(lines))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(ls, lines...)
+	}
+	return lines, nil
+}
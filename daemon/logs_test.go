@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTailLogFiles(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// three files of three lines each, the oldest first
+	var files []string
+	for i := 0; i < 3; i++ {
+		pth := filepath.Join(tmp, fmt.Sprintf("log.%d", 2-i))
+		content := fmt.Sprintf("%d-a\n%d-b\n%d-c\n", i, i, i)
+		if err := ioutil.WriteFile(pth, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, pth)
+	}
+	// a file that was rotated away must be skipped
+	files = append([]string{filepath.Join(tmp, "log.3")}, files...)
+
+	for n, expected := range map[int]string{
+		1:  "2-c",
+		3:  "2-a 2-b 2-c",
+		5:  "1-b 1-c 2-a 2-b 2-c",
+		9:  "0-a 0-b 0-c 1-a 1-b 1-c 2-a 2-b 2-c",
+		20: "0-a 0-b 0-c 1-a 1-b 1-c 2-a 2-b 2-c",
+	} {
+		lines, err := tailLogFiles(files, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, l := range lines {
+			got = append(got, string(l))
+		}
+		if strings.Join(got, " ") != expected {
+			t.Fatalf("tail %d: expected %q, got %q", n, expected, strings.Join(got, " "))
+		}
+	}
+}
@@ -49,6 +49,10 @@ type containerMonitor struct {
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// restore is set when the first run of the monitor reattaches to the process
+	// left running by a previous daemon, instead of starting it
+	restore bool
 }
 
 // newContainerMonitor returns an initialized containerMonitor for the provided container
@@ -100,7 +104,7 @@ func (m *containerMonitor) Close() error {
 func (m *containerMonitor) Start() error {
 	var (
 		err        error
-		exitStatus int
+		exitStatus execdriver.ExitStatus
 		// this variable indicates where we in execution flow:
 		// before Run or after
 		afterRun bool
@@ -110,19 +114,28 @@ func (m *containerMonitor) Start() error {
 	defer func() {
 		if afterRun {
 			m.container.Lock()
-			m.container.setStopped(exitStatus)
-			defer m.container.Unlock()
+			m.container.setStopped(&exitStatus)
 		}
 		m.Close()
+		if afterRun {
+			m.container.Unlock()
+		}
+		// the container is stopped so no new subscriber can register, the
+		// container lock must be released as the collector takes it
+		m.container.daemon.statsCollector.stopCollection(m.container)
 	}()
 
-	// reset the restart count
-	m.container.RestartCount = -1
+	// reset the restart count, a restored container keeps its own
+	if m.restore {
+		m.container.RestartCount--
+	} else {
+		m.container.RestartCount = -1
+	}
 
 	for {
 		m.container.RestartCount++
 
-		if err := m.container.startLoggingToDisk(); err != nil {
+		if err := m.container.startLogging(); err != nil {
 			m.resetContainer(false)
 
 			return err
@@ -130,14 +143,24 @@ func (m *containerMonitor) Start() error {
 
 		pipes := execdriver.NewPipes(m.container.stdin, m.container.stdout, m.container.stderr, m.container.Config.OpenStdin)
 
-		m.container.LogEvent("start")
+		restore := m.restore
+		m.restore = false
+
+		if restore {
+			m.lastStartTime = m.container.StartedAt
 
-		m.lastStartTime = time.Now()
+			exitStatus, err = m.container.daemon.Restore(m.container, pipes, m.restoreCallback)
+		} else {
+			m.container.LogEvent("start")
 
-		if exitStatus, err = m.container.daemon.Run(m.container, pipes, m.callback); err != nil {
+			m.lastStartTime = time.Now()
+
+			exitStatus, err = m.container.daemon.Run(m.container, pipes, m.callback)
+		}
+		if err != nil {
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
-			if m.container.RestartCount == 0 {
+			if m.container.RestartCount == 0 || restore {
 				m.resetContainer(false)
 
 				return err
@@ -149,10 +172,16 @@ func (m *containerMonitor) Start() error {
 		// here container.Lock is already lost
 		afterRun = true
 
-		m.resetMonitor(err == nil && exitStatus == 0)
+		m.container.stopHealthMonitor()
+
+		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
+
+		if exitStatus.OOMKilled {
+			m.container.LogEvent("oom")
+		}
 
-		if m.shouldRestart(exitStatus) {
-			m.container.SetRestarting(exitStatus)
+		if m.shouldRestart(exitStatus.ExitCode) {
+			m.container.SetRestarting(&exitStatus)
 			m.container.LogEvent("die")
 			m.resetContainer(true)
 
@@ -206,7 +235,7 @@ func (m *containerMonitor) waitForNextRestart() {
 
 // shouldRestart checks the restart policy and applies the rules to determine if
 // the container's process should be restarted
-func (m *containerMonitor) shouldRestart(exitStatus int) bool {
+func (m *containerMonitor) shouldRestart(exitCode int) bool {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
@@ -225,7 +254,7 @@ func (m *containerMonitor) shouldRestart(exitStatus int) bool {
 			return false
 		}
 
-		return exitStatus != 0
+		return exitCode != 0
 	}
 
 	return false
@@ -244,7 +273,30 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 	}
 
 	m.container.setRunning(pid)
+	m.container.connectEndpoints()
+	if m.container.RestartCount > 0 {
+		// restarts happen in the monitor's goroutine, without the lock
+		// container.Start holds during the first start
+		m.container.Lock()
+		m.container.initHealthMonitor()
+		m.container.Unlock()
+	} else {
+		m.container.initHealthMonitor()
+	}
+
+	m.signalStart()
+}
+
+// restoreCallback updates the container's state once the daemon reattached to its
+// process, whose network interfaces are already set up
+func (m *containerMonitor) restoreCallback(processConfig *execdriver.ProcessConfig, pid int) {
+	m.container.setRestored(pid)
+	m.container.initHealthMonitor()
 
+	m.signalStart()
+}
+
+func (m *containerMonitor) signalStart() {
 	// signal that the process has started
 	// close channel only if not closed
 	select {
@@ -282,6 +334,17 @@ func (m *containerMonitor) resetContainer(lock bool) {
 		log.Errorf("%s: Error close stderr: %s", container.ID, err)
 	}
 
+	if container.logDriver != nil {
+		if container.logCopier != nil {
+			container.logCopier.Wait()
+		}
+		if err := container.logDriver.Close(); err != nil {
+			log.Errorf("%s: Error closing logger: %s", container.ID, err)
+		}
+		container.logCopier = nil
+		container.logDriver = nil
+	}
+
 	if container.command != nil && container.command.ProcessConfig.Terminal != nil {
 		if err := container.command.ProcessConfig.Terminal.Close(); err != nil {
 			log.Errorf("%s: Error closing terminal: %s", container.ID, err)
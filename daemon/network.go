@@ -0,0 +1,583 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/networkfs/etchosts"
+	"github.com/docker/docker/utils"
+)
+
+var validNetworkNamePattern = regexp.MustCompile(`^` + validContainerNameChars + `+$`)
+
+// predefinedNetworks are the networks of the network modes which exist on
+// every daemon, they are listed with the user-defined ones and cannot be
+// removed.
+var predefinedNetworks = map[string]string{
+	"bridge": "bridge",
+	"host":   "host",
+	"none":   "null",
+}
+
+// Network is a network containers are connected to, either one of the
+// predefined networks or one created with docker network create.
+type Network struct {
+	ID      string
+	Name    string
+	Driver  string
+	Subnet  string
+	Gateway string
+	IPRange string
+}
+
+// IsPredefined returns whether the network is one of the predefined networks.
+func (n *Network) IsPredefined() bool {
+	_, ok := predefinedNetworks[n.Name]
+	return ok
+}
+
+// driverID returns the id of the network in the bridge driver, an empty
+// string for the default bridge.
+func (n *Network) driverID() (string, error) {
+	switch n.Name {
+	case "bridge":
+		return "", nil
+	case "host", "none":
+		return "", fmt.Errorf("Containers cannot be connected to the %s network", n.Name)
+	}
+	return n.ID, nil
+}
+
+// networkStore keeps the networks of the daemon, each one is saved in a
+// file of its root directory.
+type networkStore struct {
+	root string
+	s    map[string]*Network
+	sync.Mutex
+}
+
+func newNetworkStore(root string) (*networkStore, error) {
+	if err := os.MkdirAll(root, 0700); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+	store := &networkStore{
+		root: root,
+		s:    make(map[string]*Network),
+	}
+	dir, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range dir {
+		data, err := ioutil.ReadFile(path.Join(root, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		n := &Network{}
+		if err := json.Unmarshal(data, n); err != nil {
+			log.Errorf("Failed to load network %s: %s", fi.Name(), err)
+			continue
+		}
+		store.s[n.ID] = n
+	}
+
+	// the predefined networks are saved with the other ones the first time
+	// the daemon starts to keep their ids
+	for name, driver := range predefinedNetworks {
+		if store.Get(name) != nil {
+			continue
+		}
+		if err := store.Add(&Network{
+			ID:     utils.GenerateRandomID(),
+			Name:   name,
+			Driver: driver,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Get returns the network with the given name, id or unique id prefix, nil
+// if there is none.
+func (store *networkStore) Get(name string) *Network {
+	store.Lock()
+	defer store.Unlock()
+	if name == "" {
+		return nil
+	}
+	if n, ok := store.s[name]; ok {
+		return n
+	}
+	var matches []*Network
+	for _, n := range store.s {
+		if n.Name == name {
+			return n
+		}
+		if strings.HasPrefix(n.ID, name) {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) != 1 {
+		return nil
+	}
+	return matches[0]
+}
+
+// List returns the networks sorted by name.
+func (store *networkStore) List() []*Network {
+	store.Lock()
+	defer store.Unlock()
+	networks := make([]*Network, 0, len(store.s))
+	for _, n := range store.s {
+		networks = append(networks, n)
+	}
+	sort.Sort(networksByName(networks))
+	return networks
+}
+
+func (store *networkStore) Add(n *Network) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(store.root, n.ID), data, 0600); err != nil {
+		return err
+	}
+	store.Lock()
+	store.s[n.ID] = n
+	store.Unlock()
+	return nil
+}
+
+func (store *networkStore) Delete(id string) error {
+	if err := os.Remove(path.Join(store.root, id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	store.Lock()
+	delete(store.s, id)
+	store.Unlock()
+	return nil
+}
+
+type networksByName []*Network
+
+func (r networksByName) Len() int           { return len(r) }
+func (r networksByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r networksByName) Less(i, j int) bool { return r[i].Name < r[j].Name }
+
+// restoreNetworks sets up again the bridges of the user-defined networks
+// when the daemon starts.
+func (daemon *Daemon) restoreNetworks() {
+	if daemon.config.DisableNetwork {
+		return
+	}
+	for _, n := range daemon.networks.List() {
+		if n.IsPredefined() {
+			continue
+		}
+		job := daemon.eng.Job("create_network", n.ID)
+		job.Setenv("Subnet", n.Subnet)
+		job.Setenv("Gateway", n.Gateway)
+		job.Setenv("IPRange", n.IPRange)
+		if err := job.Run(); err != nil {
+			log.Errorf("Failed to restore network %s: %s", n.Name, err)
+		}
+	}
+}
+
+func (daemon *Daemon) NetworkCreate(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	var (
+		name   = job.Args[0]
+		driver = job.Getenv("Driver")
+	)
+	if !validNetworkNamePattern.MatchString(name) {
+		return job.Errorf("Invalid network name (%s), only %s are allowed", name, validContainerNameChars)
+	}
+	if existing := daemon.networks.Get(name); name == "container" || existing != nil && existing.Name == name {
+		return job.Errorf("Conflict, a network named %s already exists", name)
+	}
+	if driver == "" {
+		driver = "bridge"
+	}
+	if driver != "bridge" {
+		return job.Errorf("Unsupported network driver: %s", driver)
+	}
+	if daemon.config.DisableNetwork {
+		return job.Errorf("Networking is disabled on the daemon")
+	}
+
+	n := &Network{
+		ID:      utils.GenerateRandomID(),
+		Name:    name,
+		Driver:  driver,
+		IPRange: job.Getenv("IPRange"),
+	}
+
+	createJob := daemon.eng.Job("create_network", n.ID)
+	createJob.Setenv("Subnet", job.Getenv("Subnet"))
+	createJob.Setenv("Gateway", job.Getenv("Gateway"))
+	createJob.Setenv("IPRange", n.IPRange)
+	env, err := createJob.Stdout.AddEnv()
+	if err != nil {
+		return job.Error(err)
+	}
+	if err := createJob.Run(); err != nil {
+		return job.Error(err)
+	}
+	n.Subnet = env.Get("Subnet")
+	n.Gateway = env.Get("Gateway")
+
+	if err := daemon.networks.Add(n); err != nil {
+		daemon.eng.Job("delete_network", n.ID).Run()
+		return job.Error(err)
+	}
+	job.Printf("%s\n", n.ID)
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) Networks(job *engine.Job) engine.Status {
+	outs := engine.NewTable("", 0)
+	for _, n := range daemon.networks.List() {
+		out := &engine.Env{}
+		out.Set("Id", n.ID)
+		out.Set("Name", n.Name)
+		out.Set("Driver", n.Driver)
+		out.Set("Subnet", n.Subnet)
+		out.Set("Gateway", n.Gateway)
+		outs.Add(out)
+	}
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) NetworkRm(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NETWORK", job.Name)
+	}
+	n := daemon.networks.Get(job.Args[0])
+	if n == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	if n.IsPredefined() {
+		return job.Errorf("%s is a predefined network and cannot be removed", n.Name)
+	}
+	var active []string
+	for _, c := range daemon.networkContainers(n, nil) {
+		active = append(active, c.Name[1:])
+	}
+	if len(active) > 0 {
+		return job.Errorf("Conflict, network %s has running containers: %s", n.Name, strings.Join(active, ", "))
+	}
+
+	if !daemon.config.DisableNetwork {
+		if err := daemon.eng.Job("delete_network", n.ID).Run(); err != nil {
+			return job.Error(err)
+		}
+	}
+	if err := daemon.networks.Delete(n.ID); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) NetworkConnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	n := daemon.networks.Get(job.Args[0])
+	if n == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	if _, err := n.driverID(); err != nil {
+		return job.Error(err)
+	}
+	container := daemon.Get(job.Args[1])
+	if container == nil {
+		return job.Errorf("No such container: %s", job.Args[1])
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	mode := container.hostConfig.NetworkMode
+	if !mode.IsPrivate() || container.Config.NetworkDisabled {
+		return job.Errorf("Container %s uses the network mode %s and cannot be connected to networks", container.Name[1:], mode)
+	}
+	if primary, err := container.primaryNetwork(); err == nil && primary.ID == n.ID {
+		return job.Errorf("Container %s is already connected to network %s", container.Name[1:], n.Name)
+	}
+	if _, ok := container.NetworkSettings.Networks[n.Name]; ok {
+		return job.Errorf("Container %s is already connected to network %s", container.Name[1:], n.Name)
+	}
+
+	ep := &EndpointSettings{NetworkID: n.ID}
+	if container.Running {
+		if err := container.allocateEndpoint(n, ep); err != nil {
+			return job.Error(err)
+		}
+		if err := container.daemon.execDriver.ConnectInterface(container.command, ep.networkInterface()); err != nil {
+			container.releaseEndpoint(n, ep)
+			return job.Error(err)
+		}
+		if err := container.publishOnNetwork(n, ep.IPAddress); err != nil {
+			log.Errorf("Failed to update the hosts files on network %s: %s", n.Name, err)
+		}
+	}
+	if container.NetworkSettings.Networks == nil {
+		container.NetworkSettings.Networks = make(map[string]*EndpointSettings)
+	}
+	container.NetworkSettings.Networks[n.Name] = ep
+	if err := container.toDisk(); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) NetworkDisconnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	n := daemon.networks.Get(job.Args[0])
+	if n == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	container := daemon.Get(job.Args[1])
+	if container == nil {
+		return job.Errorf("No such container: %s", job.Args[1])
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	ep, ok := container.NetworkSettings.Networks[n.Name]
+	if !ok {
+		if primary, err := container.primaryNetwork(); err == nil && primary.ID == n.ID {
+			return job.Errorf("Container %s is on network %s through its network mode and cannot be disconnected", container.Name[1:], n.Name)
+		}
+		return job.Errorf("Container %s is not connected to network %s", container.Name[1:], n.Name)
+	}
+
+	if container.Running && ep.IPAddress != "" {
+		if err := container.daemon.execDriver.DisconnectInterface(container.command, ep.networkInterface()); err != nil {
+			return job.Error(err)
+		}
+		container.unpublishFromNetwork(n)
+		container.releaseEndpoint(n, ep)
+	}
+	delete(container.NetworkSettings.Networks, n.Name)
+	if err := container.toDisk(); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// networkContainers returns the running containers on the network, either
+// through their network mode or connected to it. The container except is
+// skipped, it may be locked by the caller.
+func (daemon *Daemon) networkContainers(n *Network, except *Container) []*Container {
+	var containers []*Container
+	for _, c := range daemon.List() {
+		if c == except || !c.IsRunning() {
+			continue
+		}
+		if c.networkIP(n) != "" {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
+
+// primaryNetwork returns the network of the network mode of the container.
+func (container *Container) primaryNetwork() (*Network, error) {
+	mode := container.hostConfig.NetworkMode
+	name := string(mode)
+	if !mode.IsUserDefined() {
+		if !mode.IsPrivate() {
+			return nil, fmt.Errorf("Container %s is not on a network", container.ID)
+		}
+		name = "bridge"
+	}
+	n := container.daemon.networks.Get(name)
+	if n == nil {
+		return nil, fmt.Errorf("No such network: %s", name)
+	}
+	return n, nil
+}
+
+// primaryNetworkID returns the id in the bridge driver of the network of
+// the network mode of the container.
+func (container *Container) primaryNetworkID() (string, error) {
+	if !container.hostConfig.NetworkMode.IsUserDefined() {
+		return "", nil
+	}
+	n, err := container.primaryNetwork()
+	if err != nil {
+		return "", err
+	}
+	return n.driverID()
+}
+
+// networkIP returns the address of the container on the network, an empty
+// string if it has none.
+func (container *Container) networkIP(n *Network) string {
+	if primary, err := container.primaryNetwork(); err == nil && primary.ID == n.ID {
+		return container.NetworkSettings.IPAddress
+	}
+	if ep, ok := container.NetworkSettings.Networks[n.Name]; ok {
+		return ep.IPAddress
+	}
+	return ""
+}
+
+// allocateEndpoint allocates an interface of the container on the network.
+func (container *Container) allocateEndpoint(n *Network, ep *EndpointSettings) error {
+	id, err := n.driverID()
+	if err != nil {
+		return err
+	}
+	job := container.daemon.eng.Job("allocate_interface", container.ID)
+	job.Setenv("Network", id)
+	env, err := job.Stdout.AddEnv()
+	if err != nil {
+		return err
+	}
+	if err := job.Run(); err != nil {
+		return err
+	}
+	ep.IPAddress = env.Get("IP")
+	ep.IPPrefixLen = env.GetInt("IPPrefixLen")
+	ep.Gateway = env.Get("Gateway")
+	ep.MacAddress = env.Get("MacAddress")
+	ep.Bridge = env.Get("Bridge")
+	return nil
+}
+
+// releaseEndpoint releases the interface of the container on the network,
+// the endpoint is kept so that it is allocated again on the next start.
+func (container *Container) releaseEndpoint(n *Network, ep *EndpointSettings) {
+	if id, err := n.driverID(); err == nil {
+		job := container.daemon.eng.Job("release_interface", container.ID)
+		job.Setenv("Network", id)
+		job.Run()
+	}
+	*ep = EndpointSettings{NetworkID: ep.NetworkID}
+}
+
+// allocateEndpoints allocates the interfaces of the container on the
+// networks it was connected to.
+func (container *Container) allocateEndpoints() error {
+	for name, ep := range container.NetworkSettings.Networks {
+		n := container.daemon.networks.Get(ep.NetworkID)
+		if n == nil {
+			return fmt.Errorf("No such network: %s", name)
+		}
+		if err := container.allocateEndpoint(n, ep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connectEndpoints adds the interfaces of the networks the container was
+// connected to, once its process is started.
+func (container *Container) connectEndpoints() {
+	for name, ep := range container.NetworkSettings.Networks {
+		if ep.IPAddress == "" {
+			continue
+		}
+		if err := container.daemon.execDriver.ConnectInterface(container.command, ep.networkInterface()); err != nil {
+			log.Errorf("Failed to connect container %s to network %s: %s", container.ID, name, err)
+		}
+	}
+}
+
+// publishNetworks makes the container resolvable by name on its networks.
+func (container *Container) publishNetworks() error {
+	if n, err := container.primaryNetwork(); err == nil {
+		if err := container.publishOnNetwork(n, container.NetworkSettings.IPAddress); err != nil {
+			return err
+		}
+	}
+	for _, ep := range container.NetworkSettings.Networks {
+		if n := container.daemon.networks.Get(ep.NetworkID); n != nil {
+			if err := container.publishOnNetwork(n, ep.IPAddress); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unpublishNetworks removes the container from the hosts files of the
+// containers on its networks.
+func (container *Container) unpublishNetworks() {
+	if n, err := container.primaryNetwork(); err == nil {
+		container.unpublishFromNetwork(n)
+	}
+	for _, ep := range container.NetworkSettings.Networks {
+		if n := container.daemon.networks.Get(ep.NetworkID); n != nil {
+			container.unpublishFromNetwork(n)
+		}
+	}
+}
+
+// publishOnNetwork adds the other containers of a user-defined network to
+// the hosts file of the container and the container to theirs. The default
+// bridge relies on links instead.
+func (container *Container) publishOnNetwork(n *Network, ip string) error {
+	if n.IsPredefined() || ip == "" || container.HostsPath == "" {
+		return nil
+	}
+	for _, c := range container.daemon.networkContainers(n, container) {
+		if c.HostsPath == "" {
+			continue
+		}
+		if err := etchosts.Add(container.HostsPath, c.networkIP(n), c.Name[1:]); err != nil {
+			return err
+		}
+		if err := etchosts.Add(c.HostsPath, ip, container.Name[1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (container *Container) unpublishFromNetwork(n *Network) {
+	if n.IsPredefined() {
+		return
+	}
+	for _, c := range container.daemon.networkContainers(n, container) {
+		if c.HostsPath == "" {
+			continue
+		}
+		if err := etchosts.Delete(c.HostsPath, container.Name[1:]); err != nil {
+			log.Debugf("Failed to update the hosts file of %s: %s", c.ID, err)
+		}
+	}
+}
+
+func (ep *EndpointSettings) networkInterface() *execdriver.NetworkInterface {
+	return &execdriver.NetworkInterface{
+		Gateway:     ep.Gateway,
+		Bridge:      ep.Bridge,
+		IPAddress:   ep.IPAddress,
+		IPPrefixLen: ep.IPPrefixLen,
+		MacAddress:  ep.MacAddress,
+	}
+}
@@ -9,13 +9,28 @@ import (
 type PortMapping map[string]string // Deprecated
 
 type NetworkSettings struct {
+	IPAddress           string
+	IPPrefixLen         int
+	MacAddress          string
+	Gateway             string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	IPv6Gateway         string
+	Bridge              string
+	PortMapping         map[string]PortMapping // Deprecated
+	Ports               nat.PortMap
+	Networks            map[string]*EndpointSettings // networks connected with docker network connect, by name
+}
+
+// EndpointSettings are the settings of the interface of a container on a
+// network it was connected to, in addition to the one of its network mode.
+type EndpointSettings struct {
+	NetworkID   string
 	IPAddress   string
 	IPPrefixLen int
-	MacAddress  string
 	Gateway     string
+	MacAddress  string
 	Bridge      string
-	PortMapping map[string]PortMapping // Deprecated
-	Ports       nat.PortMap
 }
 
 func (settings *NetworkSettings) PortMappingAPI() *engine.Table {
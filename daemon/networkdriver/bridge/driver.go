@@ -24,11 +24,16 @@ import (
 const (
 	DefaultNetworkBridge     = "docker0"
 	MaxAllocatedPortAttempts = 10
+	// link-local address set on the bridge when IPv6 is enabled, used as
+	// the IPv6 gateway of the containers
+	bridgeIPv6 = "fe80::1/64"
 )
 
 // Network interface represents the networking stack of a container
 type networkInterface struct {
 	IP           net.IP
+	IPv6         net.IP     // global IPv6 address, nil if none was allocated
+	Network      string     // id of the user-defined network, empty for the default bridge
 	PortMappings []net.Addr // there are mappings to the host interfaces
 }
 
@@ -50,6 +55,76 @@ func (i *ifaces) Get(key string) *networkInterface {
 	return res
 }
 
+func (i *ifaces) Delete(key string) {
+	i.Lock()
+	delete(i.c, key)
+	i.Unlock()
+}
+
+// inNetwork returns whether an interface is allocated on the network.
+func (i *ifaces) inNetwork(network string) bool {
+	i.Lock()
+	defer i.Unlock()
+	for _, n := range i.c {
+		if n.Network == network {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceKey returns the key of the interface of the container on the
+// network in currentInterfaces.
+func interfaceKey(id, network string) string {
+	if network == "" {
+		return id
+	}
+	return id + "/" + network
+}
+
+// userNetwork is a network created with create_network, it has its own
+// bridge and subnet.
+type userNetwork struct {
+	ID      string
+	Iface   string
+	Subnet  *net.IPNet
+	Gateway net.IP
+}
+
+type userNetworks struct {
+	c map[string]*userNetwork
+	sync.Mutex
+}
+
+func (n *userNetworks) Set(id string, network *userNetwork) {
+	n.Lock()
+	n.c[id] = network
+	n.Unlock()
+}
+
+func (n *userNetworks) Get(id string) *userNetwork {
+	n.Lock()
+	res := n.c[id]
+	n.Unlock()
+	return res
+}
+
+func (n *userNetworks) Delete(id string) {
+	n.Lock()
+	delete(n.c, id)
+	n.Unlock()
+}
+
+func (n *userNetworks) List() []*userNetwork {
+	n.Lock()
+	defer n.Unlock()
+	res := make([]*userNetwork, 0, len(n.c))
+	for _, network := range n.c {
+		res = append(res, network)
+	}
+	return res
+}
+
 var (
 	addrs = []string{
 		// Here we don't follow the convention of using the 1st IP of the range for the gateway.
@@ -74,8 +149,17 @@ var (
 	bridgeIface   string
 	bridgeNetwork *net.IPNet
 
+	// the subnet the global IPv6 addresses of the containers are allocated
+	// from, nil if they only have a link-local address
+	globalIPv6Network *net.IPNet
+	ipv6Enabled       bool
+
+	iptablesEnabled bool
+	ipMasqEnabled   bool
+
 	defaultBindingIP  = net.ParseIP("0.0.0.0")
 	currentInterfaces = ifaces{c: make(map[string]*networkInterface)}
+	networks          = userNetworks{c: make(map[string]*userNetwork)}
 )
 
 func InitDriver(job *engine.Job) engine.Status {
@@ -87,8 +171,14 @@ func InitDriver(job *engine.Job) engine.Status {
 		ipForward      = job.GetenvBool("EnableIpForward")
 		bridgeIP       = job.Getenv("BridgeIP")
 		fixedCIDR      = job.Getenv("FixedCIDR")
+		enableIPv6     = job.GetenvBool("EnableIPv6")
+		fixedCIDRv6    = job.Getenv("FixedCIDRv6")
 	)
 
+	if fixedCIDRv6 != "" && !enableIPv6 {
+		return job.Errorf("--fixed-cidr-v6 requires --ipv6")
+	}
+
 	if defaultIP := job.Getenv("DefaultBindingIP"); defaultIP != "" {
 		defaultBindingIP = net.ParseIP(defaultIP)
 	}
@@ -130,11 +220,31 @@ func InitDriver(job *engine.Job) engine.Status {
 		}
 	}
 
+	var ipv6Network *net.IPNet
+	if enableIPv6 {
+		if fixedCIDRv6 != "" {
+			if _, ipv6Network, err = net.ParseCIDR(fixedCIDRv6); err != nil {
+				return job.Error(err)
+			}
+			if ipv6Network.IP.To4() != nil {
+				return job.Errorf("--fixed-cidr-v6 must be an IPv6 subnet: %s", fixedCIDRv6)
+			}
+		}
+		if err := configureBridgeIPv6(ipv6Network); err != nil {
+			return job.Error(err)
+		}
+	}
+
 	// Configure iptables for link support
 	if enableIPTables {
 		if err := setupIPTables(addr, icc, ipMasq); err != nil {
 			return job.Error(err)
 		}
+		if enableIPv6 {
+			if err := setupIP6Tables(icc); err != nil {
+				return job.Error(err)
+			}
+		}
 	}
 
 	if ipForward {
@@ -142,6 +252,12 @@ func InitDriver(job *engine.Job) engine.Status {
 		if err := ioutil.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte{'1', '\n'}, 0644); err != nil {
 			job.Logf("WARNING: unable to enable IPv4 forwarding: %s\n", err)
 		}
+		if enableIPv6 {
+			// Enable IPv6 forwarding
+			if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/all/forwarding", []byte{'1', '\n'}, 0644); err != nil {
+				job.Logf("WARNING: unable to enable IPv6 forwarding: %s\n", err)
+			}
+		}
 	}
 
 	// We can always try removing the iptables
@@ -169,6 +285,11 @@ func InitDriver(job *engine.Job) engine.Status {
 		}
 	}
 
+	ipv6Enabled = enableIPv6
+	globalIPv6Network = ipv6Network
+	iptablesEnabled = enableIPTables
+	ipMasqEnabled = ipMasq
+
 	// https://github.com/docker/docker/issues/2768
 	job.Eng.Hack_SetGlobalVar("httpapi.bridgeIP", bridgeNetwork.IP)
 
@@ -177,6 +298,8 @@ func InitDriver(job *engine.Job) engine.Status {
 		"release_interface":  Release,
 		"allocate_port":      AllocatePort,
 		"link":               LinkContainers,
+		"create_network":     CreateNetwork,
+		"delete_network":     DeleteNetwork,
 	} {
 		if err := job.Eng.Register(name, f); err != nil {
 			return job.Error(err)
@@ -200,6 +323,18 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 		}
 	}
 
+	return setupForwardRules(bridgeIface, iptables.Raw, iptables.Exists, icc)
+}
+
+// setupIP6Tables sets up the forwarding rules of the bridge for IPv6. The
+// containers have global addresses, there is no NAT.
+func setupIP6Tables(icc bool) error {
+	return setupForwardRules(bridgeIface, iptables.Raw6, iptables.Exists6, icc)
+}
+
+// setupForwardRules sets up the FORWARD rules of the bridge with the given
+// iptables or ip6tables functions.
+func setupForwardRules(bridgeIface string, raw func(...string) ([]byte, error), exists func(...string) bool, icc bool) error {
 	var (
 		args       = []string{"FORWARD", "-i", bridgeIface, "-o", bridgeIface, "-j"}
 		acceptArgs = append(args, "ACCEPT")
@@ -207,22 +342,22 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 	)
 
 	if !icc {
-		iptables.Raw(append([]string{"-D"}, acceptArgs...)...)
+		raw(append([]string{"-D"}, acceptArgs...)...)
 
-		if !iptables.Exists(dropArgs...) {
+		if !exists(dropArgs...) {
 			log.Debugf("Disable inter-container communication")
-			if output, err := iptables.Raw(append([]string{"-I"}, dropArgs...)...); err != nil {
+			if output, err := raw(append([]string{"-I"}, dropArgs...)...); err != nil {
 				return fmt.Errorf("Unable to prevent intercontainer communication: %s", err)
 			} else if len(output) != 0 {
 				return fmt.Errorf("Error disabling intercontainer communication: %s", output)
 			}
 		}
 	} else {
-		iptables.Raw(append([]string{"-D"}, dropArgs...)...)
+		raw(append([]string{"-D"}, dropArgs...)...)
 
-		if !iptables.Exists(acceptArgs...) {
+		if !exists(acceptArgs...) {
 			log.Debugf("Enable inter-container communication")
-			if output, err := iptables.Raw(append([]string{"-I"}, acceptArgs...)...); err != nil {
+			if output, err := raw(append([]string{"-I"}, acceptArgs...)...); err != nil {
 				return fmt.Errorf("Unable to allow intercontainer communication: %s", err)
 			} else if len(output) != 0 {
 				return fmt.Errorf("Error enabling intercontainer communication: %s", output)
@@ -232,8 +367,8 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 
 	// Accept all non-intercontainer outgoing packets
 	outgoingArgs := []string{"FORWARD", "-i", bridgeIface, "!", "-o", bridgeIface, "-j", "ACCEPT"}
-	if !iptables.Exists(outgoingArgs...) {
-		if output, err := iptables.Raw(append([]string{"-I"}, outgoingArgs...)...); err != nil {
+	if !exists(outgoingArgs...) {
+		if output, err := raw(append([]string{"-I"}, outgoingArgs...)...); err != nil {
 			return fmt.Errorf("Unable to allow outgoing packets: %s", err)
 		} else if len(output) != 0 {
 			return fmt.Errorf("Error iptables allow outgoing: %s", output)
@@ -243,8 +378,8 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 	// Accept incoming packets for existing connections
 	existingArgs := []string{"FORWARD", "-o", bridgeIface, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"}
 
-	if !iptables.Exists(existingArgs...) {
-		if output, err := iptables.Raw(append([]string{"-I"}, existingArgs...)...); err != nil {
+	if !exists(existingArgs...) {
+		if output, err := raw(append([]string{"-I"}, existingArgs...)...); err != nil {
 			return fmt.Errorf("Unable to allow incoming packets: %s", err)
 		} else if len(output) != 0 {
 			return fmt.Errorf("Error iptables allow incoming: %s", output)
@@ -259,16 +394,6 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 // bridge (fixes issue #8444)
 // If an address which doesn't conflict with existing interfaces can't be found, an error is returned.
 func configureBridge(bridgeIP string) error {
-	nameservers := []string{}
-	resolvConf, _ := resolvconf.Get()
-	// we don't check for an error here, because we don't really care
-	// if we can't read /etc/resolv.conf. So instead we skip the append
-	// if resolvConf is nil. It either doesn't exist, or we can't read it
-	// for some reason.
-	if resolvConf != nil {
-		nameservers = append(nameservers, resolvconf.GetNameserversAsCIDR(resolvConf)...)
-	}
-
 	var ifaceAddr string
 	if len(bridgeIP) != 0 {
 		_, _, err := net.ParseCIDR(bridgeIP)
@@ -277,19 +402,9 @@ func configureBridge(bridgeIP string) error {
 		}
 		ifaceAddr = bridgeIP
 	} else {
-		for _, addr := range addrs {
-			_, dockerNetwork, err := net.ParseCIDR(addr)
-			if err != nil {
-				return err
-			}
-			if err := networkdriver.CheckNameserverOverlaps(nameservers, dockerNetwork); err == nil {
-				if err := networkdriver.CheckRouteOverlaps(dockerNetwork); err == nil {
-					ifaceAddr = addr
-					break
-				} else {
-					log.Debugf("%s %s", addr, err)
-				}
-			}
+		var err error
+		if ifaceAddr, err = freeNetworkAddr(); err != nil {
+			return err
 		}
 	}
 
@@ -324,6 +439,79 @@ func configureBridge(bridgeIP string) error {
 	return nil
 }
 
+// freeNetworkAddr returns the first of the private ranges of addrs which
+// overlaps neither with the nameservers nor with the routes of the host, an
+// empty string if they all do.
+func freeNetworkAddr() (string, error) {
+	nameservers := []string{}
+	resolvConf, _ := resolvconf.Get()
+	// we don't check for an error here, because we don't really care
+	// if we can't read /etc/resolv.conf. So instead we skip the append
+	// if resolvConf is nil. It either doesn't exist, or we can't read it
+	// for some reason.
+	if resolvConf != nil {
+		nameservers = append(nameservers, resolvconf.GetNameserversAsCIDR(resolvConf)...)
+	}
+
+	for _, addr := range addrs {
+		_, dockerNetwork, err := net.ParseCIDR(addr)
+		if err != nil {
+			return "", err
+		}
+		if err := networkdriver.CheckNameserverOverlaps(nameservers, dockerNetwork); err == nil {
+			if err := networkdriver.CheckRouteOverlaps(dockerNetwork); err == nil {
+				return addr, nil
+			} else {
+				log.Debugf("%s %s", addr, err)
+			}
+		}
+	}
+	return "", nil
+}
+
+// configureBridgeIPv6 enables IPv6 on the bridge and sets its link-local
+// address, the gateway of the containers. If network is not nil, a route to
+// it is added through the bridge, as the global addresses of the containers
+// are allocated from it.
+func configureBridgeIPv6(network *net.IPNet) error {
+	if err := ioutil.WriteFile(fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/disable_ipv6", bridgeIface), []byte{'0', '\n'}, 0644); err != nil {
+		return fmt.Errorf("Unable to enable IPv6 on %s: %s", bridgeIface, err)
+	}
+
+	iface, err := net.InterfaceByName(bridgeIface)
+	if err != nil {
+		return err
+	}
+	ip, ipNet, err := net.ParseCIDR(bridgeIPv6)
+	if err != nil {
+		return err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, addr := range addrs {
+		if a, ok := addr.(*net.IPNet); ok && a.IP.Equal(ip) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		if err := netlink.NetworkLinkAddIp(iface, ip, ipNet); err != nil {
+			return fmt.Errorf("Unable to add IPv6 address %s to %s: %s", bridgeIPv6, bridgeIface, err)
+		}
+	}
+
+	if network != nil {
+		log.Debugf("Adding route to IPv6 network %s via %s", network, bridgeIface)
+		if err := netlink.AddRoute(network.String(), "", "", bridgeIface); err != nil && !os.IsExist(err) {
+			return fmt.Errorf("Unable to add route to IPv6 network %s via %s: %s", network, bridgeIface, err)
+		}
+	}
+	return nil
+}
+
 func createBridgeIface(name string) error {
 	kv, err := kernel.GetKernelVersion()
 	// only set the bridge's mac address if the kernel version is > 3.3
@@ -358,6 +546,20 @@ func generateMacAddr(ip net.IP) net.HardwareAddr {
 	return hw
 }
 
+// lookupNetwork returns the bridge, the network the addresses are allocated
+// from and the gateway of the network with the given id, the default bridge
+// if id is empty.
+func lookupNetwork(id string) (string, *net.IPNet, net.IP, error) {
+	if id == "" {
+		return bridgeIface, bridgeNetwork, bridgeNetwork.IP, nil
+	}
+	n := networks.Get(id)
+	if n == nil {
+		return "", nil, nil, fmt.Errorf("No such network: %s", id)
+	}
+	return n.Iface, n.Subnet, n.Gateway, nil
+}
+
 // Allocate a network interface
 func Allocate(job *engine.Job) engine.Status {
 	var (
@@ -365,13 +567,20 @@ func Allocate(job *engine.Job) engine.Status {
 		mac         net.HardwareAddr
 		err         error
 		id          = job.Args[0]
+		networkID   = job.Getenv("Network")
 		requestedIP = net.ParseIP(job.Getenv("RequestedIP"))
+		globalIPv6  net.IP
 	)
 
+	iface, network, gateway, err := lookupNetwork(networkID)
+	if err != nil {
+		return job.Error(err)
+	}
+
 	if requestedIP != nil {
-		ip, err = ipallocator.RequestIP(bridgeNetwork, requestedIP)
+		ip, err = ipallocator.RequestIP(network, requestedIP)
 	} else {
-		ip, err = ipallocator.RequestIP(bridgeNetwork, nil)
+		ip, err = ipallocator.RequestIP(network, nil)
 	}
 	if err != nil {
 		return job.Error(err)
@@ -382,18 +591,46 @@ func Allocate(job *engine.Job) engine.Status {
 		mac = generateMacAddr(ip)
 	}
 
+	// IPv6 is only configured on the default bridge
+	if globalIPv6Network != nil && networkID == "" {
+		requestedIPv6 := net.ParseIP(job.Getenv("RequestedIPv6"))
+		// derive the address from the mac address when the subnet is large
+		// enough, as the address of the IPv4 network is derived from it
+		if ones, _ := globalIPv6Network.Mask.Size(); requestedIPv6 == nil && ones <= 80 {
+			requestedIPv6 = make(net.IP, len(globalIPv6Network.IP))
+			copy(requestedIPv6, globalIPv6Network.IP)
+			copy(requestedIPv6[10:], mac)
+		}
+		if globalIPv6, err = ipallocator.RequestIP(globalIPv6Network, requestedIPv6); err != nil {
+			ipallocator.ReleaseIP(network, ip)
+			return job.Error(err)
+		}
+	}
+
 	out := engine.Env{}
 	out.Set("IP", ip.String())
-	out.Set("Mask", bridgeNetwork.Mask.String())
-	out.Set("Gateway", bridgeNetwork.IP.String())
+	out.Set("Mask", network.Mask.String())
+	out.Set("Gateway", gateway.String())
 	out.Set("MacAddress", mac.String())
-	out.Set("Bridge", bridgeIface)
+	out.Set("Bridge", iface)
 
-	size, _ := bridgeNetwork.Mask.Size()
+	size, _ := network.Mask.Size()
 	out.SetInt("IPPrefixLen", size)
 
-	currentInterfaces.Set(id, &networkInterface{
-		IP: ip,
+	if ipv6Enabled && networkID == "" {
+		gateway, _, _ := net.ParseCIDR(bridgeIPv6)
+		out.Set("IPv6Gateway", gateway.String())
+	}
+	if globalIPv6 != nil {
+		ones, _ := globalIPv6Network.Mask.Size()
+		out.Set("GlobalIPv6", globalIPv6.String())
+		out.SetInt("GlobalIPv6PrefixLen", ones)
+	}
+
+	currentInterfaces.Set(interfaceKey(id, networkID), &networkInterface{
+		IP:      ip,
+		IPv6:    globalIPv6,
+		Network: networkID,
 	})
 
 	out.WriteTo(job.Stdout)
@@ -405,12 +642,14 @@ func Allocate(job *engine.Job) engine.Status {
 func Release(job *engine.Job) engine.Status {
 	var (
 		id                 = job.Args[0]
-		containerInterface = currentInterfaces.Get(id)
+		key                = interfaceKey(id, job.Getenv("Network"))
+		containerInterface = currentInterfaces.Get(key)
 	)
 
 	if containerInterface == nil {
 		return job.Errorf("No network information to release for %s", id)
 	}
+	currentInterfaces.Delete(key)
 
 	for _, nat := range containerInterface.PortMappings {
 		if err := portmapper.Unmap(nat); err != nil {
@@ -418,9 +657,16 @@ func Release(job *engine.Job) engine.Status {
 		}
 	}
 
-	if err := ipallocator.ReleaseIP(bridgeNetwork, containerInterface.IP); err != nil {
+	if _, network, _, err := lookupNetwork(containerInterface.Network); err != nil {
+		log.Infof("Unable to release ip %s", err)
+	} else if err := ipallocator.ReleaseIP(network, containerInterface.IP); err != nil {
 		log.Infof("Unable to release ip %s", err)
 	}
+	if containerInterface.IPv6 != nil && globalIPv6Network != nil {
+		if err := ipallocator.ReleaseIP(globalIPv6Network, containerInterface.IPv6); err != nil {
+			log.Infof("Unable to release ipv6 %s", err)
+		}
+	}
 	return engine.StatusOK
 }
 
@@ -435,9 +681,13 @@ func AllocatePort(job *engine.Job) engine.Status {
 		hostPort      = job.GetenvInt("HostPort")
 		containerPort = job.GetenvInt("ContainerPort")
 		proto         = job.Getenv("Proto")
-		network       = currentInterfaces.Get(id)
+		network       = currentInterfaces.Get(interfaceKey(id, job.Getenv("Network")))
 	)
 
+	if network == nil {
+		return job.Errorf("No network interface allocated for %s", id)
+	}
+
 	if hostIP != "" {
 		ip = net.ParseIP(hostIP)
 		if ip == nil {
@@ -544,3 +794,238 @@ func LinkContainers(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// CreateNetwork sets up the bridge of a user-defined network, isolated from
+// the default bridge and the other networks. The subnet is picked from the
+// private ranges if none is given. Creating a network which already exists,
+// as the daemon does on startup, reuses its bridge.
+func CreateNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s ID", job.Name)
+	}
+	var (
+		id      = job.Args[0]
+		iface   = "br-" + id
+		subnet  = job.Getenv("Subnet")
+		gateway = net.ParseIP(job.Getenv("Gateway"))
+		ipRange = job.Getenv("IPRange")
+	)
+	if len(iface) > 15 {
+		iface = iface[:15]
+	}
+
+	if n := networks.Get(id); n != nil {
+		return job.Errorf("Network %s already exists", id)
+	}
+
+	if subnet == "" {
+		addr, err := freeNetworkAddr()
+		if err != nil {
+			return job.Error(err)
+		}
+		if addr == "" {
+			return job.Errorf("Could not find a free IP address range for network %s", id)
+		}
+		ip, _, _ := net.ParseCIDR(addr)
+		if gateway == nil {
+			gateway = ip
+		}
+		subnet = addr
+	}
+	_, network, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return job.Error(err)
+	}
+	if network.IP.To4() == nil {
+		return job.Errorf("Only IPv4 subnets are supported: %s", subnet)
+	}
+	if bridgeNetwork != nil && networkdriver.NetworkOverlaps(network, bridgeNetwork) {
+		return job.Errorf("Subnet %s overlaps with the default bridge %s", network, bridgeNetwork)
+	}
+	for _, n := range networks.List() {
+		if networkdriver.NetworkOverlaps(network, n.Subnet) {
+			return job.Errorf("Subnet %s overlaps with the subnet of network %s", network, n.ID)
+		}
+	}
+	if gateway == nil {
+		// the first address of the subnet
+		gateway = make(net.IP, len(network.IP.To4()))
+		copy(gateway, network.IP.To4())
+		gateway[len(gateway)-1]++
+	}
+	if !network.Contains(gateway) {
+		return job.Errorf("Gateway %s is not in subnet %s", gateway, network)
+	}
+
+	if ipRange != "" {
+		_, r, err := net.ParseCIDR(ipRange)
+		if err != nil {
+			return job.Error(err)
+		}
+		if err := ipallocator.RegisterSubnet(network, r); err != nil {
+			return job.Errorf("Invalid IP range %s: %s", ipRange, err)
+		}
+	}
+	// reserve the gateway, it is not allocated to any container
+	if _, err := ipallocator.RequestIP(network, gateway); err != nil && err != ipallocator.ErrIPOutOfRange {
+		ipallocator.UnregisterNetwork(network)
+		return job.Error(err)
+	}
+
+	n := &userNetwork{
+		ID:      id,
+		Iface:   iface,
+		Subnet:  network,
+		Gateway: gateway,
+	}
+	if err := setupNetworkBridge(n); err != nil {
+		ipallocator.UnregisterNetwork(network)
+		return job.Error(err)
+	}
+	if iptablesEnabled {
+		if err := setupNetworkIPTables(n); err != nil {
+			ipallocator.UnregisterNetwork(network)
+			return job.Error(err)
+		}
+	}
+	networks.Set(id, n)
+
+	size, _ := network.Mask.Size()
+	out := engine.Env{}
+	out.Set("Subnet", network.String())
+	out.Set("Gateway", gateway.String())
+	out.Set("Bridge", iface)
+	out.SetInt("IPPrefixLen", size)
+	if _, err := out.WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// DeleteNetwork removes the bridge and the iptables rules of a user-defined
+// network. It fails if interfaces are still allocated on the network.
+func DeleteNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s ID", job.Name)
+	}
+	id := job.Args[0]
+	n := networks.Get(id)
+	if n == nil {
+		return job.Errorf("No such network: %s", id)
+	}
+	if currentInterfaces.inNetwork(id) {
+		return job.Errorf("Network %s has active endpoints", id)
+	}
+
+	if iptablesEnabled {
+		deleteRules(networkRules(n))
+		for _, other := range networks.List() {
+			if other.ID != id {
+				deleteRules(isolationRules(n.Iface, other.Iface))
+			}
+		}
+		deleteRules(isolationRules(n.Iface, bridgeIface))
+	}
+
+	if iface, err := net.InterfaceByName(n.Iface); err == nil {
+		if err := netlink.NetworkLinkDown(iface); err != nil {
+			return job.Errorf("Unable to stop bridge %s: %s", n.Iface, err)
+		}
+		if err := netlink.DeleteBridge(n.Iface); err != nil {
+			return job.Errorf("Unable to delete bridge %s: %s", n.Iface, err)
+		}
+	}
+	ipallocator.UnregisterNetwork(n.Subnet)
+	networks.Delete(id)
+	return engine.StatusOK
+}
+
+// setupNetworkBridge creates the bridge of the network if it does not exist
+// and gives it the address of the gateway.
+func setupNetworkBridge(n *userNetwork) error {
+	log.Debugf("Creating bridge %s with network %s", n.Iface, n.Subnet)
+	if err := createBridgeIface(n.Iface); err != nil && !os.IsExist(err) {
+		return err
+	}
+	iface, err := net.InterfaceByName(n.Iface)
+	if err != nil {
+		return err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, addr := range addrs {
+		if a, ok := addr.(*net.IPNet); ok && a.IP.Equal(n.Gateway) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		if err := netlink.NetworkLinkAddIp(iface, n.Gateway, n.Subnet); err != nil {
+			return fmt.Errorf("Unable to add address %s to %s: %s", n.Gateway, n.Iface, err)
+		}
+	}
+	if err := netlink.NetworkLinkUp(iface); err != nil {
+		return fmt.Errorf("Unable to start bridge %s: %s", n.Iface, err)
+	}
+	return nil
+}
+
+// setupNetworkIPTables lets the traffic of the network through and isolates
+// it from the default bridge and the other networks. The isolation rules are
+// inserted last so that they are evaluated first.
+func setupNetworkIPTables(n *userNetwork) error {
+	rules := networkRules(n)
+	for _, other := range networks.List() {
+		rules = append(rules, isolationRules(n.Iface, other.Iface)...)
+	}
+	rules = append(rules, isolationRules(n.Iface, bridgeIface)...)
+	return insertRules(rules)
+}
+
+// networkRules returns the rules forwarding the traffic of the network: the
+// containers talk to each other and to the outside, the replies and the
+// connections to the published ports come back in.
+func networkRules(n *userNetwork) [][]string {
+	rules := [][]string{
+		{"FORWARD", "-i", n.Iface, "-o", n.Iface, "-j", "ACCEPT"},
+		{"FORWARD", "-i", n.Iface, "!", "-o", n.Iface, "-j", "ACCEPT"},
+		{"FORWARD", "-o", n.Iface, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
+		{"FORWARD", "-o", n.Iface, "-m", "conntrack", "--ctstate", "DNAT", "-j", "ACCEPT"},
+	}
+	if ipMasqEnabled {
+		rules = append(rules, []string{"POSTROUTING", "-t", "nat", "-s", n.Subnet.String(), "!", "-o", n.Iface, "-j", "MASQUERADE"})
+	}
+	return rules
+}
+
+// isolationRules returns the rules dropping the traffic between two bridges.
+func isolationRules(iface, other string) [][]string {
+	return [][]string{
+		{"FORWARD", "-i", iface, "-o", other, "-j", "DROP"},
+		{"FORWARD", "-i", other, "-o", iface, "-j", "DROP"},
+	}
+}
+
+func insertRules(rules [][]string) error {
+	for _, rule := range rules {
+		if iptables.Exists(rule...) {
+			continue
+		}
+		if output, err := iptables.Raw(append([]string{"-I"}, rule...)...); err != nil {
+			return fmt.Errorf("Unable to set up network rule %v: %s", rule, err)
+		} else if len(output) != 0 {
+			return fmt.Errorf("Error iptables network rule %v: %s", rule, output)
+		}
+	}
+	return nil
+}
+
+func deleteRules(rules [][]string) {
+	for _, rule := range rules {
+		// Ignore errors - the rule may have been removed already
+		iptables.Raw(append([]string{"-D"}, rule...)...)
+	}
+}
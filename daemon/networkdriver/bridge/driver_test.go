@@ -118,3 +118,115 @@ func TestMacAddrGeneration(t *testing.T) {
 		t.Fatal("Non-unique MAC address")
 	}
 }
+
+func TestAllocateGlobalIPv6(t *testing.T) {
+	eng := engine.New()
+	eng.Logging = false
+
+	_, bridgeNetwork, _ = net.ParseCIDR("172.16.42.1/24")
+	_, globalIPv6Network, _ = net.ParseCIDR("2001:db8:1::/64")
+	ipv6Enabled = true
+	defer func() {
+		globalIPv6Network = nil
+		ipv6Enabled = false
+	}()
+
+	if err := eng.Register("allocate_interface", Allocate); err != nil {
+		t.Fatal(err)
+	}
+	job := eng.Job("allocate_interface", "ipv6_container")
+	output, err := job.Stdout.AddEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Run(); err != nil {
+		t.Fatalf("Failed to allocate network interface: %s", err)
+	}
+
+	ip := net.ParseIP(output.Get("GlobalIPv6"))
+	if ip == nil || !globalIPv6Network.Contains(ip) {
+		t.Fatalf("Expected a global IPv6 address in %s, got %q", globalIPv6Network, output.Get("GlobalIPv6"))
+	}
+	mac, err := net.ParseMAC(output.Get("MacAddress"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if net.HardwareAddr(ip[10:]).String() != mac.String() {
+		t.Fatalf("Expected the IPv6 address %s to be derived from the mac address %s", ip, mac)
+	}
+	if prefixLen := output.GetInt("GlobalIPv6PrefixLen"); prefixLen != 64 {
+		t.Fatalf("Expected a prefix length of 64, got %d", prefixLen)
+	}
+	if gw := output.Get("IPv6Gateway"); gw != "fe80::1" {
+		t.Fatalf("Expected fe80::1 as IPv6 gateway, got %s", gw)
+	}
+
+	if res := Release(eng.Job("release_interface", "ipv6_container")); res != engine.StatusOK {
+		t.Fatal("Failed to release network interface")
+	}
+}
+
+func TestAllocateOnUserNetwork(t *testing.T) {
+	eng := engine.New()
+	eng.Logging = false
+
+	_, bridgeNetwork, _ = net.ParseCIDR("172.16.42.1/24")
+	_, subnet, _ := net.ParseCIDR("10.42.0.0/16")
+	networks.Set("testnet", &userNetwork{
+		ID:      "testnet",
+		Iface:   "br-testnet",
+		Subnet:  subnet,
+		Gateway: net.ParseIP("10.42.0.1"),
+	})
+	defer networks.Delete("testnet")
+
+	for name, f := range map[string]engine.Handler{
+		"allocate_interface": Allocate,
+		"release_interface":  Release,
+	} {
+		if err := eng.Register(name, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := eng.Job("allocate_interface", "user_container")
+	job.Setenv("Network", "testnet")
+	output, err := job.Stdout.AddEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Run(); err != nil {
+		t.Fatalf("Failed to allocate network interface: %s", err)
+	}
+
+	if ip := net.ParseIP(output.Get("IP")); ip == nil || !subnet.Contains(ip) {
+		t.Fatalf("Expected an address in %s, got %q", subnet, output.Get("IP"))
+	}
+	if gw := output.Get("Gateway"); gw != "10.42.0.1" {
+		t.Fatalf("Expected 10.42.0.1 as gateway, got %s", gw)
+	}
+	if bridge := output.Get("Bridge"); bridge != "br-testnet" {
+		t.Fatalf("Expected the bridge br-testnet, got %s", bridge)
+	}
+	if prefixLen := output.GetInt("IPPrefixLen"); prefixLen != 16 {
+		t.Fatalf("Expected a prefix length of 16, got %d", prefixLen)
+	}
+	if !currentInterfaces.inNetwork("testnet") {
+		t.Fatal("Expected an interface to be allocated on testnet")
+	}
+
+	job = eng.Job("release_interface", "user_container")
+	job.Setenv("Network", "testnet")
+	if err := job.Run(); err != nil {
+		t.Fatalf("Failed to release network interface: %s", err)
+	}
+	if currentInterfaces.inNetwork("testnet") {
+		t.Fatal("Expected the interface on testnet to be released")
+	}
+
+	job = eng.Job("allocate_interface", "user_container")
+	job.Setenv("Network", "unknown")
+	if err := job.Run(); err == nil {
+		t.Fatal("Expected an error allocating an interface on an unknown network")
+	}
+}
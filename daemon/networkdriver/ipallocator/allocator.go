@@ -77,6 +77,14 @@ func RegisterSubnet(network *net.IPNet, subnet *net.IPNet) error {
 	return nil
 }
 
+// UnregisterNetwork removes the network from the global allocator along with
+// all the ips allocated from it, the network can then be registered again.
+func UnregisterNetwork(network *net.IPNet) {
+	lock.Lock()
+	delete(allocatedIPs, network.String())
+	lock.Unlock()
+}
+
 // RequestIP requests an available ip from the given network.  It
 // will return the next available ip if the ip provided is nil.  If the
 // ip provided is not nil it will validate that the provided ip is available
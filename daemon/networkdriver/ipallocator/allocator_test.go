@@ -549,6 +549,38 @@ func TestRegisterBadRange(t *testing.T) {
 	}
 }
 
+func TestUnregisterNetwork(t *testing.T) {
+	defer reset()
+	network := &net.IPNet{
+		IP:   []byte{192, 168, 1, 1},
+		Mask: []byte{255, 255, 255, 0},
+	}
+	subnet := &net.IPNet{
+		IP:   []byte{192, 168, 1, 8},
+		Mask: []byte{255, 255, 255, 248},
+	}
+
+	if err := RegisterSubnet(network, subnet); err != nil {
+		t.Fatal(err)
+	}
+	ip, err := RequestIP(network, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	UnregisterNetwork(network)
+
+	if err := RegisterSubnet(network, subnet); err != nil {
+		t.Fatal(err)
+	}
+	// the ip allocated before was released with the network
+	ip2, err := RequestIP(network, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIPEquals(t, ip, ip2)
+}
+
 func TestAllocateFromRange(t *testing.T) {
 	defer reset()
 	network := &net.IPNet{
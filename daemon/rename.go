@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"github.com/docker/docker/engine"
+)
+
+func (daemon *Daemon) ContainerRename(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("usage: %s OLD_NAME NEW_NAME", job.Name)
+	}
+	oldName := job.Args[0]
+	newName := job.Args[1]
+
+	container := daemon.Get(oldName)
+	if container == nil {
+		return job.Errorf("No such container: %s", oldName)
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	oldName = container.Name
+
+	// Reserving the new name adds a second edge to the container's entity
+	// in the graph, the links of the container hang off the entity and are
+	// therefore kept.
+	newName, err := daemon.reserveName(container.ID, newName)
+	if err != nil {
+		return job.Errorf("Error when allocating new name: %s", err)
+	}
+
+	container.Name = newName
+	if err := container.toDisk(); err != nil {
+		container.Name = oldName
+		if err := daemon.containerGraph.Delete(newName); err != nil {
+			return job.Errorf("Failed to delete container %q: %v", newName, err)
+		}
+		return job.Error(err)
+	}
+
+	if err := daemon.containerGraph.Delete(oldName); err != nil {
+		return job.Errorf("Failed to delete container %q: %v", oldName, err)
+	}
+
+	container.LogEvent("rename")
+	return engine.StatusOK
+}
@@ -3,8 +3,10 @@ package daemon
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/runconfig"
 )
@@ -44,11 +46,52 @@ func (daemon *Daemon) ContainerStart(job *engine.Job) engine.Status {
 }
 
 func (daemon *Daemon) setHostConfig(container *Container, hostConfig *runconfig.HostConfig) error {
+	// Validate the logging configuration, an empty type means the
+	// daemon's default is used
+	if hostConfig.LogConfig.Type != "" {
+		if err := logger.ValidateLogOpts(hostConfig.LogConfig.Type, hostConfig.LogConfig.Config); err != nil {
+			return err
+		}
+	} else if len(hostConfig.LogConfig.Config) > 0 {
+		if err := logger.ValidateLogOpts(daemon.config.LogConfig.Type, hostConfig.LogConfig.Config); err != nil {
+			return err
+		}
+	}
+
+	if hostConfig.NetworkMode.IsUserDefined() {
+		n := daemon.networks.Get(string(hostConfig.NetworkMode))
+		if n == nil {
+			return fmt.Errorf("No such network: %s", hostConfig.NetworkMode)
+		}
+		if _, err := n.driverID(); err != nil {
+			return err
+		}
+	}
+
+	if daemon.uidMaps != nil {
+		if hostConfig.Privileged {
+			return fmt.Errorf("Privileged mode is incompatible with user namespaces")
+		}
+		if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() {
+			return fmt.Errorf("Cannot share the network namespace of the host or of another container when user namespaces are enabled")
+		}
+	}
+
+	for dest, options := range hostConfig.Tmpfs {
+		if err := runconfig.ValidateTmpfs(dest, options); err != nil {
+			return err
+		}
+	}
+
 	// Validate the HostConfig binds. Make sure that:
 	// the source exists
 	for _, bind := range hostConfig.Binds {
 		splitBind := strings.Split(bind, ":")
 		source := splitBind[0]
+		// Named volumes are not on the host
+		if !filepath.IsAbs(source) {
+			continue
+		}
 
 		// ensure the source exists on the host
 		_, err := os.Stat(source)
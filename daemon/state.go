@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/pkg/units"
 )
 
@@ -13,11 +14,13 @@ type State struct {
 	Running    bool
 	Paused     bool
 	Restarting bool
+	OOMKilled  bool
 	Pid        int
 	ExitCode   int
 	Error      string // contains last known error when starting the container
 	StartedAt  time.Time
 	FinishedAt time.Time
+	Health     *Health // nil if the container has no health check
 	waitChan   chan struct{}
 }
 
@@ -37,6 +40,9 @@ func (s *State) String() string {
 			return fmt.Sprintf("Restarting (%d) %s ago", s.ExitCode, units.HumanDuration(time.Now().UTC().Sub(s.FinishedAt)))
 		}
 
+		if s.Health != nil {
+			return fmt.Sprintf("Up %s (%s)", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)), s.Health.Status)
+		}
 		return fmt.Sprintf("Up %s", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)))
 	}
 
@@ -44,6 +50,9 @@ func (s *State) String() string {
 		return ""
 	}
 
+	if s.OOMKilled {
+		return fmt.Sprintf("Exited (%d) %s ago (OOM killed)", s.ExitCode, units.HumanDuration(time.Now().UTC().Sub(s.FinishedAt)))
+	}
 	return fmt.Sprintf("Exited (%d) %s ago", s.ExitCode, units.HumanDuration(time.Now().UTC().Sub(s.FinishedAt)))
 }
 
@@ -142,6 +151,7 @@ func (s *State) setRunning(pid int) {
 	s.Running = true
 	s.Paused = false
 	s.Restarting = false
+	s.OOMKilled = false
 	s.ExitCode = 0
 	s.Pid = pid
 	s.StartedAt = time.Now().UTC()
@@ -149,25 +159,36 @@ func (s *State) setRunning(pid int) {
 	s.waitChan = make(chan struct{})
 }
 
+// setRestored marks the state as running once the daemon reattached to the
+// process left running by a previous daemon, keeping its start time.
+func (s *State) setRestored(pid int) {
+	s.Running = true
+	s.Restarting = false
+	s.Pid = pid
+	close(s.waitChan) // fire waiters for start
+	s.waitChan = make(chan struct{})
+}
+
 func (s *State) SetStopped(exitCode int) {
 	s.Lock()
-	s.setStopped(exitCode)
+	s.setStopped(&execdriver.ExitStatus{ExitCode: exitCode})
 	s.Unlock()
 }
 
-func (s *State) setStopped(exitCode int) {
+func (s *State) setStopped(exitStatus *execdriver.ExitStatus) {
 	s.Running = false
 	s.Restarting = false
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
-	s.ExitCode = exitCode
+	s.ExitCode = exitStatus.ExitCode
+	s.OOMKilled = exitStatus.OOMKilled
 	close(s.waitChan) // fire waiters for stop
 	s.waitChan = make(chan struct{})
 }
 
 // SetRestarting is when docker hanldes the auto restart of containers when they are
 // in the middle of a stop and being restarted again
-func (s *State) SetRestarting(exitCode int) {
+func (s *State) SetRestarting(exitStatus *execdriver.ExitStatus) {
 	s.Lock()
 	// we should consider the container running when it is restarting because of
 	// all the checks in docker around rm/stop/etc
@@ -175,7 +196,8 @@ func (s *State) SetRestarting(exitCode int) {
 	s.Restarting = true
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
-	s.ExitCode = exitCode
+	s.ExitCode = exitStatus.ExitCode
+	s.OOMKilled = exitStatus.OOMKilled
 	close(s.waitChan) // fire waiters for stop
 	s.waitChan = make(chan struct{})
 	s.Unlock()
@@ -1,9 +1,12 @@
 package daemon
 
 import (
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
 )
 
 func TestStateRunStop(t *testing.T) {
@@ -73,6 +76,27 @@ func TestStateRunStop(t *testing.T) {
 	}
 }
 
+func TestStateOOMKilled(t *testing.T) {
+	s := NewState()
+	s.SetRunning(100)
+	s.setStopped(&execdriver.ExitStatus{ExitCode: 137, OOMKilled: true})
+	if !s.OOMKilled {
+		t.Fatal("State not OOM killed")
+	}
+	if status := s.String(); !strings.HasSuffix(status, "(OOM killed)") {
+		t.Fatalf("Status %q, expected the OOM kill to be reported", status)
+	}
+
+	s.SetRunning(101)
+	if s.OOMKilled {
+		t.Fatal("OOM kill not reset when the container is started")
+	}
+	s.SetStopped(0)
+	if status := s.String(); strings.Contains(status, "OOM") {
+		t.Fatalf("Status %q, expected no OOM kill to be reported", status)
+	}
+}
+
 func TestStateTimeoutWait(t *testing.T) {
 	s := NewState()
 	started := make(chan struct{})
@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/system"
+)
+
+// newStatsCollector returns a new statsCollector that collects
+// network and cgroup stats for a registered container at the specified
+// interval.  The collector allows non-running containers to be added
+// and will start processing stats when they are started.
+func newStatsCollector(interval time.Duration) *statsCollector {
+	s := &statsCollector{
+		interval:    interval,
+		subscribers: make(map[*Container]map[chan *execdriver.ResourceStats]struct{}),
+		clockTicks:  uint64(system.GetClockTicks()),
+	}
+	go s.run()
+	return s
+}
+
+// statsCollector manages and provides container resource stats
+type statsCollector struct {
+	m           sync.Mutex
+	interval    time.Duration
+	clockTicks  uint64
+	subscribers map[*Container]map[chan *execdriver.ResourceStats]struct{}
+}
+
+// collect registers the container with the collector and returns a channel
+// receiving a stats sample every interval until the subscription is removed.
+// The container must be running: the running check is done with the collector
+// locked so that a container stopping concurrently always closes the channel
+// in stopCollection.
+func (s *statsCollector) collect(c *Container) (chan *execdriver.ResourceStats, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !c.IsRunning() {
+		return nil, fmt.Errorf("Container %s is not running", c.ID)
+	}
+	subs, exists := s.subscribers[c]
+	if !exists {
+		subs = make(map[chan *execdriver.ResourceStats]struct{})
+		s.subscribers[c] = subs
+	}
+	ch := make(chan *execdriver.ResourceStats, 1)
+	subs[ch] = struct{}{}
+	return ch, nil
+}
+
+// unsubscribe removes a specific subscriber from receiving updates for a
+// container's stats.
+func (s *statsCollector) unsubscribe(c *Container, ch chan *execdriver.ResourceStats) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	subs, exists := s.subscribers[c]
+	if !exists {
+		return
+	}
+	if _, exists := subs[ch]; exists {
+		delete(subs, ch)
+		close(ch)
+	}
+	if len(subs) == 0 {
+		delete(s.subscribers, c)
+	}
+}
+
+// stopCollection closes the channels of all subscribers of the container
+// and removes it from the collector. It must be called once the container is
+// stopped and without holding the container lock, which collect takes.
+func (s *statsCollector) stopCollection(c *Container) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for ch := range s.subscribers[c] {
+		close(ch)
+	}
+	delete(s.subscribers, c)
+}
+
+func (s *statsCollector) run() {
+	for _ = range time.Tick(s.interval) {
+		s.m.Lock()
+		if len(s.subscribers) == 0 {
+			s.m.Unlock()
+			continue
+		}
+		systemUsage, err := s.getSystemCpuUsage()
+		if err != nil {
+			log.Errorf("collecting system cpu usage: %v", err)
+		}
+		for container, subs := range s.subscribers {
+			stats, err := container.Stats()
+			if err != nil {
+				if err != execdriver.ErrNotRunning {
+					log.Errorf("collecting stats for %s: %v", container.ID, err)
+				}
+				continue
+			}
+			stats.SystemUsage = systemUsage
+			for ch := range subs {
+				// never block the collector on a slow subscriber, it will
+				// simply miss this sample
+				select {
+				case ch <- stats:
+				default:
+				}
+			}
+		}
+		s.m.Unlock()
+	}
+}
+
+const nanoSeconds = 1e9
+
+// getSystemCpuUsage returns the host system's cpu usage in nanoseconds
+// for the system to match the cgroup readings are returned in the same format.
+func (s *statsCollector) getSystemCpuUsage() (uint64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.Fields(sc.Text())
+		if len(parts) == 0 || parts[0] != "cpu" {
+			continue
+		}
+		if len(parts) < 8 {
+			return 0, fmt.Errorf("invalid number of cpu fields")
+		}
+		var sum uint64
+		for _, i := range parts[1:8] {
+			v, err := strconv.ParseUint(i, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("Unable to convert value %s to int: %s", i, err)
+			}
+			sum += v
+		}
+		return (sum * nanoSeconds) / s.clockTicks, nil
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("invalid stat format")
+}
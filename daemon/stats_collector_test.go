@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsCollectorStoppedContainer(t *testing.T) {
+	s := newStatsCollector(time.Hour)
+	c := &Container{ID: "stopped", State: NewState()}
+
+	if ch, err := s.collect(c); err == nil || ch != nil {
+		t.Fatalf("Expected an error subscribing to a stopped container, got %v", err)
+	}
+	if len(s.subscribers) != 0 {
+		t.Fatalf("Expected no subscriber for a stopped container, got %d", len(s.subscribers))
+	}
+}
+
+func TestStatsCollectorStopCollection(t *testing.T) {
+	s := newStatsCollector(time.Hour)
+	c := &Container{ID: "running", State: NewState()}
+	c.SetRunning(100)
+
+	ch, err := s.collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the monitor marks the container stopped before stopping the collection
+	c.SetStopped(0)
+	s.stopCollection(c)
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("Expected the channel to be closed, got a sample")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Channel not closed when the collection stopped")
+	}
+	if _, err := s.collect(c); err == nil {
+		t.Fatal("Expected an error subscribing to a stopped container")
+	}
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/engine"
+)
+
+// ContainerUpdate changes the memory limit, cpu shares and cpuset of a
+// container. The new values are applied right away to a running container
+// and are kept for its next starts.
+func (daemon *Daemon) ContainerUpdate(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s CONTAINER", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	resources := execdriver.Resources{
+		Memory:     container.Config.Memory,
+		MemorySwap: container.Config.MemorySwap,
+		CpuShares:  container.Config.CpuShares,
+		Cpuset:     container.Config.Cpuset,
+	}
+	if job.EnvExists("Memory") {
+		resources.Memory = job.GetenvInt64("Memory")
+	}
+	if job.EnvExists("MemorySwap") {
+		resources.MemorySwap = job.GetenvInt64("MemorySwap")
+	}
+	if job.EnvExists("CpuShares") {
+		resources.CpuShares = job.GetenvInt64("CpuShares")
+	}
+	if job.EnvExists("Cpuset") {
+		resources.Cpuset = job.Getenv("Cpuset")
+	}
+
+	if resources.Memory != 0 && resources.Memory < 4194304 {
+		return job.Errorf("Minimum memory limit allowed is 4MB")
+	}
+	if resources.Memory > 0 && !daemon.SystemConfig().MemoryLimit {
+		return job.Errorf("Your kernel does not support memory limit capabilities")
+	}
+	if resources.Memory > 0 && !daemon.SystemConfig().SwapLimit {
+		resources.MemorySwap = -1
+	}
+
+	if container.Running && container.command != nil {
+		previous := container.command.Resources
+		container.command.Resources = &resources
+		if err := daemon.execDriver.Update(container.command); err != nil {
+			container.command.Resources = previous
+			return job.Errorf("Cannot update container %s: %s", name, err)
+		}
+	}
+
+	container.Config.Memory = resources.Memory
+	container.Config.MemorySwap = resources.MemorySwap
+	container.Config.CpuShares = resources.CpuShares
+	container.Config.Cpuset = resources.Cpuset
+	if err := container.toDisk(); err != nil {
+		return job.Error(err)
+	}
+
+	container.LogEvent("update")
+	return engine.StatusOK
+}
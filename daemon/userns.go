@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// parseRemappedRoot returns the user and the group of --userns-remap, given
+// as user or user:group. The group has the name of the user by default.
+func parseRemappedRoot(usergrp string) (string, string, error) {
+	parts := strings.SplitN(usergrp, ":", 2)
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("Invalid --userns-remap %q: no user", usergrp)
+	}
+	if len(parts) == 1 || parts[1] == "" {
+		return parts[0], parts[0], nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// setupRemappedRoot returns the mappings of the users and groups of the
+// containers to the subordinate ids of the user and group of
+// --userns-remap. They are nil when the containers are not remapped.
+func setupRemappedRoot(config *Config) ([]idtools.IDMap, []idtools.IDMap, error) {
+	if config.RemappedRoot == "" {
+		return nil, nil, nil
+	}
+	if config.ExecDriver != "native" {
+		return nil, nil, fmt.Errorf("User namespaces are only supported by the native execution driver")
+	}
+	if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+		return nil, nil, fmt.Errorf("User namespaces are not supported by the kernel")
+	}
+	username, groupname, err := parseRemappedRoot(config.RemappedRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	uidMaps, gidMaps, err := idtools.CreateIDMappings(username, groupname)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Can't create the ID mappings of --userns-remap: %s", err)
+	}
+	return uidMaps, gidMaps, nil
+}
+
+// setupDaemonRoot creates the root directory of the daemon. When the root of
+// the containers is remapped, the data of the daemon goes in a sub directory
+// owned by it, named after its ids, so that the layers and volumes created
+// with different mappings are kept apart.
+func setupDaemonRoot(config *Config, rootUID, rootGID int) error {
+	if rootUID == 0 && rootGID == 0 {
+		if err := os.MkdirAll(config.Root, 0700); err != nil && !os.IsExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	// the root of the containers needs to search the root directory to
+	// reach its sub directory
+	if err := os.MkdirAll(config.Root, 0701); err != nil && !os.IsExist(err) {
+		return err
+	}
+	fi, err := os.Stat(config.Root)
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&0001 == 0 {
+		if err := os.Chmod(config.Root, fi.Mode().Perm()|0001); err != nil {
+			return err
+		}
+	}
+
+	config.Root = filepath.Join(config.Root, fmt.Sprintf("%d.%d", rootUID, rootGID))
+	return idtools.MkdirAllAs(config.Root, 0700, rootUID, rootGID)
+}
+
+// remapArchive returns the archive of files of a container with their
+// owners mapped back to the users and groups of the containers. The files
+// created by the daemon, owned by users of the host outside of the
+// mappings, belong to root.
+func (daemon *Daemon) remapArchive(arch archive.Archive) (archive.Archive, error) {
+	if daemon.uidMaps == nil {
+		return arch, nil
+	}
+	remapped, err := archive.ChownArchive(arch, func(uid, gid int) (int, int, error) {
+		contUID, err := idtools.ToContainer(uid, daemon.uidMaps)
+		if err != nil {
+			contUID = 0
+		}
+		contGID, err := idtools.ToContainer(gid, daemon.gidMaps)
+		if err != nil {
+			contGID = 0
+		}
+		return contUID, contGID, nil
+	})
+	if err != nil {
+		arch.Close()
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(remapped, func() error {
+		remapped.Close()
+		return arch.Close()
+	}), nil
+}
+
+// remapArchiveToHost returns the archive with the owners of its files mapped
+// from the users and groups of the containers to the ones of the host.
+func (daemon *Daemon) remapArchiveToHost(arch archive.ArchiveReader) (archive.Archive, error) {
+	if daemon.uidMaps == nil {
+		return ioutil.NopCloser(arch), nil
+	}
+	return archive.ChownArchive(arch, func(uid, gid int) (int, int, error) {
+		hostUID, err := idtools.ToHost(uid, daemon.uidMaps)
+		if err != nil {
+			return -1, -1, err
+		}
+		hostGID, err := idtools.ToHost(gid, daemon.gidMaps)
+		if err != nil {
+			return -1, -1, err
+		}
+		return hostUID, hostGID, nil
+	})
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRemappedRoot(t *testing.T) {
+	for usergrp, expected := range map[string][2]string{
+		"dockremap":       {"dockremap", "dockremap"},
+		"dockremap:":      {"dockremap", "dockremap"},
+		"dockremap:staff": {"dockremap", "staff"},
+		"1000:1000":       {"1000", "1000"},
+	} {
+		user, group, err := parseRemappedRoot(usergrp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user != expected[0] || group != expected[1] {
+			t.Fatalf("Expected %q to be parsed as %v, got %s:%s", usergrp, expected, user, group)
+		}
+	}
+	if _, _, err := parseRemappedRoot(":staff"); err == nil {
+		t.Fatal("Expected an error without a user")
+	}
+}
+
+func TestSetupDaemonRoot(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-userns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	root := filepath.Join(tmp, "docker")
+	config := &Config{Root: root}
+	if err := setupDaemonRoot(config, os.Getuid()+1, os.Getgid()+1); err != nil {
+		t.Fatal(err)
+	}
+	expected := filepath.Join(root, fmt.Sprintf("%d.%d", os.Getuid()+1, os.Getgid()+1))
+	if config.Root != expected {
+		t.Fatalf("Expected the root to be %s, got %s", expected, config.Root)
+	}
+	fi, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0001 == 0 {
+		t.Fatalf("Expected %s to be searchable, got %v", root, fi.Mode())
+	}
+}
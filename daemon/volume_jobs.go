@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/utils"
+	"github.com/docker/docker/volumes"
+)
+
+var validVolumeNamePattern = regexp.MustCompile(`^` + validContainerNameChars + `+$`)
+
+// volumeName returns the name of a volume, its id for the volumes which
+// were created without one.
+func volumeName(v *volumes.Volume) string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.ID
+}
+
+func volumeEnv(v *volumes.Volume) *engine.Env {
+	out := &engine.Env{}
+	out.Set("Name", volumeName(v))
+	out.Set("Driver", v.DriverName())
+	out.Set("Mountpoint", v.Path)
+	return out
+}
+
+func (daemon *Daemon) VolumeCreate(job *engine.Job) engine.Status {
+	if len(job.Args) > 1 {
+		return job.Errorf("Usage: %s [NAME]", job.Name)
+	}
+	var name string
+	if len(job.Args) == 1 {
+		name = job.Args[0]
+	}
+	if name == "" {
+		name = utils.GenerateRandomID()
+	}
+	if !validVolumeNamePattern.MatchString(name) {
+		return job.Errorf("Invalid volume name (%s), only %s are allowed", name, validContainerNameChars)
+	}
+
+	v, err := daemon.volumes.CreateNamedVolume(name, job.Getenv("Driver"))
+	if err != nil {
+		return job.Error(err)
+	}
+	job.Printf("%s\n", v.Name)
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) Volumes(job *engine.Job) engine.Status {
+	list := daemon.volumes.List()
+	sort.Sort(volumesByName(list))
+
+	outs := engine.NewTable("", 0)
+	for _, v := range list {
+		outs.Add(volumeEnv(v))
+	}
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) VolumeInspect(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	v := daemon.volumes.GetByName(job.Args[0])
+	if v == nil {
+		return job.Errorf("No such volume: %s", job.Args[0])
+	}
+	if _, err := volumeEnv(v).WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) VolumeRm(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	v := daemon.volumes.GetByName(job.Args[0])
+	if v == nil {
+		return job.Errorf("No such volume: %s", job.Args[0])
+	}
+	if containers := v.Containers(); len(containers) > 0 {
+		for i, id := range containers {
+			containers[i] = utils.TruncateID(id)
+		}
+		return job.Errorf("Conflict, volume %s is in use by containers %s", job.Args[0], strings.Join(containers, ", "))
+	}
+	if err := daemon.volumes.Delete(v.Path); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// VolumePrune removes the volumes which are not used by any container, or
+// only lists them when DryRun is set, and reports the space reclaimed.
+func (daemon *Daemon) VolumePrune(job *engine.Job) engine.Status {
+	if len(job.Args) != 0 {
+		return job.Errorf("Usage: %s", job.Name)
+	}
+
+	var (
+		pruned    = daemon.volumes.Prune(job.GetenvBool("DryRun"))
+		list      = make([]map[string]interface{}, 0, len(pruned))
+		reclaimed int64
+	)
+	for _, v := range pruned {
+		name := v.Name
+		if name == "" {
+			name = v.ID
+		}
+		list = append(list, map[string]interface{}{"Name": name, "Size": v.Size})
+		reclaimed += v.Size
+	}
+
+	out := &engine.Env{}
+	if err := out.SetJson("Volumes", list); err != nil {
+		return job.Error(err)
+	}
+	out.SetInt64("SpaceReclaimed", reclaimed)
+	if _, err := out.WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+type volumesByName []*volumes.Volume
+
+func (v volumesByName) Len() int           { return len(v) }
+func (v volumesByName) Less(i, j int) bool { return volumeName(v[i]) < volumeName(v[j]) }
+func (v volumesByName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
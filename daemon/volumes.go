@@ -46,6 +46,11 @@ func (container *Container) prepareVolumes() error {
 		}
 	}
 
+	// The volumes of the previous starts are mounted again, the new ones
+	// are mounted as they are initialized
+	if err := container.mountVolumes(); err != nil {
+		return err
+	}
 	return container.createVolumes()
 }
 
@@ -68,6 +73,7 @@ func (container *Container) createVolumes() error {
 
 	for _, mnt := range mounts {
 		if err := mnt.initialize(); err != nil {
+			container.releaseVolumes(mounts)
 			return err
 		}
 	}
@@ -75,6 +81,17 @@ func (container *Container) createVolumes() error {
 	return nil
 }
 
+// releaseVolumes drops the references the container took on the volumes of
+// mounts which it did not end up using.
+func (container *Container) releaseVolumes(mounts map[string]*Mount) {
+	used := container.VolumePaths()
+	for _, mnt := range mounts {
+		if _, exists := used[mnt.volume.Path]; !exists {
+			mnt.volume.RemoveContainer(container.ID)
+		}
+	}
+}
+
 func (m *Mount) initialize() error {
 	// No need to initialize anything since it's already been initialized
 	if _, exists := m.container.Volumes[m.MountToPath]; exists {
@@ -86,6 +103,9 @@ func (m *Mount) initialize() error {
 	if err != nil {
 		return err
 	}
+	if err := m.container.mountVolume(m.volume); err != nil {
+		return err
+	}
 	m.container.VolumesRW[m.MountToPath] = m.Writable
 	m.container.Volumes[m.MountToPath] = m.volume.Path
 	m.volume.AddContainer(m.container.ID)
@@ -97,6 +117,42 @@ func (m *Mount) initialize() error {
 	return nil
 }
 
+// mountVolume has the driver of a volume mount it for the time the
+// container runs.
+func (container *Container) mountVolume(v *volumes.Volume) error {
+	if _, mounted := container.mountedVolumes[v.Path]; mounted {
+		return nil
+	}
+	if err := v.Mount(); err != nil {
+		return fmt.Errorf("Cannot mount volume %s: %v", v.Path, err)
+	}
+	if container.mountedVolumes == nil {
+		container.mountedVolumes = make(map[string]*volumes.Volume)
+	}
+	container.mountedVolumes[v.Path] = v
+	return nil
+}
+
+func (container *Container) mountVolumes() error {
+	for _, mnt := range container.VolumeMounts() {
+		if err := container.mountVolume(mnt.volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountVolumes tells the drivers of the volumes mounted for the container
+// that it stopped.
+func (container *Container) unmountVolumes() {
+	for path, v := range container.mountedVolumes {
+		if err := v.Unmount(); err != nil {
+			log.Errorf("%v: Failed to unmount volume %s: %v", container.ID, path, err)
+		}
+	}
+	container.mountedVolumes = nil
+}
+
 func (container *Container) VolumePaths() map[string]struct{} {
 	var paths = make(map[string]struct{})
 	for _, path := range container.Volumes {
@@ -122,16 +178,43 @@ func (container *Container) derefVolumes() {
 	}
 }
 
-func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error) {
+// parseVolumeMountConfig finds or creates the volumes of the container. They
+// are referenced by the container as soon as they are found so that they are
+// not pruned before being mounted, the references are dropped on errors.
+func (container *Container) parseVolumeMountConfig() (_ map[string]*Mount, err error) {
 	var mounts = make(map[string]*Mount)
+	defer func() {
+		if err != nil {
+			container.releaseVolumes(mounts)
+		}
+	}()
 	// Get all the bind mounts
 	for _, spec := range container.hostConfig.Binds {
 		path, mountToPath, writable, err := parseBindMountSpec(spec)
 		if err != nil {
 			return nil, err
 		}
+		if _, exists := container.hostConfig.Tmpfs[mountToPath]; exists {
+			return nil, fmt.Errorf("Duplicate mount point %s: it is both a volume and a tmpfs mount", mountToPath)
+		}
+		if !filepath.IsAbs(path) {
+			// Named volumes are filled with the content of the image
+			// on their first use, like the anonymous ones
+			vol, err := container.daemon.volumes.FindOrCreateNamedVolume(path, container.hostConfig.VolumeDriver, container.ID)
+			if err != nil {
+				return nil, err
+			}
+			mounts[mountToPath] = &Mount{
+				container:   container,
+				volume:      vol,
+				MountToPath: mountToPath,
+				Writable:    writable,
+				copyData:    true,
+			}
+			continue
+		}
 		// Check if a volume already exists for this and use it
-		vol, err := container.daemon.volumes.FindOrCreateVolume(path, writable)
+		vol, err := container.daemon.volumes.FindOrCreateVolume(path, "", writable, container.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -156,7 +239,12 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 			continue
 		}
 
-		vol, err := container.daemon.volumes.FindOrCreateVolume("", true)
+		// A tmpfs mount replaces the volume of the image
+		if _, exists := container.hostConfig.Tmpfs[path]; exists {
+			continue
+		}
+
+		vol, err := container.daemon.volumes.FindOrCreateVolume("", container.hostConfig.VolumeDriver, true, container.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -193,7 +281,11 @@ func parseBindMountSpec(spec string) (string, string, bool, error) {
 	}
 
 	if !filepath.IsAbs(path) {
-		return "", "", false, fmt.Errorf("cannot bind mount volume: %s volume paths must be absolute.", path)
+		if !validVolumeNamePattern.MatchString(path) {
+			return "", "", false, fmt.Errorf("cannot bind mount volume: %s volume paths must be absolute.", path)
+		}
+		// A volume name rather than a host path
+		return path, filepath.Clean(mountToPath), writable, nil
 	}
 
 	path = filepath.Clean(path)
@@ -247,18 +339,55 @@ func (container *Container) setupMounts() error {
 	// volumes. For instance if you use -v /usr:/usr and the host later mounts /usr/share you
 	// want this new mount in the container
 	// These mounts must be ordered based on the length of the path that it is being mounted to (lexicographic)
+	var userMounts []execdriver.Mount
 	for _, path := range container.sortedVolumeMounts() {
-		mounts = append(mounts, execdriver.Mount{
+		if _, exists := container.hostConfig.Tmpfs[path]; exists {
+			return fmt.Errorf("Duplicate mount point %s: it is both a volume and a tmpfs mount", path)
+		}
+		userMounts = append(userMounts, execdriver.Mount{
 			Source:      container.Volumes[path],
 			Destination: path,
 			Writable:    container.VolumesRW[path],
 		})
 	}
+	tmpfsMounts, err := container.tmpfsMounts()
+	if err != nil {
+		return err
+	}
+	userMounts = append(userMounts, tmpfsMounts...)
+	sort.Sort(mountsByDestination(userMounts))
 
-	container.command.Mounts = mounts
+	container.command.Mounts = append(mounts, userMounts...)
 	return nil
 }
 
+type mountsByDestination []execdriver.Mount
+
+func (m mountsByDestination) Len() int           { return len(m) }
+func (m mountsByDestination) Less(i, j int) bool { return m[i].Destination < m[j].Destination }
+func (m mountsByDestination) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// tmpfsMounts returns the tmpfs mounts of the container, and creates their
+// mount points.
+func (container *Container) tmpfsMounts() ([]execdriver.Mount, error) {
+	var mounts []execdriver.Mount
+	for path, options := range container.hostConfig.Tmpfs {
+		mountPath, err := symlink.FollowSymlinkInScope(filepath.Join(container.basefs, path), container.basefs)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(mountPath, 0755); err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, execdriver.Mount{
+			Destination: path,
+			Type:        "tmpfs",
+			Data:        options,
+		})
+	}
+	return mounts, nil
+}
+
 func parseVolumesFromSpec(daemon *Daemon, spec string) (map[string]*Mount, error) {
 	specParts := strings.SplitN(spec, ":", 2)
 	if len(specParts) == 0 {
@@ -17,6 +17,7 @@ import (
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/truncindex"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
@@ -228,8 +229,9 @@ func (graph *Graph) Mktemp(id string) (string, error) {
 // empty file at /.dockerinit
 //
 // This extra layer is used by all containers as the top-most ro layer. It protects
-// the container from unwanted side-effects on the rw layer.
-func SetupInitLayer(initLayer string) error {
+// the container from unwanted side-effects on the rw layer. The files it
+// creates are owned by rootUID and rootGID, the root of the containers.
+func SetupInitLayer(initLayer string, rootUID, rootGID int) error {
 	for pth, typ := range map[string]string{
 		"/dev/pts":         "dir",
 		"/dev/shm":         "dir",
@@ -252,12 +254,12 @@ func SetupInitLayer(initLayer string) error {
 
 		if _, err := os.Stat(path.Join(initLayer, pth)); err != nil {
 			if os.IsNotExist(err) {
-				if err := os.MkdirAll(path.Join(initLayer, path.Dir(pth)), 0755); err != nil {
+				if err := idtools.MkdirAllAs(path.Join(initLayer, path.Dir(pth)), 0755, rootUID, rootGID); err != nil {
 					return err
 				}
 				switch typ {
 				case "dir":
-					if err := os.MkdirAll(path.Join(initLayer, pth), 0755); err != nil {
+					if err := idtools.MkdirAllAs(path.Join(initLayer, pth), 0755, rootUID, rootGID); err != nil {
 						return err
 					}
 				case "file":
@@ -266,10 +268,16 @@ func SetupInitLayer(initLayer string) error {
 						return err
 					}
 					f.Close()
+					if err := os.Chown(path.Join(initLayer, pth), rootUID, rootGID); err != nil {
+						return err
+					}
 				default:
 					if err := os.Symlink(typ, path.Join(initLayer, pth)); err != nil {
 						return err
 					}
+					if err := os.Lchown(path.Join(initLayer, pth), rootUID, rootGID); err != nil {
+						return err
+					}
 				}
 			} else {
 				return err
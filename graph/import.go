@@ -1,11 +1,14 @@
 package graph
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/url"
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
@@ -20,11 +23,27 @@ func (s *TagStore) CmdImport(job *engine.Job) engine.Status {
 		sf      = utils.NewStreamFormatter(job.GetenvBool("json"))
 		archive archive.ArchiveReader
 		resp    *http.Response
+		config  *runconfig.Config
 	)
 	if len(job.Args) > 2 {
 		tag = job.Args[2]
 	}
 
+	if changes := job.GetenvList("changes"); len(changes) > 0 {
+		var (
+			buildConfigJob = job.Eng.Job("build_config")
+			configBuffer   = bytes.NewBuffer(nil)
+		)
+		buildConfigJob.SetenvList("changes", changes)
+		buildConfigJob.Stdout.Add(configBuffer)
+		if err := buildConfigJob.Run(); err != nil {
+			return job.Error(err)
+		}
+		if err := json.NewDecoder(configBuffer).Decode(&config); err != nil {
+			return job.Error(err)
+		}
+	}
+
 	if src == "-" {
 		archive = job.Stdin
 	} else {
@@ -46,7 +65,7 @@ func (s *TagStore) CmdImport(job *engine.Job) engine.Status {
 		defer progressReader.Close()
 		archive = progressReader
 	}
-	img, err := s.graph.Create(archive, "", "", "Imported from "+src, "", nil, nil)
+	img, err := s.graph.Create(archive, "", "", "Imported from "+src, "", nil, config)
 	if err != nil {
 		return job.Error(err)
 	}
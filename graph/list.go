@@ -11,6 +11,12 @@ import (
 	"github.com/docker/docker/pkg/parsers/filters"
 )
 
+// acceptedImageFilters are the filters understood by the "images" job.
+var acceptedImageFilters = map[string]bool{
+	"dangling": true,
+	"label":    true,
+}
+
 func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	var (
 		allImages   map[string]*image.Image
@@ -22,6 +28,9 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	if err != nil {
 		return job.Error(err)
 	}
+	if err := imageFilters.Validate(acceptedImageFilters); err != nil {
+		return job.Error(err)
+	}
 	if i, ok := imageFilters["dangling"]; ok {
 		for _, value := range i {
 			if strings.ToLower(value) == "true" {
@@ -52,6 +61,10 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 				log.Printf("Warning: couldn't load %s from %s/%s: %s", id, name, tag, err)
 				continue
 			}
+			if !matchImageLabels(imageFilters, image) {
+				delete(allImages, id)
+				continue
+			}
 
 			if out, exists := lookup[id]; exists {
 				if filt_tagged {
@@ -84,6 +97,9 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	// Display images which aren't part of a repository/tag
 	if job.Getenv("filter") == "" {
 		for _, image := range allImages {
+			if !matchImageLabels(imageFilters, image) {
+				continue
+			}
 			out := &engine.Env{}
 			out.Set("ParentId", image.Parent)
 			out.SetList("RepoTags", []string{"<none>:<none>"})
@@ -101,3 +117,13 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// matchImageLabels returns true if the labels of the image satisfy the
+// label filters.
+func matchImageLabels(imageFilters filters.Args, img *image.Image) bool {
+	var labels map[string]string
+	if img.Config != nil {
+		labels = img.Config.Labels
+	}
+	return imageFilters.MatchKVList("label", labels)
+}
@@ -1,19 +1,28 @@
 package graph
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/engine"
+	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
 )
 
+var ErrV2RegistryUnavailable = errors.New("error v2 registry unavailable")
+
 // Retrieve the all the images to be uploaded in the correct order
 func (s *TagStore) getImageList(localRepo map[string]string, requestedTag string) ([]string, map[string][]string, error) {
 	var (
@@ -188,6 +197,157 @@ func (s *TagStore) pushImage(r *registry.Session, out io.Writer, remote, imgID,
 	return imgData.Checksum, nil
 }
 
+func (s *TagStore) pushV2Repository(r *registry.Session, out io.Writer, localName, remoteName string, localRepo map[string]string, tag string, sf *utils.StreamFormatter) error {
+	if _, err := r.GetV2Version(nil); err != nil {
+		log.Debugf("Registry does not support v2: %s", err)
+		return ErrV2RegistryUnavailable
+	}
+	if s.trustKey == nil {
+		return fmt.Errorf("no trust key available to sign the manifest")
+	}
+
+	var tags []string
+	if tag == "" {
+		for t := range localRepo {
+			tags = append(tags, t)
+		}
+	} else {
+		if _, exists := localRepo[tag]; !exists {
+			return fmt.Errorf("Tag %s does not exist for %s", tag, localName)
+		}
+		tags = []string{tag}
+	}
+
+	out = utils.NewWriteFlusher(out)
+	out.Write(sf.FormatStatus("", "Pushing repository %s (%d tags) to a v2 registry", localName, len(tags)))
+	for _, t := range tags {
+		if err := s.pushV2Tag(r, out, remoteName, t, localRepo[t], sf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushV2Tag uploads the layers of the image referenced by tag that the
+// registry does not have yet and then puts the signed manifest of the tag.
+func (s *TagStore) pushV2Tag(r *registry.Session, out io.Writer, remoteName, tag, imgID string, sf *utils.StreamFormatter) error {
+	// the manifest lists the layers from the top most one down to the base
+	img, err := s.graph.Get(imgID)
+	if err != nil {
+		return err
+	}
+	var layers []*image.Image
+	for img != nil {
+		layers = append(layers, img)
+		parent, err := img.GetParent()
+		if err != nil {
+			return fmt.Errorf("Cannot get the parent layers of %s: %s", img.ID, err)
+		}
+		img = parent
+	}
+
+	manifest := &registry.ManifestData{
+		Name:          remoteName,
+		Tag:           tag,
+		Architecture:  layers[0].Architecture,
+		SchemaVersion: 1,
+		FSLayers:      make([]*registry.FSLayer, len(layers)),
+		History:       make([]*registry.ManifestHistory, len(layers)),
+	}
+
+	// push the base layer first so that a partial push leaves usable blobs
+	for i := len(layers) - 1; i >= 0; i-- {
+		img := layers[i]
+		jsonRaw, err := ioutil.ReadFile(path.Join(s.graph.Root, img.ID, "json"))
+		if err != nil {
+			return fmt.Errorf("Cannot retrieve the path for {%s}: %s", img.ID, err)
+		}
+		sumStr, err := s.pushV2Image(r, out, remoteName, img, sf)
+		if err != nil {
+			return err
+		}
+		manifest.FSLayers[i] = &registry.FSLayer{BlobSum: sumStr}
+		manifest.History[i] = &registry.ManifestHistory{V1Compatibility: string(jsonRaw)}
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "   ")
+	if err != nil {
+		return err
+	}
+	js, err := libtrust.NewJSONSignature(manifestBytes)
+	if err != nil {
+		return err
+	}
+	if err := js.Sign(s.trustKey); err != nil {
+		return err
+	}
+	signedBody, err := js.PrettySignature("signatures")
+	if err != nil {
+		return err
+	}
+
+	out.Write(sf.FormatStatus("", "Pushing tag for rev [%s] on {%s:%s}", utils.TruncateID(imgID), remoteName, tag))
+	return r.PutV2ImageManifest(remoteName, tag, bytes.NewReader(signedBody), nil)
+}
+
+// pushV2Image computes the tarsum of the image's layer and uploads the
+// layer unless the registry already has a blob with that sum. It returns
+// the tarsum the layer is referenced by in the manifest.
+func (s *TagStore) pushV2Image(r *registry.Session, out io.Writer, remoteName string, img *image.Image, sf *utils.StreamFormatter) (string, error) {
+	out.Write(sf.FormatProgress(utils.TruncateID(img.ID), "Buffering to Disk", nil))
+
+	layerData, err := s.graph.TempLayerArchive(img.ID, archive.Uncompressed, sf, out)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate layer archive: %s", err)
+	}
+	defer os.RemoveAll(layerData.Name())
+	defer layerData.Close()
+
+	ts, err := tarsum.NewTarSum(layerData, true, tarsum.Version0)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+		return "", err
+	}
+	sumStr := ts.Sum(nil)
+	chunks := strings.SplitN(sumStr, ":", 2)
+	if len(chunks) < 2 {
+		return "", fmt.Errorf("expected 2 parts in the sumStr, got %#v", chunks)
+	}
+	sumType, sum := chunks[0], chunks[1]
+
+	// skip the upload if the blob is already in this repository or can be
+	// mounted from another one
+	if exists, err := r.HeadV2ImageBlob(remoteName, sumType, sum, nil); err != nil {
+		return "", err
+	} else if exists {
+		out.Write(sf.FormatProgress(utils.TruncateID(img.ID), "Image already exists", nil))
+		return sumStr, nil
+	}
+	if mounted, err := r.PostV2ImageMountBlob(remoteName, sumType, sum, nil); err != nil {
+		return "", err
+	} else if mounted {
+		out.Write(sf.FormatProgress(utils.TruncateID(img.ID), "Image already exists", nil))
+		return sumStr, nil
+	}
+
+	if _, err := layerData.Seek(0, 0); err != nil {
+		return "", err
+	}
+	log.Debugf("rendered layer for %s of [%d] size", img.ID, layerData.Size)
+	serverSum, err := r.PutV2ImageBlob(remoteName, sumType, utils.ProgressReader(layerData, int(layerData.Size), out, sf, false, utils.TruncateID(img.ID), "Pushing"), nil)
+	if err != nil {
+		return "", err
+	}
+	if serverSum != sumStr {
+		return "", fmt.Errorf("checksum mismatch for %s: registry computed %s, expected %s", img.ID, serverSum, sumStr)
+	}
+
+	out.Write(sf.FormatProgress(utils.TruncateID(img.ID), "Image successfully pushed", nil))
+	return sumStr, nil
+}
+
 // FIXME: Allow to interrupt current push when new push of same image is done.
 func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 	if n := len(job.Args); n != 1 {
@@ -235,6 +395,17 @@ func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 		job.Stdout.Write(sf.FormatStatus("", "The push refers to a repository [%s] (len: %d)", localName, reposLen))
 		// If it fails, try to get the repository
 		if localRepo, exists := s.Repositories[localName]; exists {
+			if endpoint.Version == registry.APIVersion2 || endpoint.VersionString(1) == registry.IndexServerAddress() {
+				// as for pulls, any v2 failure, such as a registry requiring
+				// a token, falls back to the v1 protocol
+				if err := s.pushV2Repository(r, job.Stdout, localName, remoteName, localRepo, tag, sf); err == nil {
+					return engine.StatusOK
+				} else if err != ErrV2RegistryUnavailable {
+					log.Errorf("Error from V2 registry: %s", err)
+				}
+				log.Debugf("Falling back to v1 push for %s", localName)
+			}
+
 			if err := s.pushRepository(r, job.Stdout, localName, remoteName, localRepo, tag, sf); err != nil {
 				return job.Error(err)
 			}
@@ -0,0 +1,280 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/tarsum"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+	"github.com/docker/libtrust"
+)
+
+// testV2Registry is a minimal v2 registry recording the blobs and
+// manifests pushed to it
+type testV2Registry struct {
+	sync.Mutex
+	server    *httptest.Server
+	available bool
+	// blobs already in the repository, answered by HEAD
+	blobs map[string]bool
+	// blobs of other repositories that can be mounted
+	mountable map[string]bool
+	uploads   []string
+	manifest  []byte
+}
+
+func newTestV2Registry(available bool) *testV2Registry {
+	reg := &testV2Registry{
+		available: available,
+		blobs:     make(map[string]bool),
+		mountable: make(map[string]bool),
+	}
+	reg.server = httptest.NewServer(http.HandlerFunc(reg.serveHTTP))
+	return reg
+}
+
+func (reg *testV2Registry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.Lock()
+	defer reg.Unlock()
+
+	// the paths are /v2/<route>/<imagename>/..., imagename has no slash here
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+	switch {
+	case parts[0] == "_ping":
+		w.Write([]byte("{}"))
+	case parts[0] == "version":
+		if !reg.available {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("{}"))
+	case parts[0] == "blob" && r.Method == "HEAD" && len(parts) == 4:
+		if !reg.blobs[parts[2]+":"+parts[3]] {
+			w.WriteHeader(404)
+		}
+	case parts[0] == "mountblob" && r.Method == "POST" && len(parts) == 4:
+		sum := parts[2] + ":" + parts[3]
+		if !reg.mountable[sum] {
+			w.WriteHeader(300)
+			return
+		}
+		reg.blobs[sum] = true
+	case parts[0] == "blob" && r.Method == "PUT" && len(parts) == 3:
+		ts, err := tarsum.NewTarSum(r.Body, true, tarsum.Version0)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		sum := ts.Sum(nil)
+		reg.blobs[sum] = true
+		reg.uploads = append(reg.uploads, sum)
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(map[string]string{"checksum": sum})
+	case parts[0] == "manifest" && r.Method == "PUT":
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		reg.manifest = data
+		w.WriteHeader(201)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (reg *testV2Registry) session(t *testing.T) *registry.Session {
+	endpoint, err := registry.NewEndpoint(reg.server.URL+"/v2/", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.NewSession(&registry.AuthConfig{}, registry.HTTPRequestFactory(nil), endpoint, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func fakeLayer(name string) (io.Reader, error) {
+	content := []byte(name)
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	hdr := &tar.Header{
+		Name: name,
+		Uid:  os.Getuid(),
+		Gid:  os.Getgid(),
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	tw.Write(content)
+	tw.Close()
+	return buf, nil
+}
+
+// mkTestPushStore creates a tag store with the image chain base <- middle <- top
+// tagged as testImageName:latest and a key to sign the manifests
+func mkTestPushStore(root string, t *testing.T) *TagStore {
+	store := mkTestTagStore(root, t)
+	key, err := libtrust.GenerateECP256PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.trustKey = key
+
+	parent := ""
+	for _, id := range []string{"base", "middle", "top"} {
+		layer, err := fakeLayer(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img := &image.Image{ID: id, Parent: parent, Architecture: "amd64"}
+		if err := store.graph.Register(img, nil, layer); err != nil {
+			t.Fatal(err)
+		}
+		parent = id
+	}
+	if err := store.Set(testImageName, "latest", "top", true); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestPushV2Manifest(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestPushStore(tmp, t)
+	reg := newTestV2Registry(true)
+	defer reg.server.Close()
+
+	sf := utils.NewStreamFormatter(false)
+	if err := store.pushV2Repository(reg.session(t), ioutil.Discard, testImageName, testImageName, store.Repositories[testImageName], "latest", sf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reg.uploads) != 3 {
+		t.Fatalf("Expected 3 blobs to be uploaded, got %d", len(reg.uploads))
+	}
+	manifest := &registry.ManifestData{}
+	if err := json.Unmarshal(reg.manifest, manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != testImageName || manifest.Tag != "latest" || manifest.Architecture != "amd64" {
+		t.Fatalf("Unexpected manifest header %s:%s (%s)", manifest.Name, manifest.Tag, manifest.Architecture)
+	}
+	if len(manifest.FSLayers) != 3 || len(manifest.History) != 3 {
+		t.Fatalf("Expected 3 layers in the manifest, got %d and %d history entries", len(manifest.FSLayers), len(manifest.History))
+	}
+	// the manifest lists the top layer first while the base is uploaded first
+	for i, id := range []string{"top", "middle", "base"} {
+		img := &image.Image{}
+		if err := json.Unmarshal([]byte(manifest.History[i].V1Compatibility), img); err != nil {
+			t.Fatal(err)
+		}
+		if img.ID != id {
+			t.Fatalf("Expected layer %d of the manifest to be %s, got %s", i, id, img.ID)
+		}
+		if sum := reg.uploads[len(reg.uploads)-1-i]; manifest.FSLayers[i].BlobSum != sum {
+			t.Fatalf("Expected layer %d of the manifest to reference %s, got %s", i, sum, manifest.FSLayers[i].BlobSum)
+		}
+	}
+	if _, err := libtrust.ParsePrettySignature(reg.manifest, "signatures"); err != nil {
+		t.Fatalf("The manifest is not signed: %s", err)
+	}
+}
+
+func TestPushV2SkipExistingBlobs(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestPushStore(tmp, t)
+	sf := utils.NewStreamFormatter(false)
+
+	// learn the sums of the layers, base first
+	first := newTestV2Registry(true)
+	defer first.server.Close()
+	if err := store.pushV2Repository(first.session(t), ioutil.Discard, testImageName, testImageName, store.Repositories[testImageName], "latest", sf); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.uploads) != 3 {
+		t.Fatalf("Expected 3 blobs to be uploaded, got %d", len(first.uploads))
+	}
+
+	// the base layer is already in the repository and the middle one can
+	// be mounted from another repository
+	reg := newTestV2Registry(true)
+	defer reg.server.Close()
+	reg.blobs[first.uploads[0]] = true
+	reg.mountable[first.uploads[1]] = true
+	if err := store.pushV2Repository(reg.session(t), ioutil.Discard, testImageName, testImageName, store.Repositories[testImageName], "latest", sf); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.uploads) != 1 || reg.uploads[0] != first.uploads[2] {
+		t.Fatalf("Expected only the top layer %s to be uploaded, got %v", first.uploads[2], reg.uploads)
+	}
+	if reg.manifest == nil {
+		t.Fatal("Expected the manifest to be pushed")
+	}
+}
+
+func TestPushV2RegistryUnavailable(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestPushStore(tmp, t)
+	reg := newTestV2Registry(false)
+	defer reg.server.Close()
+
+	sf := utils.NewStreamFormatter(false)
+	if err := store.pushV2Repository(reg.session(t), ioutil.Discard, testImageName, testImageName, store.Repositories[testImageName], "latest", sf); err != ErrV2RegistryUnavailable {
+		t.Fatalf("Expected %q so the push falls back to v1, got %v", ErrV2RegistryUnavailable, err)
+	}
+	if len(reg.uploads) != 0 || reg.manifest != nil {
+		t.Fatal("Expected nothing to be pushed to a registry without v2 support")
+	}
+}
+
+func TestPushV2MissingParent(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestPushStore(tmp, t)
+	reg := newTestV2Registry(true)
+	defer reg.server.Close()
+
+	if err := store.graph.Delete("middle"); err != nil {
+		t.Fatal(err)
+	}
+	sf := utils.NewStreamFormatter(false)
+	if err := store.pushV2Repository(reg.session(t), ioutil.Discard, testImageName, testImageName, store.Repositories[testImageName], "latest", sf); err == nil {
+		t.Fatal("Expected the push to fail when a parent layer is missing")
+	}
+	if reg.manifest != nil {
+		t.Fatal("Expected no manifest to be pushed with a missing parent layer")
+	}
+}
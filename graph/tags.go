@@ -14,6 +14,7 @@ import (
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
 )
 
 const DEFAULTTAG = "latest"
@@ -25,6 +26,7 @@ var (
 type TagStore struct {
 	path               string
 	graph              *Graph
+	trustKey           libtrust.PrivateKey
 	mirrors            []string
 	insecureRegistries []string
 	Repositories       map[string]Repository
@@ -55,7 +57,7 @@ func (r Repository) Contains(u Repository) bool {
 	return true
 }
 
-func NewTagStore(path string, graph *Graph, mirrors []string, insecureRegistries []string) (*TagStore, error) {
+func NewTagStore(path string, graph *Graph, key libtrust.PrivateKey, mirrors []string, insecureRegistries []string) (*TagStore, error) {
 	abspath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
@@ -64,6 +66,7 @@ func NewTagStore(path string, graph *Graph, mirrors []string, insecureRegistries
 	store := &TagStore{
 		path:               abspath,
 		graph:              graph,
+		trustKey:           key,
 		mirrors:            mirrors,
 		insecureRegistries: insecureRegistries,
 		Repositories:       make(map[string]Repository),
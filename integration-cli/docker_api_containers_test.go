@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/docker/docker/api/stats"
 	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
 )
 
@@ -120,3 +121,33 @@ func TestContainerApiGetChanges(t *testing.T) {
 
 	logDone("container REST API - check GET containers/changes")
 }
+
+func TestContainerApiGetStats(t *testing.T) {
+	name := "statscontainer"
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", name, "busybox", "sleep", "3")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatalf("Error on container creation: %v, output: %q", err, out)
+	}
+	defer deleteAllContainers()
+
+	// the stream ends once the container has exited
+	body, err := sockRequest("GET", "/containers/"+name+"/stats")
+	if err != nil {
+		t.Fatalf("GET containers/stats sockRequest failed: %v", err)
+	}
+
+	var s stats.Stats
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(&s); err != nil {
+		t.Fatalf("unable to decode stats: %v, body: %q", err, body)
+	}
+	if s.Read.IsZero() {
+		t.Fatalf("expected a read timestamp in the stats sample")
+	}
+	if s.MemoryStats.Limit == 0 {
+		t.Fatalf("expected a memory limit in the stats sample")
+	}
+
+	logDone("container REST API - check GET containers/stats")
+}
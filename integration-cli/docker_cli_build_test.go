@@ -1564,6 +1564,33 @@ func TestBuildUser(t *testing.T) {
 	logDone("build - user")
 }
 
+func TestBuildStopSignal(t *testing.T) {
+	name := "testbuildstopsignal"
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox
+		STOPSIGNAL SIGKILL`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectField(name, "Config.StopSignal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "SIGKILL" {
+		t.Fatalf("Signal %s, expected SIGKILL", res)
+	}
+
+	if _, err := buildImage(name+"-invalid",
+		`FROM busybox
+		STOPSIGNAL SIGFOO`,
+		true); err == nil {
+		t.Fatal("Expected the build to fail with an invalid STOPSIGNAL")
+	}
+	logDone("build - stop signal")
+}
+
 func TestBuildRelativeWorkdir(t *testing.T) {
 	name := "testbuildrelativeworkdir"
 	expected := "/test2/test3"
@@ -1638,6 +1665,133 @@ func TestBuildEnv(t *testing.T) {
 	logDone("build - env")
 }
 
+func TestBuildLabels(t *testing.T) {
+	name := "testbuildlabels"
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox
+		ENV VENDOR ACME
+		LABEL vendor=$VENDOR "description"="a \"quoted\" description"
+		LABEL overridden=first
+		LABEL overridden=second empty=`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectFieldJSON(name, "Config.Labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(res), &labels); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"vendor":      "ACME",
+		"description": `a "quoted" description`,
+		"overridden":  "second",
+		"empty":       "",
+	}
+	if len(labels) != len(expected) {
+		t.Fatalf("Labels %v, expected %v", labels, expected)
+	}
+	for k, v := range expected {
+		if labels[k] != v {
+			t.Fatalf("Labels %v, expected %v", labels, expected)
+		}
+	}
+	logDone("build - label")
+}
+
+func TestBuildArgs(t *testing.T) {
+	name := "testbuildargs"
+	defer deleteImages(name)
+	_, out, err := buildImageWithFlags(name,
+		`FROM busybox
+		ARG user
+		ARG workdir=/default
+		ARG unused=value
+		ENV HOME /home/$user
+		WORKDIR $workdir
+		RUN echo "user=$user" && [ "$user" = "docker" ] && [ "$(pwd)" = "/default" ]`,
+		true, "--build-arg", "user=docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "user=docker") {
+		t.Fatalf("RUN did not see the build arg: %s", out)
+	}
+	res, err := inspectFieldJSON(name, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res, "HOME=/home/docker") {
+		t.Fatalf("build arg was not substituted in ENV: %s", res)
+	}
+	if strings.Contains(res, "user=") || strings.Contains(res, "workdir=") {
+		t.Fatalf("build args were persisted in the image env: %s", res)
+	}
+	logDone("build - build args")
+}
+
+func TestBuildArgsOverriddenByEnv(t *testing.T) {
+	name := "testbuildargsoverriddenbyenv"
+	defer deleteImages(name)
+	_, _, err := buildImageWithFlags(name,
+		`FROM busybox
+		ARG user
+		ENV user root
+		RUN [ "$user" = "root" ]`,
+		true, "--build-arg", "user=docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logDone("build - build args are overridden by ENV")
+}
+
+func TestBuildArgsCache(t *testing.T) {
+	name := "testbuildargscache"
+	defer deleteImages(name)
+	dockerfile := `FROM busybox
+		ARG version
+		RUN echo $version`
+	id1, _, err := buildImageWithFlags(name, dockerfile, true, "--build-arg", "version=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, _, err := buildImageWithFlags(name, dockerfile, true, "--build-arg", "version=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 != id2 {
+		t.Fatal("The cache should have been used with the same build args")
+	}
+	id3, _, err := buildImageWithFlags(name, dockerfile, true, "--build-arg", "version=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 == id3 {
+		t.Fatal("The cache should not have been used with different build args")
+	}
+	logDone("build - build args participate in the cache")
+}
+
+func TestBuildArgsNotDeclared(t *testing.T) {
+	name := "testbuildargsnotdeclared"
+	defer deleteImages(name)
+	_, out, err := buildImageWithFlags(name,
+		`FROM busybox
+		RUN true`,
+		true, "--build-arg", "user=docker")
+	if err == nil {
+		t.Fatal("Build should have failed with an undeclared build arg")
+	}
+	if !strings.Contains(out, "were not consumed") {
+		t.Fatalf("Unexpected error: %s", out)
+	}
+	logDone("build - undeclared build args fail the build")
+}
+
 func TestBuildContextCleanup(t *testing.T) {
 	name := "testbuildcontextcleanup"
 	defer deleteImages(name)
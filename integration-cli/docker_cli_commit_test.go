@@ -199,3 +199,55 @@ func TestCommitWithHostBindMount(t *testing.T) {
 
 	logDone("commit - commit bind mounted file")
 }
+
+func TestCommitChange(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "test", "busybox", "true")
+	if _, err := runCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = exec.Command(dockerBinary, "commit",
+		"--change", "EXPOSE 8080",
+		"--change", "ENV DEBUG true",
+		"--change", "WORKDIR /opt",
+		"--change", "CMD [\"/bin/sh\"]",
+		"test", "test-commit")
+	imageID, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(imageID, err)
+	}
+	imageID = strings.Trim(imageID, "\r\n")
+
+	expected := map[string]string{
+		"Config.ExposedPorts": "map[8080/tcp:map[]]",
+		"Config.WorkingDir":   "/opt",
+		"Config.Cmd":          "[/bin/sh]",
+	}
+	for field, value := range expected {
+		actual, err := inspectField(imageID, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != value {
+			t.Errorf("expected %s to be %q, got %q", field, value, actual)
+		}
+	}
+
+	env, err := inspectField(imageID, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(env, "DEBUG=true") {
+		t.Errorf("expected Config.Env to contain DEBUG=true, got %q", env)
+	}
+
+	cmd = exec.Command(dockerBinary, "commit", "--change", "RUN true", "test", "test-commit-run")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "RUN is not a valid change command") {
+		t.Fatalf("expected RUN to be rejected, got %q (%v)", out, err)
+	}
+
+	deleteAllContainers()
+	deleteImages(imageID)
+
+	logDone("commit - commit --change")
+}
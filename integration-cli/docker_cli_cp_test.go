@@ -478,3 +478,94 @@ func TestCpVolumePath(t *testing.T) {
 
 	logDone("cp - volume path")
 }
+
+func TestCpToContainer(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cp-test-to-container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, cpTestName), []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, exitCode, err := cmd(t, "create", "-v", "/foo", "busybox", "cat", "/tmp/"+cpTestName, "/tmp/renamed", "/foo/"+cpTestName)
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	// the volume is created once the container started
+	if out, _, err := cmd(t, "start", cleanedContainerID); err != nil {
+		t.Fatal("failed to start the container", out, err)
+	}
+	if out, _, err := cmd(t, "wait", cleanedContainerID); err != nil {
+		t.Fatal("failed to wait for the container", out, err)
+	}
+
+	// Copy into a directory
+	if out, _, err := cmd(t, "cp", filepath.Join(tmpDir, cpTestName), cleanedContainerID+":/tmp"); err != nil {
+		t.Fatalf("couldn't copy to the container: %s %v", out, err)
+	}
+	// Copy to a new file
+	if out, _, err := cmd(t, "cp", filepath.Join(tmpDir, cpTestName), cleanedContainerID+":/tmp/renamed"); err != nil {
+		t.Fatalf("couldn't copy to a new file of the container: %s %v", out, err)
+	}
+	// Copy into a volume
+	if out, _, err := cmd(t, "cp", filepath.Join(tmpDir, cpTestName), cleanedContainerID+":/foo"); err != nil {
+		t.Fatalf("couldn't copy to the volume of the container: %s %v", out, err)
+	}
+
+	out, _, err = cmd(t, "start", "-a", cleanedContainerID)
+	if err != nil {
+		t.Fatalf("failed to read the copied files: %s %v", out, err)
+	}
+	if expected := cpHostContents + cpHostContents + cpHostContents; out != expected {
+		t.Fatalf("Expected %q, got %q", expected, out)
+	}
+
+	// A directory can't replace a file
+	if _, _, err := cmd(t, "cp", tmpDir, cleanedContainerID+":/tmp/renamed"); err == nil {
+		t.Fatal("Expected copying a directory to a file to fail")
+	}
+
+	logDone("cp - to container")
+}
+
+// Check that the symlinks of the container don't make cp write outside of it
+func TestCpToContainerSymlinkBreakout(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cp-test-symlink-breakout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	hostDir := filepath.Join(tmpDir, "host")
+	if err := os.Mkdir(hostDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, cpTestName), []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, exitCode, err := cmd(t, "run", "-d", "busybox", "/bin/sh", "-c", "mkdir -p '"+hostDir+"' && ln -s '"+hostDir+"' /link")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	out, _, err = cmd(t, "wait", cleanedContainerID)
+	if err != nil || stripTrailingCharacters(out) != "0" {
+		t.Fatal("failed to set up container", out, err)
+	}
+
+	if out, _, err := cmd(t, "cp", filepath.Join(tmpDir, cpTestName), cleanedContainerID+":/link"); err != nil {
+		t.Fatalf("couldn't copy to the container: %s %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(hostDir, cpTestName)); err == nil {
+		t.Fatal("cp followed a symlink of the container outside of it")
+	}
+
+	logDone("cp - symlinks don't escape the container")
+}
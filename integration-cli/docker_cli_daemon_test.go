@@ -128,3 +128,39 @@ func TestDaemonStartBridgeWithoutIPAssociation(t *testing.T) {
 
 	logDone("daemon - successful daemon start when bridge has no IP association")
 }
+
+func TestDaemonLiveRestoreKeepsContainersRunning(t *testing.T) {
+	d := NewDaemon(t)
+	if err := d.StartWithBusybox("--live-restore"); err != nil {
+		t.Fatalf("Could not start daemon with busybox: %v", err)
+	}
+	defer d.Stop()
+
+	if out, err := d.Cmd("run", "-d", "--name", "live", "busybox:latest", "sh", "-c", "echo before; while true; do sleep 1; done"); err != nil {
+		t.Fatalf("Could not run live: err=%v\n%s", err, out)
+	}
+	pid, err := d.Cmd("inspect", "--format", "{{.State.Pid}}", "live")
+	if err != nil {
+		t.Fatalf("Could not inspect live: err=%v\n%s", err, pid)
+	}
+
+	if err := d.Restart("--live-restore"); err != nil {
+		t.Fatalf("Could not restart daemon: %v", err)
+	}
+
+	out, err := d.Cmd("inspect", "--format", "{{.State.Running}} {{.State.Pid}}", "live")
+	if err != nil {
+		t.Fatalf("Could not inspect live: err=%v\n%s", err, out)
+	}
+	if expected := "true " + strings.TrimSpace(pid); strings.TrimSpace(out) != expected {
+		t.Fatalf("Expected the container to keep running as %q, got %q", expected, strings.TrimSpace(out))
+	}
+	if out, err := d.Cmd("logs", "live"); err != nil || !strings.Contains(out, "before") {
+		t.Fatalf("Expected the logs of the container to be kept: err=%v\n%s", err, out)
+	}
+	if out, err := d.Cmd("stop", "-t", "1", "live"); err != nil {
+		t.Fatalf("Could not stop live: err=%v\n%s", err, out)
+	}
+
+	logDone("daemon - containers keep running across restarts with --live-restore")
+}
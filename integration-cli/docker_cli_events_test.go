@@ -92,6 +92,33 @@ func TestEventsContainerFailStartDie(t *testing.T) {
 	logDone("events - container failed to start logs die")
 }
 
+func TestEventsContainerOOM(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "--name", "testeventoom", "-m", "4m", "busybox", "sh", "-c", "x=a; while true; do x=$x$x$x$x; done")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Expected the container to be OOM killed, got %s", out)
+	}
+	defer deleteAllContainers()
+
+	eventsCmd := exec.Command(dockerBinary, "events", "--since=0", fmt.Sprintf("--until=%d", time.Now().Unix()))
+	out, _, _ := runCommandWithOutput(eventsCmd)
+	events := strings.Split(out, "\n")
+	if len(events) < 3 {
+		t.Fatalf("Missing expected event")
+	}
+
+	oomEvent := strings.Fields(events[len(events)-3])
+	dieEvent := strings.Fields(events[len(events)-2])
+
+	if oomEvent[len(oomEvent)-1] != "oom" {
+		t.Fatalf("event should be oom, not %#v", oomEvent)
+	}
+	if dieEvent[len(dieEvent)-1] != "die" {
+		t.Fatalf("event should be die, not %#v", dieEvent)
+	}
+
+	logDone("events - OOM killed container logs oom")
+}
+
 func TestEventsLimit(t *testing.T) {
 	for i := 0; i < 30; i++ {
 		cmd(t, "run", "busybox", "echo", strconv.Itoa(i))
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForHealthStatus polls the health status of the container until it is
+// the expected one.
+func waitForHealthStatus(name, expected string, timeout time.Duration) error {
+	var status string
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		out, err := inspectField(name, "State.Health.Status")
+		if err != nil {
+			return err
+		}
+		if status = out; status == expected {
+			return nil
+		}
+	}
+	return fmt.Errorf("health status of %s is %q after %s, expected %q", name, status, timeout, expected)
+}
+
+func TestHealthRunCmd(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "health_run",
+		"--health-cmd", "cat /status", "--health-interval", "1s", "--health-retries", "2",
+		"busybox", "sh", "-c", "echo ok > /status && top")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	if err := waitForHealthStatus("health_run", "healthy", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, err = dockerCmd(t, "ps", "--filter", "name=health_run")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "(healthy)") {
+		t.Fatalf("Expected the health status in the output of ps: %s", out)
+	}
+
+	if out, _, err = dockerCmd(t, "exec", "health_run", "rm", "/status"); err != nil {
+		t.Fatal(out, err)
+	}
+	if err := waitForHealthStatus("health_run", "unhealthy", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := inspectFieldJSON("health_run", "State.Health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health struct {
+		FailingStreak int
+		Log           []struct {
+			ExitCode int
+			Output   string
+		}
+	}
+	if err := json.Unmarshal([]byte(res), &health); err != nil {
+		t.Fatal(err)
+	}
+	if health.FailingStreak < 2 || len(health.Log) == 0 {
+		t.Fatalf("Expected at least 2 failed checks in the health status: %s", res)
+	}
+	if last := health.Log[len(health.Log)-1]; last.ExitCode == 0 || !strings.Contains(last.Output, "No such file") {
+		t.Fatalf("Expected the output of the failed check in the log: %s", res)
+	}
+
+	logDone("health - run with --health-cmd")
+}
+
+func TestHealthBuildAndOverride(t *testing.T) {
+	name := "testhealthbuild"
+	defer deleteImages(name)
+	defer deleteAllContainers()
+
+	_, err := buildImage(name,
+		`FROM busybox
+		HEALTHCHECK --interval=1s --timeout=5s --retries=1 CMD ["false"]`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := inspectFieldJSON(name, "Config.Healthcheck.Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != `["CMD","false"]` {
+		t.Fatalf("Unexpected healthcheck of the image: %s", res)
+	}
+
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "health_image", name, "top")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if err := waitForHealthStatus("health_image", "unhealthy", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// the command is overridden and the options are kept from the image
+	runCmd = exec.Command(dockerBinary, "run", "-d", "--name", "health_override", "--health-cmd", "true", name, "top")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if err := waitForHealthStatus("health_override", "healthy", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	logDone("health - HEALTHCHECK instruction and override")
+}
+
+func TestHealthEvents(t *testing.T) {
+	defer deleteAllContainers()
+
+	since := time.Now().Unix()
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "health_events",
+		"--health-cmd", "true", "--health-interval", "1s", "busybox", "top")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := stripTrailingCharacters(out)
+
+	if err := waitForHealthStatus("health_events", "healthy", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	eventsCmd := exec.Command(dockerBinary, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", time.Now().Unix()+1))
+	out, _, err = runCommandWithOutput(eventsCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, id) && strings.Contains(line, "health_status: healthy") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Missing health_status event for %s: %s", id, out)
+	}
+
+	logDone("health - health_status events")
+}
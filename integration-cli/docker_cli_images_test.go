@@ -63,3 +63,50 @@ func TestImagesOrderedByCreationDate(t *testing.T) {
 
 	logDone("images - ordering by creation date")
 }
+
+func TestImagesFilterLabel(t *testing.T) {
+	imageName1 := "images_filter_test1"
+	imageName2 := "images_filter_test2"
+	imageName3 := "images_filter_test3"
+	defer deleteImages(imageName1, imageName2, imageName3)
+	image1ID, err := buildImage(imageName1,
+		`FROM busybox
+		 LABEL match me`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image2ID, err := buildImage(imageName2,
+		`FROM busybox
+		 LABEL match="me too"`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image3ID, err := buildImage(imageName3,
+		`FROM busybox
+		 LABEL nomatch me`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(dockerBinary, "images", "--no-trunc", "-q", "-f", "label=match")
+	out, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	out = strings.TrimSpace(out)
+	if !strings.Contains(out, image1ID) || !strings.Contains(out, image2ID) || strings.Contains(out, image3ID) {
+		t.Fatalf("Expected images %s and %s but not %s, got %q", image1ID, image2ID, image3ID, out)
+	}
+
+	cmd = exec.Command(dockerBinary, "images", "--no-trunc", "-q", "-f", "label=match=me too")
+	if out, _, err = runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if out = strings.TrimSpace(out); out != image2ID {
+		t.Fatalf("Expected image %s, got %q", image2ID, out)
+	}
+
+	logDone("images - filter label")
+}
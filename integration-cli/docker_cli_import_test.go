@@ -28,3 +28,34 @@ func TestImportDisplay(t *testing.T) {
 
 	logDone("import - cirros was imported and display is fine")
 }
+
+func TestImportFileWithChange(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal("failed to create a container", out, err)
+	}
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	importCmd := exec.Command("bash", "-c",
+		fmt.Sprintf("%s export %s | %s import --change 'ENV DEBUG true' --change 'CMD [\"/bin/echo\", \"imported\"]' - repo/testimportchange:v1",
+			dockerBinary, cleanedContainerID, dockerBinary))
+	out, _, err = runCommandWithOutput(importCmd)
+	if err != nil {
+		t.Fatalf("failed to import image: %s, %v", out, err)
+	}
+	defer deleteImages("repo/testimportchange:v1")
+
+	imageID := stripTrailingCharacters(out)
+	if actual, err := inspectField(imageID, "Config.Env"); err != nil || actual != "[DEBUG=true]" {
+		t.Fatalf("expected Config.Env to be [DEBUG=true], got %q (%v)", actual, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "--rm", "repo/testimportchange:v1")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil || !strings.Contains(out, "imported") {
+		t.Fatalf("expected imported CMD to run, got %q (%v)", out, err)
+	}
+
+	logDone("import - import with --change")
+}
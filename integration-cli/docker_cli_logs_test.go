@@ -335,3 +335,66 @@ func TestLogsFollowSlowStdoutConsumer(t *testing.T) {
 
 	logDone("logs - follow slow consumer")
 }
+
+func TestLogsNoneLogDriver(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--log-driver=none", "busybox", "sh", "-c", "echo hello")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatalf("run failed with errors: %s, %v", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+	exec.Command(dockerBinary, "wait", cleanedContainerID).Run()
+
+	logsCmd := exec.Command(dockerBinary, "logs", cleanedContainerID)
+	out, _, err = runCommandWithOutput(logsCmd)
+	if err == nil {
+		t.Fatalf("logs should fail for a container with the none logging driver, got: %s", out)
+	}
+	if !strings.Contains(out, "\"logs\" endpoint is supported only for \"json-file\" logging driver") {
+		t.Fatalf("unexpected error output: %s", out)
+	}
+
+	logDone("logs - logs fail for container with none logging driver")
+}
+
+func TestLogsRotatedJSONFile(t *testing.T) {
+	testLen := 2000
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--log-opt", "max-size=10k", "--log-opt", "max-file=100", "busybox", "sh", "-c", fmt.Sprintf("for i in $(seq 1 %d); do echo line$i; done", testLen))
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatalf("run failed with errors: %s, %v", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+	exec.Command(dockerBinary, "wait", cleanedContainerID).Run()
+
+	logsCmd := exec.Command(dockerBinary, "logs", cleanedContainerID)
+	out, _, _, err = runCommandWithStdoutStderr(logsCmd)
+	if err != nil {
+		t.Fatalf("failed to log container: %s, %v", out, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != testLen {
+		t.Fatalf("Expected %d log lines across the rotated files, got %d", testLen, len(lines))
+	}
+	for i, l := range lines {
+		if expected := fmt.Sprintf("line%d", i+1); l != expected {
+			t.Fatalf("Expected line %q, got %q", expected, l)
+		}
+	}
+
+	logsCmd = exec.Command(dockerBinary, "logs", "--tail", "1500", cleanedContainerID)
+	out, _, _, err = runCommandWithStdoutStderr(logsCmd)
+	if err != nil {
+		t.Fatalf("failed to log container: %s, %v", out, err)
+	}
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1500 || lines[0] != "line501" || lines[1499] != "line2000" {
+		t.Fatalf("Unexpected tail across the rotated files: %d lines, first %q", len(lines), lines[0])
+	}
+
+	logDone("logs - logs read across rotated json-file logs")
+}
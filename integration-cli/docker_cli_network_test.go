@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func deleteNetwork(name string) error {
+	return exec.Command(dockerBinary, "network", "rm", name).Run()
+}
+
+func TestNetworkCreateLsRm(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "network", "create", "--subnet", "10.213.0.0/24", "testnet")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "network", "ls", "--no-trunc")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	for _, expected := range []string{id, "testnet", "10.213.0.0/24", "bridge", "host", "none"} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %q in the network list, got %s", expected, out)
+		}
+	}
+
+	runCmd = exec.Command(dockerBinary, "network", "create", "testnet")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Creating a network with an existing name should fail, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "network", "rm", "testnet")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "network", "ls", "-q", "--no-trunc")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.Contains(out, id) {
+		t.Fatalf("Network %s should have been removed, got %s", id, out)
+	}
+
+	logDone("network - create, ls and rm")
+}
+
+func TestNetworkRmPredefined(t *testing.T) {
+	for _, name := range []string{"bridge", "host", "none"} {
+		runCmd := exec.Command(dockerBinary, "network", "rm", name)
+		if out, _, err := runCommandWithOutput(runCmd); err == nil {
+			t.Fatalf("Removing the %s network should fail, got %s", name, out)
+		}
+	}
+
+	logDone("network - predefined networks cannot be removed")
+}
+
+func TestNetworkRmWithRunningContainer(t *testing.T) {
+	defer deleteNetwork("testnet")
+	defer deleteAllContainers()
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "testnet")); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "run", "-d", "--net", "testnet", "busybox", "top")); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd := exec.Command(dockerBinary, "network", "rm", "testnet")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil || !strings.Contains(out, "running containers") {
+		t.Fatalf("Removing a network with running containers should fail, got %s", out)
+	}
+
+	logDone("network - rm fails with running containers")
+}
+
+func TestNetworkRunResolveByName(t *testing.T) {
+	defer deleteNetwork("testnet")
+	defer deleteAllContainers()
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "testnet")); err != nil {
+		t.Fatal(out, err)
+	}
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "first", "--net", "testnet", "busybox", "top")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "--rm", "--net", "testnet", "busybox", "ping", "-c", "1", "-W", "1", "first")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatalf("Containers on the same network should resolve each other by name: %s, %v", out, err)
+	}
+
+	logDone("network - run with --net and resolve containers by name")
+}
+
+func TestNetworkIsolation(t *testing.T) {
+	defer deleteNetwork("testnet")
+	defer deleteAllContainers()
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "testnet")); err != nil {
+		t.Fatal(out, err)
+	}
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "isolated", "--net", "testnet", "busybox", "top")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	ip, err := inspectField("isolated", "NetworkSettings.IPAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "--rm", "busybox", "ping", "-c", "1", "-W", "1", ip)
+	if out, _, err := runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Containers on the default bridge should not reach containers on other networks, got %s", out)
+	}
+
+	logDone("network - containers are isolated from other networks")
+}
+
+func TestNetworkConnectDisconnect(t *testing.T) {
+	defer deleteNetwork("testnet")
+	defer deleteAllContainers()
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "testnet")); err != nil {
+		t.Fatal(out, err)
+	}
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "connected", "busybox", "top")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "network", "connect", "testnet", "connected")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	ip, err := inspectField("connected", "NetworkSettings.Networks.testnet.IPAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip == "" {
+		t.Fatal("Expected an address on the connected network")
+	}
+
+	runCmd = exec.Command(dockerBinary, "exec", "connected", "ip", "addr", "show", "eth1")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, ip) {
+		t.Fatalf("Expected %s on eth1, got %s", ip, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "network", "disconnect", "testnet", "connected")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	networks, err := inspectField("connected", "NetworkSettings.Networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(networks, "testnet") {
+		t.Fatalf("Expected the container to be disconnected from testnet, got %s", networks)
+	}
+
+	runCmd = exec.Command(dockerBinary, "exec", "connected", "ip", "link", "show", "eth1")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Expected eth1 to be removed, got %s", out)
+	}
+
+	logDone("network - connect and disconnect a running container")
+}
@@ -474,3 +474,146 @@ func TestPsListContainersFilterExited(t *testing.T) {
 	}
 	logDone("ps - test ps filter exited")
 }
+
+func TestPsListContainersFilterIDPrefix(t *testing.T) {
+	defer deleteAllContainers()
+	runCmd := exec.Command(dockerBinary, "run", "-d", "busybox")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	firstID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "busybox", "sh", "-c", "sleep 360")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	// the id filter matches a prefix of the id, not an arbitrary substring
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--filter=id="+firstID[:6])
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if containerOut := strings.TrimSpace(out); containerOut != firstID[:12] {
+		t.Fatalf("Expected id %s, got %s for id prefix filter, output: %q", firstID[:12], containerOut, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--filter=id="+firstID[1:12])
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if containerOut := strings.TrimSpace(out); containerOut != "" {
+		t.Fatalf("Expected no container for a non prefix id filter, got %q", containerOut)
+	}
+
+	logDone("ps - test ps filter id prefix")
+}
+
+func TestPsListContainersFilterLabel(t *testing.T) {
+	defer deleteAllContainers()
+	runCmd := exec.Command(dockerBinary, "run", "-d", "-l", "match=me", "-l", "second=tag", "busybox")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	firstID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "-l", "match=me too", "busybox")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	secondID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "-l", "nomatch=me", "busybox")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	// filter containers by exact label value
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=label=match=me")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if containerOut := strings.TrimSpace(out); containerOut != firstID {
+		t.Fatalf("Expected id %s, got %s for label filter, output: %q", firstID, containerOut, out)
+	}
+
+	// filter containers by several labels, all of them must match
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=label=match", "--filter=label=second=tag")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if containerOut := strings.TrimSpace(out); containerOut != firstID {
+		t.Fatalf("Expected id %s, got %s for label filter, output: %q", firstID, containerOut, out)
+	}
+
+	// filter containers by label key only
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=label=match")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	ids := strings.Split(strings.TrimSpace(out), "\n")
+	if len(ids) != 2 || ids[0] != secondID || ids[1] != firstID {
+		t.Fatalf("Expected ids %s and %s for label filter, output: %q", secondID, firstID, out)
+	}
+
+	logDone("ps - test ps filter label")
+}
+
+func TestPsListContainersFilterAncestor(t *testing.T) {
+	defer deleteAllContainers()
+	defer deleteImages("testpsancestor")
+	if _, err := buildImage("testpsancestor",
+		`FROM busybox
+		LABEL built=yes`,
+		true); err != nil {
+		t.Fatal(err)
+	}
+
+	runCmd := exec.Command(dockerBinary, "run", "-d", "busybox")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	firstID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "testpsancestor")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	secondID := stripTrailingCharacters(out)
+
+	// busybox is an ancestor of both containers
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=ancestor=busybox")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	ids := strings.Split(strings.TrimSpace(out), "\n")
+	if len(ids) != 2 || ids[0] != secondID || ids[1] != firstID {
+		t.Fatalf("Expected ids %s and %s for ancestor filter, output: %q", secondID, firstID, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=ancestor=testpsancestor")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if containerOut := strings.TrimSpace(out); containerOut != secondID {
+		t.Fatalf("Expected id %s, got %s for ancestor filter, output: %q", secondID, containerOut, out)
+	}
+
+	logDone("ps - test ps filter ancestor")
+}
+
+func TestPsListContainersFilterInvalid(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "ps", "-a", "--filter=foo=bar")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil || !strings.Contains(out, "Invalid filter 'foo'") {
+		t.Fatalf("Expected an error for an invalid filter, got %v, output: %q", err, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "--filter=status=foo")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil || !strings.Contains(out, "Unrecognised filter value for status") {
+		t.Fatalf("Expected an error for an invalid status, got %v, output: %q", err, out)
+	}
+
+	logDone("ps - test ps invalid filters")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRenameStoppedContainer(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "first_name", "-d", "busybox", "sh")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "wait", cleanedContainerID)
+	out, _, err = runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "rename", "first_name", "new_name")
+	out, _, err = runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	name, err := inspectField(cleanedContainerID, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/new_name" {
+		t.Fatal("Failed to rename container ", name)
+	}
+
+	logDone("rename - stopped container")
+}
+
+func TestRenameRunningContainer(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "first_name", "-d", "busybox", "sh")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	runCmd = exec.Command(dockerBinary, "rename", "first_name", "new_name")
+	out, _, err = runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	name, err := inspectField(cleanedContainerID, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/new_name" {
+		t.Fatal("Failed to rename container ")
+	}
+
+	logDone("rename - running container")
+}
+
+func TestRenameCheckNames(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "first_name", "-d", "busybox", "sh")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "rename", "first_name", "new_name")
+	out, _, err = runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	name, err := inspectField("new_name", "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/new_name" {
+		t.Fatal("Failed to rename container ")
+	}
+
+	if _, err := inspectField("first_name", "Name"); err == nil {
+		t.Fatal("The old name should no longer refer to the container")
+	}
+
+	logDone("rename - old name released")
+}
+
+func TestRenameConflictingName(t *testing.T) {
+	defer deleteAllContainers()
+
+	for _, name := range []string{"first_name", "second_name"} {
+		runCmd := exec.Command(dockerBinary, "run", "--name", name, "-d", "busybox", "sh")
+		if out, _, err := runCommandWithOutput(runCmd); err != nil {
+			t.Fatal(out, err)
+		}
+	}
+
+	runCmd := exec.Command(dockerBinary, "rename", "first_name", "second_name")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err == nil {
+		t.Fatalf("Renaming to an existing name should fail: %s", out)
+	}
+	if !strings.Contains(out, "Conflict") {
+		t.Fatalf("Expected a conflict error, got: %s", out)
+	}
+
+	name, err := inspectField("first_name", "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/first_name" {
+		t.Fatalf("Container should have kept its name, got %s", name)
+	}
+
+	logDone("rename - conflicting name is refused")
+}
+
+func TestRenameKeepsLinks(t *testing.T) {
+	defer deleteAllContainers()
+
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "child", "busybox", "sleep", "10"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "parent", "--link", "child:alias", "busybox", "sleep", "10"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	if out, _, err := dockerCmd(t, "rename", "child", "new_child"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "rename", "parent", "new_parent"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	links, err := inspectFieldJSON("new_parent", "HostConfig.Links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["/new_child:/new_parent/alias"]`; links != expected {
+		t.Fatalf("Expected links %s, got %s", expected, links)
+	}
+
+	logDone("rename - links are kept")
+}
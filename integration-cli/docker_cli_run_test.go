@@ -354,7 +354,7 @@ func TestRunWithVolumesFromExited(t *testing.T) {
 
 // Regression test for #4830
 func TestRunWithRelativePath(t *testing.T) {
-	runCmd := exec.Command(dockerBinary, "run", "-v", "tmp:/other-tmp", "busybox", "true")
+	runCmd := exec.Command(dockerBinary, "run", "-v", "./tmp:/other-tmp", "busybox", "true")
 	if _, _, _, err := runCommandWithStdoutStderr(runCmd); err == nil {
 		t.Fatalf("relative path should result in an error")
 	}
@@ -2503,3 +2503,212 @@ func TestRunAllowPortRangeThroughExpose(t *testing.T) {
 	}
 	logDone("run - allow port range through --expose flag")
 }
+
+func TestRunLabels(t *testing.T) {
+	defer deleteAllContainers()
+	defer deleteImages("testrunlabels")
+
+	labelFile := filepath.Join(os.TempDir(), "docker-test-run-labels")
+	writeFile(labelFile, "# a comment\nfromfile=yes\noverridden=file\n", t)
+	defer os.Remove(labelFile)
+
+	if _, err := buildImage("testrunlabels",
+		`FROM busybox
+		LABEL image=yes overridden=image`,
+		true); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(dockerBinary, "run", "--name", "labels", "-l", "cli=yes", "--label", "overridden=cli", "--label-file", labelFile, "testrunlabels", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	expected := map[string]string{
+		"image":      "yes",
+		"fromfile":   "yes",
+		"cli":        "yes",
+		"overridden": "cli",
+	}
+	for k, v := range expected {
+		label, err := inspectFieldMap("labels", "Config.Labels", k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if label != v {
+			t.Fatalf("Expected label %s to be %q, got %q", k, v, label)
+		}
+	}
+
+	// labels are kept when the container is committed
+	cmd = exec.Command(dockerBinary, "commit", "labels", "testrunlabelscommit")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+	defer deleteImages("testrunlabelscommit")
+	for k, v := range expected {
+		label, err := inspectFieldMap("testrunlabelscommit", "Config.Labels", k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if label != v {
+			t.Fatalf("Expected label %s of the committed image to be %q, got %q", k, v, label)
+		}
+	}
+
+	logDone("run - labels")
+}
+
+func TestRunReadonlyRootfs(t *testing.T) {
+	defer deleteAllContainers()
+
+	for _, path := range []string{"/file", "/etc/file", "/root/file"} {
+		cmd := exec.Command(dockerBinary, "run", "--read-only", "busybox", "touch", path)
+		if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Read-only file system") {
+			t.Fatalf("Expected writing %s to fail on a read only root filesystem, got %s", path, out)
+		}
+	}
+
+	cmd := exec.Command(dockerBinary, "run", "--read-only", "-v", "/data", "busybox", "touch", "/data/file")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatalf("Volumes should be writable with a read only root filesystem: %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--name", "readonly", "--read-only", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, err := inspectField("readonly", "HostConfig.ReadonlyRootfs"); err != nil || out != "true" {
+		t.Fatalf("Expected ReadonlyRootfs in the host config, got %s, %v", out, err)
+	}
+
+	logDone("run - read only root filesystem")
+}
+
+func TestRunSeccompProfiles(t *testing.T) {
+	defer deleteAllContainers()
+
+	cmd := exec.Command(dockerBinary, "run", "--security-opt", "seccomp=unconfined", "busybox", "grep", "^Seccomp:", "/proc/self/status")
+	if out, _, err := runCommandWithOutput(cmd); err != nil || !strings.Contains(out, "0") {
+		t.Fatalf("Expected no seccomp filter with seccomp=unconfined, got %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--privileged", "busybox", "sh", "-c", "mkdir /mnt/tmp && mount -t tmpfs none /mnt/tmp")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatalf("Expected privileged containers not to be filtered: %s, %v", out, err)
+	}
+
+	profile, err := ioutil.TempFile("", "seccomp-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(profile.Name())
+	if _, err := profile.WriteString(`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "mkdir", "action": "SCMP_ACT_ERRNO"}, {"name": "mkdirat", "action": "SCMP_ACT_ERRNO"}]}`); err != nil {
+		t.Fatal(err)
+	}
+	profile.Close()
+
+	// the native driver cannot install seccomp filters yet
+	cmd = exec.Command(dockerBinary, "run", "--security-opt", "seccomp="+profile.Name(), "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "seccomp profiles are not supported") {
+		t.Fatalf("Expected custom seccomp profiles to be refused, got %s", out)
+	}
+
+	invalid := filepath.Join(filepath.Dir(profile.Name()), "seccomp-invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte(`{"defaultAction": "SCMP_ACT_DENY"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(invalid)
+	cmd = exec.Command(dockerBinary, "run", "--security-opt", "seccomp="+invalid, "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Invalid seccomp action") {
+		t.Fatalf("Expected an error for an invalid seccomp profile, got %s", out)
+	}
+
+	logDone("run - seccomp profiles")
+}
+
+func TestRunTmpfsMounts(t *testing.T) {
+	defer deleteAllContainers()
+
+	cmd := exec.Command(dockerBinary, "run", "--name", "tmpfs", "--tmpfs", "/run", "--tmpfs", "/data:size=1m,mode=700", "busybox", "sh", "-c", "grep ' /run \\| /data ' /proc/mounts")
+	out, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected tmpfs mounts on /run and /data, got %s", out)
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "tmpfs" || !strings.Contains(fields[3], "nosuid,nodev,noexec") {
+			t.Fatalf("Expected a noexec, nosuid and nodev tmpfs mount, got %s", line)
+		}
+		if fields[1] == "/data" && (!strings.Contains(fields[3], "size=1024k") || !strings.Contains(fields[3], "mode=700")) {
+			t.Fatalf("Expected the options of the tmpfs mount on /data, got %s", line)
+		}
+	}
+
+	if out, err := inspectField("tmpfs", "HostConfig.Tmpfs"); err != nil || out != "map[/data:size=1m,mode=700 /run:]" {
+		t.Fatalf("Expected the tmpfs mounts in the host config, got %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--tmpfs", "/run:foo=bar", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Invalid tmpfs option") {
+		t.Fatalf("Expected an error for invalid tmpfs options, got %s", out)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--tmpfs", "/data", "-v", "/tmp:/data", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Duplicate mount point") {
+		t.Fatalf("Expected an error for a tmpfs mount on a volume, got %s", out)
+	}
+
+	logDone("run - tmpfs mounts")
+}
+
+func TestRunOOMKilled(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "oomkilled", "-m", "4m", "busybox", "sh", "-c", "x=a; while true; do x=$x$x$x$x; done")
+	if out, _, err := runCommandWithOutput(cmd); err == nil {
+		t.Fatalf("Expected the container to be OOM killed, got %s", out)
+	}
+	defer deleteAllContainers()
+
+	if out, err := inspectField("oomkilled", "State.OOMKilled"); err != nil || out != "true" {
+		t.Fatalf("Expected State.OOMKilled to be true, got %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "ps", "-a", "--filter", "name=oomkilled")
+	if out, _, err := runCommandWithOutput(cmd); err != nil || !strings.Contains(out, "(OOM killed)") {
+		t.Fatalf("Expected the OOM kill in the status of the container, got %s, %v", out, err)
+	}
+
+	logDone("run - OOM killed containers are reported")
+}
+
+func TestRunStopSignal(t *testing.T) {
+	defer deleteAllContainers()
+
+	cmd := exec.Command(dockerBinary, "run", "-d", "--name", "stopsignal", "--stop-signal", "SIGQUIT", "busybox", "sh", "-c", "trap 'exit 42' QUIT; while true; do sleep 1; done")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	if out, err := inspectField("stopsignal", "Config.StopSignal"); err != nil || out != "SIGQUIT" {
+		t.Fatalf("Expected the stop signal SIGQUIT, got %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "stop", "stopsignal")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, err := inspectField("stopsignal", "State.ExitCode"); err != nil || out != "42" {
+		t.Fatalf("Expected the container to exit with 42 on SIGQUIT, got %s, %v", out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--stop-signal", "SIGFOO", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Invalid signal: SIGFOO") {
+		t.Fatalf("Expected an error for an invalid stop signal, got %s", out)
+	}
+
+	logDone("run - stop signal")
+}
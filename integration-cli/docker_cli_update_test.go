@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateStoppedContainer(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "create", "--name", "update_stopped", "-c", "512", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "update", "-m", "64m", "update_stopped")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	memory, err := inspectField("update_stopped", "Config.Memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memory != "67108864" {
+		t.Fatalf("Expected a memory limit of 67108864, got %s", memory)
+	}
+
+	// the cpu shares were not given and must be left unchanged
+	shares, err := inspectField("update_stopped", "Config.CpuShares")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares != "512" {
+		t.Fatalf("Expected cpu shares of 512, got %s", shares)
+	}
+
+	logDone("update - stopped container")
+}
+
+func TestUpdateRunningContainer(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", "update_running", "-c", "512", "busybox", "top")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "update", "--cpu-shares", "256", "update_running")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	shares, err := inspectField("update_running", "Config.CpuShares")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares != "256" {
+		t.Fatalf("Expected cpu shares of 256, got %s", shares)
+	}
+
+	// check the value of the cgroup when the daemon uses the default layout
+	if data, err := ioutil.ReadFile(filepath.Join("/sys/fs/cgroup/cpu/docker", id, "cpu.shares")); err == nil {
+		if strings.TrimSpace(string(data)) != "256" {
+			t.Fatalf("Expected the cpu.shares cgroup to be 256, got %s", data)
+		}
+	}
+
+	// the new value is kept across restarts
+	runCmd = exec.Command(dockerBinary, "restart", "update_running")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if shares, err = inspectField("update_running", "Config.CpuShares"); err != nil {
+		t.Fatal(err)
+	}
+	if shares != "256" {
+		t.Fatalf("Expected cpu shares of 256 after restart, got %s", shares)
+	}
+
+	logDone("update - running container")
+}
+
+func TestUpdateInvalid(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "create", "--name", "update_invalid", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "update", "update_invalid")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Expected an error without any flag, got %q", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "update", "-m", "1m", "update_invalid")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil || !strings.Contains(out, "Minimum memory limit allowed is 4MB") {
+		t.Fatalf("Expected an error for a too low memory limit, got %v: %q", err, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "update", "-c", "512", "update_unknown")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Expected an error for an unknown container, got %q", out)
+	}
+
+	logDone("update - invalid")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testVolumeDriver = "test-external-volume-driver"
+
+// volumeDriverPlugin is a volume plugin keeping volumes in directories of a
+// temporary directory, which counts the calls made to it.
+type volumeDriverPlugin struct {
+	root     string
+	listener net.Listener
+	calls    map[string]int
+	sync.Mutex
+}
+
+func startVolumeDriverPlugin(t *testing.T) *volumeDriverPlugin {
+	root, err := ioutil.TempDir("", "docker-volume-driver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll("/run/docker/plugins", 0755); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", filepath.Join("/run/docker/plugins", testVolumeDriver+".sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &volumeDriverPlugin{root: root, listener: l, calls: make(map[string]int)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]string{"Implements": {"VolumeDriver"}})
+	})
+	for _, method := range []string{"Create", "Remove", "Path", "Mount", "Unmount"} {
+		method := method
+		mux.HandleFunc("/VolumeDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			var req struct{ Name string }
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			p.Lock()
+			p.calls[method]++
+			p.Unlock()
+
+			var (
+				path = filepath.Join(p.root, req.Name)
+				ret  = map[string]string{}
+			)
+			switch method {
+			case "Create":
+				if err := os.MkdirAll(path, 0755); err != nil {
+					ret["Err"] = err.Error()
+				}
+			case "Remove":
+				if err := os.RemoveAll(path); err != nil {
+					ret["Err"] = err.Error()
+				}
+			case "Path", "Mount":
+				ret["Mountpoint"] = path
+			}
+			json.NewEncoder(w).Encode(ret)
+		})
+	}
+	go http.Serve(l, mux)
+	return p
+}
+
+func (p *volumeDriverPlugin) Close() {
+	p.listener.Close()
+	os.RemoveAll(p.root)
+}
+
+func (p *volumeDriverPlugin) Calls(method string) int {
+	p.Lock()
+	defer p.Unlock()
+	return p.calls[method]
+}
+
+func TestVolumeDriverNamedVolume(t *testing.T) {
+	p := startVolumeDriverPlugin(t)
+	defer p.Close()
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "driver_user", "--volume-driver", testVolumeDriver, "-v", "external:/data", "busybox", "sh", "-c", "echo hello > /data/file")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(p.root, "external", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello\n" {
+		t.Fatalf("Expected the file written by the container in the volume of the driver, got %q", content)
+	}
+	if created, mounted := p.Calls("Create"), p.Calls("Mount"); created != 1 || mounted != 1 {
+		t.Fatalf("Expected the volume to be created and mounted once, got %d and %d", created, mounted)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "inspect", "-f", "{{.Driver}}", "external")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != testVolumeDriver {
+		t.Fatalf("Expected the volume on %s, got %s", testVolumeDriver, out)
+	}
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "rm", "driver_user")); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "volume", "rm", "external")); err != nil {
+		t.Fatal(out, err)
+	}
+	if removed := p.Calls("Remove"); removed != 1 {
+		t.Fatalf("Expected the volume to be removed from the driver once, got %d", removed)
+	}
+	if _, err := os.Stat(filepath.Join(p.root, "external")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the directory of the volume to be removed, got %v", err)
+	}
+
+	logDone("volume driver - named volume on an external driver")
+}
+
+func TestVolumeDriverNotFound(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--volume-driver", "no-such-driver", "-v", "external:/data", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err == nil || !strings.Contains(out, "No such volume driver") {
+		t.Fatalf("Expected an error for a missing volume driver, got %s", out)
+	}
+
+	logDone("volume driver - missing driver")
+}
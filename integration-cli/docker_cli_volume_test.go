@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func deleteVolume(name string) error {
+	return exec.Command(dockerBinary, "volume", "rm", name).Run()
+}
+
+func hasLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVolumeCreateLsInspectRm(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "volume", "create", "--name", "testvolume")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if name := stripTrailingCharacters(out); name != "testvolume" {
+		t.Fatalf("Expected the volume name to be printed, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "create", "--name", "testvolume")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Creating a volume with an existing name should fail, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "ls", "-q")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "testvolume\n") {
+		t.Fatalf("Expected testvolume in the volume list, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "inspect", "-f", "{{.Driver}} {{.Mountpoint}}", "testvolume")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.HasPrefix(out, "local /") {
+		t.Fatalf("Expected the driver and mountpoint of the volume, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "rm", "testvolume")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "inspect", "testvolume")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Volume testvolume should have been removed, got %s", out)
+	}
+
+	logDone("volume - create, ls, inspect and rm")
+}
+
+func TestVolumeNamedShared(t *testing.T) {
+	defer deleteVolume("testvolume")
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "-v", "testvolume:/data", "busybox", "sh", "-c", "echo hello > /data/file")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "-v", "testvolume:/other", "busybox", "cat", "/other/file")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if out != "hello\n" {
+		t.Fatalf("Expected the content written by the first container, got %q", out)
+	}
+
+	logDone("volume - named volume shared between containers")
+}
+
+func TestVolumeNamedCopiesImageContent(t *testing.T) {
+	defer deleteVolume("testvolume")
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "-v", "testvolume:/etc", "busybox", "true")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "-v", "testvolume:/data", "busybox", "ls", "/data")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "passwd") {
+		t.Fatalf("Expected the content of /etc in the volume, got %s", out)
+	}
+
+	logDone("volume - named volume filled with the content of the image")
+}
+
+func TestVolumeNamedKeptOnRm(t *testing.T) {
+	defer deleteVolume("testvolume")
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "volume_user", "-v", "testvolume:/data", "busybox", "true")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "rm", "testvolume")
+	if out, _, err := runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Removing a volume used by a container should fail, got %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "rm", "-v", "volume_user")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "inspect", "testvolume")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatalf("Named volumes should be kept when their containers are removed: %s, %v", out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "rm", "testvolume")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	logDone("volume - named volume kept when its container is removed")
+}
+
+func TestVolumePrune(t *testing.T) {
+	defer deleteVolume("usedvolume")
+	defer deleteVolume("unusedvolume")
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "--name", "volume_user", "-v", "usedvolume:/data", "busybox", "true")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	runCmd = exec.Command(dockerBinary, "run", "-v", "unusedvolume:/data", "busybox", "sh", "-c", "dd if=/dev/zero of=/data/file bs=1024 count=1024")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := stripTrailingCharacters(out)
+	deleteContainer(id)
+
+	runCmd = exec.Command(dockerBinary, "volume", "prune", "--dry-run")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	lines := strings.Split(out, "\n")
+	if !hasLine(lines, "unusedvolume") || hasLine(lines, "usedvolume") {
+		t.Fatalf("Expected only unusedvolume to be listed, got %s", out)
+	}
+	// Volumes left by other tests may be counted too
+	if !strings.Contains(out, "Total space which would be reclaimed: ") || strings.Contains(out, "reclaimed: 0 B") {
+		t.Fatalf("Expected the size of unusedvolume to be reported, got %s", out)
+	}
+	runCmd = exec.Command(dockerBinary, "volume", "inspect", "unusedvolume")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatalf("A dry run should not remove volumes: %s, %v", out, err)
+	}
+
+	runCmd = exec.Command(dockerBinary, "volume", "prune")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	runCmd = exec.Command(dockerBinary, "volume", "ls", "-q")
+	if out, _, err = runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(out, err)
+	}
+	if lines = strings.Split(out, "\n"); hasLine(lines, "unusedvolume") || !hasLine(lines, "usedvolume") {
+		t.Fatalf("Expected unusedvolume to be removed and usedvolume kept, got %s", out)
+	}
+
+	logDone("volume - prune the volumes not used by any container")
+}
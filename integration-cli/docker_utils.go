@@ -555,10 +555,17 @@ func getContainerState(t *testing.T, id string) (int, bool, error) {
 }
 
 func buildImageWithOut(name, dockerfile string, useCache bool) (string, string, error) {
+	return buildImageWithFlags(name, dockerfile, useCache)
+}
+
+// buildImageWithFlags builds the dockerfile read from stdin, passing the
+// extra flags to docker build.
+func buildImageWithFlags(name, dockerfile string, useCache bool, flags ...string) (string, string, error) {
 	args := []string{"build", "-t", name}
 	if !useCache {
 		args = append(args, "--no-cache")
 	}
+	args = append(args, flags...)
 	args = append(args, "-")
 	buildCmd := exec.Command(dockerBinary, args...)
 	buildCmd.Stdin = strings.NewReader(dockerfile)
@@ -11,6 +11,20 @@ import (
 Read in a line delimited file with environment variables enumerated
 */
 func ParseEnvFile(filename string) ([]string, error) {
+	return parseKeyValueFile(filename, os.Getenv)
+}
+
+/*
+Read in a line delimited file with labels enumerated, a label without a
+value is empty
+*/
+func ParseLabelFile(filename string) ([]string, error) {
+	return parseKeyValueFile(filename, func(string) string { return "" })
+}
+
+// parseKeyValueFile reads the key=value lines of filename, the value of a
+// line with only a key is given by emptyFn
+func parseKeyValueFile(filename string, emptyFn func(string) string) ([]string, error) {
 	fh, err := os.Open(filename)
 	if err != nil {
 		return []string{}, err
@@ -36,7 +50,7 @@ func ParseEnvFile(filename string) ([]string, error) {
 				lines = append(lines, fmt.Sprintf("%s=%s", variable, data[1]))
 			} else {
 				// if only a pass-through variable is given, clean it up.
-				lines = append(lines, fmt.Sprintf("%s=%s", strings.TrimSpace(line), os.Getenv(line)))
+				lines = append(lines, fmt.Sprintf("%s=%s", strings.TrimSpace(line), emptyFn(line)))
 			}
 		}
 	}
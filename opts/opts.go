@@ -43,6 +43,10 @@ func MirrorListVar(values *[]string, names []string, usage string) {
 	flag.Var(newListOptsRef(values, ValidateMirror), names, usage)
 }
 
+func LogOptsVar(values map[string]string, names []string, usage string) {
+	flag.Var(NewMapOpts(values, ValidateLogOpt), names, usage)
+}
+
 // ListOpts type
 type ListOpts struct {
 	values    *[]string
@@ -121,6 +125,51 @@ func (opts *ListOpts) Len() int {
 	return len((*opts.values))
 }
 
+// MapOpts type holds key=value pairs, later values for a key override
+// earlier ones.
+type MapOpts struct {
+	values    map[string]string
+	validator ValidatorFctType
+}
+
+func NewMapOpts(values map[string]string, validator ValidatorFctType) *MapOpts {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	return &MapOpts{
+		values:    values,
+		validator: validator,
+	}
+}
+
+func (opts *MapOpts) String() string {
+	return fmt.Sprintf("%v", map[string]string((opts.values)))
+}
+
+// Set validates if needed the input value and stores it in the map,
+// splitting it on the first '='.
+func (opts *MapOpts) Set(value string) error {
+	if opts.validator != nil {
+		v, err := opts.validator(value)
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	vals := strings.SplitN(value, "=", 2)
+	if len(vals) == 1 {
+		(opts.values)[vals[0]] = ""
+	} else {
+		(opts.values)[vals[0]] = vals[1]
+	}
+	return nil
+}
+
+// GetAll returns the values' map.
+func (opts *MapOpts) GetAll() map[string]string {
+	return opts.values
+}
+
 // Validators
 type ValidatorFctType func(val string) (string, error)
 
@@ -141,6 +190,14 @@ func ValidateLink(val string) (string, error) {
 	return val, nil
 }
 
+// ValidateLogOpt checks that the logging option is of the form key=value.
+func ValidateLogOpt(val string) (string, error) {
+	if vals := strings.SplitN(val, "=", 2); len(vals) != 2 || vals[0] == "" {
+		return "", fmt.Errorf("invalid logging option: %s (expected key=value)", val)
+	}
+	return val, nil
+}
+
 func ValidatePath(val string) (string, error) {
 	var containerPath string
 
@@ -171,6 +228,15 @@ func ValidateEnv(val string) (string, error) {
 	return fmt.Sprintf("%s=%s", val, os.Getenv(val)), nil
 }
 
+// ValidateLabel stores a label given without a value as an empty label,
+// unlike environment variables it is not read from the environment.
+func ValidateLabel(val string) (string, error) {
+	if strings.Contains(val, "=") {
+		return val, nil
+	}
+	return val + "=", nil
+}
+
 func ValidateIPAddress(val string) (string, error) {
 	var ip = net.ParseIP(strings.TrimSpace(val))
 	if ip != nil {
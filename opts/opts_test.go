@@ -1,6 +1,8 @@
 package opts
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 )
 
@@ -27,12 +29,74 @@ func TestValidateIPAddress(t *testing.T) {
 
 }
 
+func TestValidateLabel(t *testing.T) {
+	os.Setenv("DOCKER_TEST_LABEL", "fromenv")
+	defer os.Unsetenv("DOCKER_TEST_LABEL")
+
+	if ret, err := ValidateLabel("key=value"); err != nil || ret != "key=value" {
+		t.Fatalf("ValidateLabel(`key=value`) got %s %s", ret, err)
+	}
+	if ret, err := ValidateLabel("DOCKER_TEST_LABEL"); err != nil || ret != "DOCKER_TEST_LABEL=" {
+		t.Fatalf("ValidateLabel(`DOCKER_TEST_LABEL`) got %s %s", ret, err)
+	}
+}
+
+func TestParseLabelFile(t *testing.T) {
+	os.Setenv("DOCKER_TEST_LABEL", "fromenv")
+	defer os.Unsetenv("DOCKER_TEST_LABEL")
+
+	f, err := ioutil.TempFile("", "labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("# comment\nkey=value\nDOCKER_TEST_LABEL\n")
+	f.Close()
+
+	labels, err := ParseLabelFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || labels[0] != "key=value" || labels[1] != "DOCKER_TEST_LABEL=" {
+		t.Fatalf("ParseLabelFile got %v", labels)
+	}
+}
+
 func TestListOpts(t *testing.T) {
 	o := NewListOpts(nil)
 	o.Set("foo")
 	o.String()
 }
 
+func TestMapOpts(t *testing.T) {
+	tmpMap := make(map[string]string)
+	o := NewMapOpts(tmpMap, ValidateLogOpt)
+	if err := o.Set("max-size=1"); err != nil {
+		t.Fatal(err)
+	}
+	if o.String() != "map[max-size:1]" {
+		t.Errorf("%s != [map[max-size:1]", o.String())
+	}
+	if err := o.Set("max-file=2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(tmpMap) != 2 {
+		t.Errorf("map length %d != 2", len(tmpMap))
+	}
+	if tmpMap["max-file"] != "2" {
+		t.Errorf("max-file = %s != 2", tmpMap["max-file"])
+	}
+	if tmpMap["max-size"] != "1" {
+		t.Errorf("max-size = %s != 1", tmpMap["max-size"])
+	}
+	if o.Set("dummy-val=") != nil {
+		t.Errorf("dummy-val= should be a valid option")
+	}
+	if o.Set("dummy") == nil {
+		t.Errorf("dummy should not be a valid option")
+	}
+}
+
 func TestValidateDnsSearch(t *testing.T) {
 	valid := []string{
 		`.`,
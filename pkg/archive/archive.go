@@ -412,7 +412,7 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 				}
 
 				// Rename the base resource
-				if options.Name != "" && filePath == srcPath+"/"+filepath.Base(relFilePath) {
+				if options.Name != "" && filePath == filepath.Join(srcPath, filepath.Base(relFilePath)) {
 					renamedRelFilePath = relFilePath
 				}
 				// Set this to make sure the items underneath also get renamed
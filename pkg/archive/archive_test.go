@@ -403,3 +403,67 @@ func BenchmarkTarUntarWithLinks(b *testing.B) {
 		os.RemoveAll(target)
 	}
 }
+
+func TestChownArchive(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755, Uid: 0, Gid: 0},
+		{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0644, Uid: 1000, Gid: 50, Size: 5},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.Bytes()
+
+	arch, err := ChownArchive(bytes.NewReader(content), func(uid, gid int) (int, int, error) {
+		return uid + 100000, gid + 200000, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arch.Close()
+
+	tr := tar.NewReader(arch)
+	expected := [][2]int{{100000, 200000}, {101000, 200050}}
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			if i != len(expected) {
+				t.Fatalf("Expected %d files, got %d", len(expected), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != expected[i][0] || hdr.Gid != expected[i][1] {
+			t.Fatalf("Expected %s to be owned by %v, got %d:%d", hdr.Name, expected[i], hdr.Uid, hdr.Gid)
+		}
+		if hdr.Name == "dir/file" {
+			content, err := ioutil.ReadAll(tr)
+			if err != nil || string(content) != "hello" {
+				t.Fatalf("Expected the content of dir/file to be kept, got %q, %v", content, err)
+			}
+		}
+	}
+
+	arch, err = ChownArchive(bytes.NewReader(content), func(uid, gid int) (int, int, error) {
+		return -1, -1, fmt.Errorf("unmapped")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(arch); err == nil {
+		t.Fatal("Expected the error of chown to be returned")
+	}
+}
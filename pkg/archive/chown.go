@@ -0,0 +1,47 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+)
+
+// ChownArchive returns the archive, uncompressed, with the owner of each of
+// its files replaced by the one returned by chown.
+func ChownArchive(arch ArchiveReader, chown func(uid, gid int) (int, int, error)) (Archive, error) {
+	decompressed, err := DecompressStream(arch)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeR, pipeW := io.Pipe()
+	go func() {
+		err := chownTar(decompressed, pipeW, chown)
+		decompressed.Close()
+		pipeW.CloseWithError(err)
+	}()
+	return pipeR, nil
+}
+
+func chownTar(r io.Reader, w io.Writer, chown func(uid, gid int) (int, int, error)) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Uid, hdr.Gid, err = chown(hdr.Uid, hdr.Gid); err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package chrootarchive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/reexec"
+)
+
+const untarCommandName = "docker-untar"
+
+func init() {
+	reexec.Register(untarCommandName, untar)
+}
+
+// untar is the reexec function extracting the archive read from stdin into
+// the directory it chroots into.
+func untar() {
+	runtime.LockOSThread()
+
+	if len(os.Args) != 3 {
+		fatal(fmt.Errorf("Usage: %s DEST OPTIONS", untarCommandName))
+	}
+	var options *archive.TarOptions
+	if err := json.Unmarshal([]byte(os.Args[2]), &options); err != nil {
+		fatal(err)
+	}
+	if err := syscall.Chroot(os.Args[1]); err != nil {
+		fatal(err)
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		fatal(err)
+	}
+	if err := archive.Untar(os.Stdin, "/", options); err != nil {
+		fatal(err)
+	}
+	// the end of the archive may be padded
+	io.Copy(ioutil.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+func fatal(err error) {
+	fmt.Fprint(os.Stderr, err)
+	os.Exit(1)
+}
+
+// Untar extracts the archive into dest like archive.Untar, from a process
+// chrooted into dest: the files of the archive and the symlinks found in
+// dest can't make it write outside of dest.
+func Untar(tarArchive io.Reader, dest string, options *archive.TarOptions) error {
+	if tarArchive == nil {
+		return fmt.Errorf("Empty archive")
+	}
+	if options == nil {
+		options = &archive.TarOptions{}
+	}
+	if options.Excludes == nil {
+		options.Excludes = []string{}
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	dest = filepath.Clean(dest)
+	if _, err := os.Stat(dest); os.IsNotExist(err) {
+		if err := os.MkdirAll(dest, 0777); err != nil {
+			return err
+		}
+	}
+
+	// decompressed outside of the chroot, which lacks the tools used for
+	// some of the formats
+	decompressed, err := archive.DecompressStream(tarArchive)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+
+	var stderr bytes.Buffer
+	cmd := &exec.Cmd{
+		Path:   reexec.Self(),
+		Args:   []string{untarCommandName, dest, string(data)},
+		Stdin:  decompressed,
+		Stderr: &stderr,
+	}
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("Error extracting the archive to %s: %s", dest, stderr.String())
+		}
+		return fmt.Errorf("Error extracting the archive to %s: %s", dest, err)
+	}
+	return nil
+}
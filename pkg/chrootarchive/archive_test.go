@@ -0,0 +1,89 @@
+package chrootarchive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+)
+
+func init() {
+	reexec.Init()
+}
+
+func tarFiles(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestChrootUntar(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chroot requires root")
+	}
+	tmpdir, err := ioutil.TempDir("", "docker-TestChrootUntar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	dest := filepath.Join(tmpdir, "dest")
+	if err := Untar(tarFiles(t, map[string]string{"foo/bar": "hello"}), dest, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dest, "foo", "bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("Expected hello, got %q", data)
+	}
+}
+
+func TestChrootUntarBreakout(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chroot requires root")
+	}
+	tmpdir, err := ioutil.TempDir("", "docker-TestChrootUntarBreakout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	dest := filepath.Join(tmpdir, "dest")
+	outside := filepath.Join(tmpdir, "outside")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dest, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	Untar(tarFiles(t, map[string]string{"link/escaped": "hello"}), dest, nil)
+	if _, err := os.Stat(filepath.Join(outside, "escaped")); err == nil {
+		t.Fatal("The archive was extracted through a symlink outside of the destination")
+	}
+
+	Untar(tarFiles(t, map[string]string{"../escaped": "hello"}), dest, nil)
+	if _, err := os.Stat(filepath.Join(tmpdir, "escaped")); err == nil {
+		t.Fatal("The archive was extracted outside of the destination")
+	}
+}
@@ -0,0 +1,220 @@
+package idtools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer/user"
+)
+
+const (
+	subuidFileName = "/etc/subuid"
+	subgidFileName = "/etc/subgid"
+)
+
+// IDMap maps the range of Size ids of a container starting at ContainerID
+// to the range of ids of the host starting at HostID.
+type IDMap struct {
+	ContainerID int `json:"container_id"`
+	HostID      int `json:"host_id"`
+	Size        int `json:"size"`
+}
+
+type subIDRange struct {
+	Start  int
+	Length int
+}
+
+type ranges []subIDRange
+
+func (e ranges) Len() int           { return len(e) }
+func (e ranges) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e ranges) Less(i, j int) bool { return e[i].Start < e[j].Start }
+
+// CreateIDMappings returns the mappings of the users and groups of the
+// containers to the subordinate ids of the given user and group, read from
+// /etc/subuid and /etc/subgid. The ranges of a user are mapped one after the
+// other, starting at 0 in the container.
+func CreateIDMappings(username, groupname string) ([]IDMap, []IDMap, error) {
+	uid, gid, err := lookupUserGroup(username, groupname)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subuidRanges, err := parseSubidFile(subuidFileName, username, uid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(subuidRanges) == 0 {
+		return nil, nil, fmt.Errorf("No subuid ranges found for user %q in %s", username, subuidFileName)
+	}
+	subgidRanges, err := parseSubidFile(subgidFileName, groupname, gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(subgidRanges) == 0 {
+		return nil, nil, fmt.Errorf("No subgid ranges found for group %q in %s", groupname, subgidFileName)
+	}
+
+	return createIDMap(subuidRanges), createIDMap(subgidRanges), nil
+}
+
+func createIDMap(subidRanges ranges) []IDMap {
+	idMap := []IDMap{}
+
+	// sort the ranges by lowest ID first
+	sort.Sort(subidRanges)
+	containerID := 0
+	for _, idrange := range subidRanges {
+		idMap = append(idMap, IDMap{
+			ContainerID: containerID,
+			HostID:      idrange.Start,
+			Size:        idrange.Length,
+		})
+		containerID = containerID + idrange.Length
+	}
+	return idMap
+}
+
+// GetRootUIDGID returns the ids of the host mapped to root in the
+// containers, 0 when the mappings are empty.
+func GetRootUIDGID(uidMap, gidMap []IDMap) (int, int, error) {
+	uid, err := ToHost(0, uidMap)
+	if err != nil {
+		return -1, -1, err
+	}
+	gid, err := ToHost(0, gidMap)
+	if err != nil {
+		return -1, -1, err
+	}
+	return uid, gid, nil
+}
+
+// ToHost returns the id of the host mapped to the id of a container. Ids
+// are not changed when the mappings are empty.
+func ToHost(contID int, idMap []IDMap) (int, error) {
+	if idMap == nil {
+		return contID, nil
+	}
+	for _, m := range idMap {
+		if contID >= m.ContainerID && contID <= m.ContainerID+m.Size-1 {
+			return m.HostID + (contID - m.ContainerID), nil
+		}
+	}
+	return -1, fmt.Errorf("Container ID %d cannot be mapped to a host ID", contID)
+}
+
+// ToContainer returns the id of a container mapped to the id of the host.
+// Ids are not changed when the mappings are empty.
+func ToContainer(hostID int, idMap []IDMap) (int, error) {
+	if idMap == nil {
+		return hostID, nil
+	}
+	for _, m := range idMap {
+		if hostID >= m.HostID && hostID <= m.HostID+m.Size-1 {
+			return m.ContainerID + (hostID - m.HostID), nil
+		}
+	}
+	return -1, fmt.Errorf("Host ID %d cannot be mapped to a container ID", hostID)
+}
+
+// MkdirAllAs creates the directory path like os.MkdirAll, with the
+// directories it creates owned by uid and gid.
+func MkdirAllAs(path string, mode os.FileMode, uid, gid int) error {
+	var created []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || !os.IsNotExist(err) {
+			break
+		}
+		created = append(created, dir)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if err := os.MkdirAll(path, mode); err != nil {
+		return err
+	}
+	for _, dir := range created {
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupUserGroup returns the ids of the user and the group, given by name
+// or by id.
+func lookupUserGroup(username, groupname string) (int, int, error) {
+	uid, err := strconv.Atoi(username)
+	if err != nil {
+		users, err := user.ParsePasswdFilter(func(u *user.User) bool {
+			return u.Name == username
+		})
+		if err != nil {
+			return -1, -1, err
+		}
+		if len(users) == 0 {
+			return -1, -1, fmt.Errorf("No such user: %s", username)
+		}
+		uid = users[0].Uid
+	}
+
+	gid, err := strconv.Atoi(groupname)
+	if err != nil {
+		groups, err := user.ParseGroupFilter(func(g *user.Group) bool {
+			return g.Name == groupname
+		})
+		if err != nil {
+			return -1, -1, err
+		}
+		if len(groups) == 0 {
+			return -1, -1, fmt.Errorf("No such group: %s", groupname)
+		}
+		gid = groups[0].Gid
+	}
+	return uid, gid, nil
+}
+
+// parseSubidFile returns the ranges of the lines of the subordinate ids file
+// whose owner is the given name or id.
+func parseSubidFile(path, name string, id int) (ranges, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		rangeList ranges
+		idStr     = strconv.Itoa(id)
+		s         = bufio.NewScanner(f)
+	)
+	for s.Scan() {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Split(text, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("Invalid line in %s: %q", path, text)
+		}
+		if parts[0] != name && parts[0] != idStr {
+			continue
+		}
+		start, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid start of the range in %s: %q", path, text)
+		}
+		length, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid length of the range in %s: %q", path, text)
+		}
+		rangeList = append(rangeList, subIDRange{start, length})
+	}
+	return rangeList, s.Err()
+}
@@ -0,0 +1,107 @@
+package idtools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSubidFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "subuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("# comment\ndockremap:300000:65536\nother:100000:65536\n\n1000:200000:1000\ndockremap:231072:65536\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ranges, err := parseSubidFile(f.Name(), "dockremap", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 3 {
+		t.Fatalf("Expected 3 ranges, got %v", ranges)
+	}
+
+	idMap := createIDMap(ranges)
+	expected := []IDMap{
+		{ContainerID: 0, HostID: 200000, Size: 1000},
+		{ContainerID: 1000, HostID: 231072, Size: 65536},
+		{ContainerID: 66536, HostID: 300000, Size: 65536},
+	}
+	if len(idMap) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, idMap)
+	}
+	for i := range expected {
+		if idMap[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, idMap)
+		}
+	}
+
+	if ranges, err := parseSubidFile(f.Name(), "nobody", 65534); err != nil || len(ranges) != 0 {
+		t.Fatalf("Expected no range for nobody, got %v, %v", ranges, err)
+	}
+
+	if err := ioutil.WriteFile(f.Name(), []byte("dockremap:300000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSubidFile(f.Name(), "dockremap", 1000); err == nil {
+		t.Fatal("Expected an error for an invalid line")
+	}
+}
+
+func TestToHostToContainer(t *testing.T) {
+	idMap := []IDMap{
+		{ContainerID: 0, HostID: 100000, Size: 1000},
+		{ContainerID: 1000, HostID: 300000, Size: 1000},
+	}
+	for contID, hostID := range map[int]int{0: 100000, 999: 100999, 1000: 300000, 1999: 300999} {
+		if id, err := ToHost(contID, idMap); err != nil || id != hostID {
+			t.Fatalf("Expected %d to be mapped to %d, got %d, %v", contID, hostID, id, err)
+		}
+		if id, err := ToContainer(hostID, idMap); err != nil || id != contID {
+			t.Fatalf("Expected %d to be mapped to %d, got %d, %v", hostID, contID, id, err)
+		}
+	}
+	if _, err := ToHost(2000, idMap); err == nil {
+		t.Fatal("Expected an error for an unmapped container ID")
+	}
+	if _, err := ToContainer(0, idMap); err == nil {
+		t.Fatal("Expected an error for an unmapped host ID")
+	}
+
+	if id, err := ToHost(42, nil); err != nil || id != 42 {
+		t.Fatalf("Expected IDs not to be changed without mappings, got %d, %v", id, err)
+	}
+	uid, gid, err := GetRootUIDGID(idMap, nil)
+	if err != nil || uid != 100000 || gid != 0 {
+		t.Fatalf("Expected root to be 100000:0, got %d:%d, %v", uid, gid, err)
+	}
+}
+
+func TestMkdirAllAs(t *testing.T) {
+	root, err := ioutil.TempDir("", "idtools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := MkdirAllAs(filepath.Join(root, "a", "b"), 0700, os.Getuid(), os.Getgid()); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"a", "a/b"} {
+		fi, err := os.Stat(filepath.Join(root, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.IsDir() || fi.Mode().Perm() != 0700 {
+			t.Fatalf("Expected %s to be a directory with mode 0700, got %v", dir, fi.Mode())
+		}
+	}
+	if err := MkdirAllAs(filepath.Join(root, "a", "b"), 0700, os.Getuid(), os.Getgid()); err != nil {
+		t.Fatalf("Expected existing directories to be accepted, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,9 +21,10 @@ const (
 )
 
 var (
-	ErrIptablesNotFound = errors.New("Iptables not found")
-	nat                 = []string{"-t", "nat"}
-	supportsXlock       = false
+	ErrIptablesNotFound  = errors.New("Iptables not found")
+	ErrIp6tablesNotFound = errors.New("Ip6tables not found")
+	nat                  = []string{"-t", "nat"}
+	supportsXlock        = false
 )
 
 type Chain struct {
@@ -166,11 +168,34 @@ func Exists(args ...string) bool {
 	)
 }
 
+// Check if an existing rule exists in ip6tables
+func Exists6(args ...string) bool {
+	if _, err := Raw6(append([]string{"-C"}, args...)...); err == nil {
+		return true
+	}
+
+	existingRules, _ := exec.Command("ip6tables-save").Output()
+	return strings.Contains(string(existingRules), strings.Join(args, " "))
+}
+
 func Raw(args ...string) ([]byte, error) {
 	path, err := exec.LookPath("iptables")
 	if err != nil {
 		return nil, ErrIptablesNotFound
 	}
+	return raw(path, args...)
+}
+
+// Raw6 is like Raw for the IPv6 rules managed by ip6tables.
+func Raw6(args ...string) ([]byte, error) {
+	path, err := exec.LookPath("ip6tables")
+	if err != nil {
+		return nil, ErrIp6tablesNotFound
+	}
+	return raw(path, args...)
+}
+
+func raw(path string, args ...string) ([]byte, error) {
 
 	if supportsXlock {
 		args = append([]string{"--wait"}, args...)
@@ -180,7 +205,7 @@ func Raw(args ...string) ([]byte, error) {
 
 	output, err := exec.Command(path, args...).CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("iptables failed: iptables %v: %s (%s)", strings.Join(args, " "), output, err)
+		return nil, fmt.Errorf("iptables failed: %s %v: %s (%s)", filepath.Base(path), strings.Join(args, " "), output, err)
 	}
 
 	// ignore iptables' message about xtables lock
@@ -1,9 +1,55 @@
 package mount
 
 import (
+	"fmt"
 	"strings"
 )
 
+// flags are the mount() flags of the fstab type mount options, the ones
+// clearing the flag being marked with clear.
+var flags = map[string]struct {
+	clear bool
+	flag  int
+}{
+	"defaults":      {false, 0},
+	"ro":            {false, RDONLY},
+	"rw":            {true, RDONLY},
+	"suid":          {true, NOSUID},
+	"nosuid":        {false, NOSUID},
+	"dev":           {true, NODEV},
+	"nodev":         {false, NODEV},
+	"exec":          {true, NOEXEC},
+	"noexec":        {false, NOEXEC},
+	"sync":          {false, SYNCHRONOUS},
+	"async":         {true, SYNCHRONOUS},
+	"dirsync":       {false, DIRSYNC},
+	"remount":       {false, REMOUNT},
+	"mand":          {false, MANDLOCK},
+	"nomand":        {true, MANDLOCK},
+	"atime":         {true, NOATIME},
+	"noatime":       {false, NOATIME},
+	"diratime":      {true, NODIRATIME},
+	"nodiratime":    {false, NODIRATIME},
+	"bind":          {false, BIND},
+	"rbind":         {false, RBIND},
+	"private":       {false, PRIVATE},
+	"relatime":      {false, RELATIME},
+	"norelatime":    {true, RELATIME},
+	"strictatime":   {false, STRICTATIME},
+	"nostrictatime": {true, STRICTATIME},
+}
+
+// tmpfsData are the tmpfs specific options, which take a value.
+var tmpfsData = map[string]bool{
+	"size":      true,
+	"nr_blocks": true,
+	"nr_inodes": true,
+	"mode":      true,
+	"uid":       true,
+	"gid":       true,
+	"mpol":      true,
+}
+
 // Parse fstab type mount options into mount() flags
 // and device specific data
 func parseOptions(options string) (int, string) {
@@ -12,38 +58,6 @@ func parseOptions(options string) (int, string) {
 		data []string
 	)
 
-	flags := map[string]struct {
-		clear bool
-		flag  int
-	}{
-		"defaults":      {false, 0},
-		"ro":            {false, RDONLY},
-		"rw":            {true, RDONLY},
-		"suid":          {true, NOSUID},
-		"nosuid":        {false, NOSUID},
-		"dev":           {true, NODEV},
-		"nodev":         {false, NODEV},
-		"exec":          {true, NOEXEC},
-		"noexec":        {false, NOEXEC},
-		"sync":          {false, SYNCHRONOUS},
-		"async":         {true, SYNCHRONOUS},
-		"dirsync":       {false, DIRSYNC},
-		"remount":       {false, REMOUNT},
-		"mand":          {false, MANDLOCK},
-		"nomand":        {true, MANDLOCK},
-		"atime":         {true, NOATIME},
-		"noatime":       {false, NOATIME},
-		"diratime":      {true, NODIRATIME},
-		"nodiratime":    {false, NODIRATIME},
-		"bind":          {false, BIND},
-		"rbind":         {false, RBIND},
-		"private":       {false, PRIVATE},
-		"relatime":      {false, RELATIME},
-		"norelatime":    {true, RELATIME},
-		"strictatime":   {false, STRICTATIME},
-		"nostrictatime": {true, STRICTATIME},
-	}
-
 	for _, o := range strings.Split(options, ",") {
 		// If the option does not exist in the flags table or the flag
 		// is not supported on the platform,
@@ -60,3 +74,38 @@ func parseOptions(options string) (int, string) {
 	}
 	return flag, strings.Join(data, ",")
 }
+
+// ParseTmpfsOptions parses the fstab type options of a tmpfs mount into
+// mount() flags and tmpfs data. The mount is noexec, nosuid and nodev unless
+// the options say otherwise. Options which don't apply to a tmpfs mount are
+// rejected.
+func ParseTmpfsOptions(options string) (int, string, error) {
+	var (
+		flag = NOEXEC | NOSUID | NODEV
+		data []string
+	)
+
+	if options == "" {
+		return flag, "", nil
+	}
+	for _, o := range strings.Split(options, ",") {
+		if f, exists := flags[o]; exists {
+			switch o {
+			case "remount", "bind", "rbind", "private":
+				return 0, "", fmt.Errorf("Invalid tmpfs option %q", o)
+			}
+			if f.clear {
+				flag &= ^f.flag
+			} else {
+				flag |= f.flag
+			}
+			continue
+		}
+		parts := strings.SplitN(o, "=", 2)
+		if len(parts) != 2 || parts[1] == "" || !tmpfsData[parts[0]] {
+			return 0, "", fmt.Errorf("Invalid tmpfs option %q", o)
+		}
+		data = append(data, o)
+	}
+	return flag, strings.Join(data, ","), nil
+}
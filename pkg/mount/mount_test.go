@@ -22,6 +22,33 @@ func TestMountOptionsParsing(t *testing.T) {
 	}
 }
 
+func TestTmpfsOptionsParsing(t *testing.T) {
+	flag, data, err := ParseTmpfsOptions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedFlag := NOEXEC | NOSUID | NODEV; flag != expectedFlag || data != "" {
+		t.Fatalf("Expected %d and no data by default, got %d and %s", expectedFlag, flag, data)
+	}
+
+	flag, data, err = ParseTmpfsOptions("ro,exec,size=64m,mode=1777")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != "size=64m,mode=1777" {
+		t.Fatalf("Expected size=64m,mode=1777 got %s", data)
+	}
+	if expectedFlag := NOSUID | NODEV | RDONLY; flag != expectedFlag {
+		t.Fatalf("Expected %d got %d", expectedFlag, flag)
+	}
+
+	for _, options := range []string{"bind", "size", "size=", "foo=bar", "rw,,size=1m"} {
+		if _, _, err := ParseTmpfsOptions(options); err == nil {
+			t.Fatalf("Expected an error for the options %q", options)
+		}
+	}
+}
+
 func TestMounted(t *testing.T) {
 	tmp := path.Join(os.TempDir(), "mount-tests")
 	if err := os.MkdirAll(tmp, 0777); err != nil {
@@ -51,3 +51,29 @@ func Update(path, IP, hostname string) error {
 	var re = regexp.MustCompile(fmt.Sprintf("(\\S*)(\\t%s)", regexp.QuoteMeta(hostname)))
 	return ioutil.WriteFile(path, re.ReplaceAll(old, []byte(IP+"$2")), 0644)
 }
+
+// Add sets the entry of hostname in the hosts file at path to IP, replacing
+// the entry already written by Add if there is one.
+func Add(path, IP, hostname string) error {
+	old, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := append(entryPattern(hostname).ReplaceAll(old, nil), []byte(fmt.Sprintf("%s\t%s\n", IP, hostname))...)
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// Delete removes the entry of hostname written by Add from the hosts file at
+// path.
+func Delete(path, hostname string) error {
+	old, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, entryPattern(hostname).ReplaceAll(old, nil), 0644)
+}
+
+// entryPattern matches the lines holding only hostname.
+func entryPattern(hostname string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf("(?m)^\\S+\\t%s\\n", regexp.QuoteMeta(hostname)))
+}
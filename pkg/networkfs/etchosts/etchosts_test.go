@@ -106,3 +106,53 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("Expected to find '%s' got '%s'", expected, content)
 	}
 }
+
+func TestAddDelete(t *testing.T) {
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := Build(file.Name(), "10.11.12.13", "testhostname", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Add(file.Name(), "10.11.12.14", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(file.Name(), "10.11.12.15", "web2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(file.Name(), "10.11.12.16", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "10.11.12.16\tweb\n"; !bytes.Contains(content, []byte(expected)) {
+		t.Fatalf("Expected to find '%s' got '%s'", expected, content)
+	}
+	if unexpected := "10.11.12.14\tweb\n"; bytes.Contains(content, []byte(unexpected)) {
+		t.Fatalf("Expected the entry '%s' to be replaced, got '%s'", unexpected, content)
+	}
+
+	if err := Delete(file.Name(), "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err = ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unexpected := "\tweb\n"; bytes.Contains(content, []byte(unexpected)) {
+		t.Fatalf("Expected the entry of web to be deleted, got '%s'", content)
+	}
+	for _, expected := range []string{"10.11.12.15\tweb2\n", "10.11.12.13\ttesthostname\n"} {
+		if !bytes.Contains(content, []byte(expected)) {
+			t.Fatalf("Expected to find '%s' got '%s'", expected, content)
+		}
+	}
+}
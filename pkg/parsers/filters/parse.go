@@ -3,6 +3,7 @@ package filters
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -81,3 +82,59 @@ func (filters Args) Match(field, source string) bool {
 	}
 	return false
 }
+
+// MatchKVList returns true if every value of the filter field matches an
+// entry of sources. A value is either a "key", matching any entry with that
+// key, or a "key=value" pair, matching only the entry with that exact value:
+//
+//   `docker ps -f 'label=com.example.vendor' -f 'label=version=1.0'`
+func (filters Args) MatchKVList(field string, sources map[string]string) bool {
+	fieldValues := filters[field]
+
+	//do not filter if there is no filter set or cannot determine filter
+	if len(fieldValues) == 0 {
+		return true
+	}
+	if len(sources) == 0 {
+		return false
+	}
+	for _, name2match := range fieldValues {
+		kv := strings.SplitN(name2match, "=", 2)
+		value, exists := sources[kv[0]]
+		if !exists {
+			return false
+		}
+		if len(kv) == 2 && value != kv[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchPrefix returns true if one of the values of the filter field is a
+// prefix of source, as used to match truncated IDs.
+func (filters Args) MatchPrefix(field, source string) bool {
+	fieldValues := filters[field]
+
+	//do not filter if there is no filter set or cannot determine filter
+	if len(fieldValues) == 0 {
+		return true
+	}
+	for _, prefix := range fieldValues {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the Args contain a filter which is not in
+// the accepted set.
+func (filters Args) Validate(accepted map[string]bool) error {
+	for name := range filters {
+		if !accepted[name] {
+			return fmt.Errorf("Invalid filter '%s'", name)
+		}
+	}
+	return nil
+}
@@ -76,3 +76,92 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("these should both be empty sets")
 	}
 }
+
+func TestMatch(t *testing.T) {
+	a := Args{
+		"name": []string{"^/foo", "bar$"},
+	}
+	matches := map[string]bool{
+		"/foo1":   true,
+		"/foobar": true,
+		"/bar":    true,
+		"/baz":    false,
+		"/ofoo":   false,
+	}
+	for source, expected := range matches {
+		if a.Match("name", source) != expected {
+			t.Errorf("expected Match(%q) to be %v", source, expected)
+		}
+	}
+	if !a.Match("unset", "anything") {
+		t.Errorf("a field without filters should match anything")
+	}
+}
+
+func TestMatchKVList(t *testing.T) {
+	sources := map[string]string{
+		"key1": "value1",
+		"key2": "value2",
+		"key3": "",
+	}
+	matches := map[string]bool{
+		"key1":        true,
+		"key1=value1": true,
+		"key1=value2": false,
+		"key3=":       true,
+		"key3=value3": false,
+		"key4":        false,
+	}
+	for filter, expected := range matches {
+		a := Args{"label": []string{filter}}
+		if a.MatchKVList("label", sources) != expected {
+			t.Errorf("expected MatchKVList with %q to be %v", filter, expected)
+		}
+	}
+
+	a := Args{"label": []string{"key1", "key2=value2"}}
+	if !a.MatchKVList("label", sources) {
+		t.Errorf("expected all the labels to match")
+	}
+	a = Args{"label": []string{"key1", "key4"}}
+	if a.MatchKVList("label", sources) {
+		t.Errorf("expected a missing label to make the whole filter fail")
+	}
+	if a.MatchKVList("label", nil) {
+		t.Errorf("expected no match without any source")
+	}
+	if !a.MatchKVList("unset", nil) {
+		t.Errorf("a field without filters should match anything")
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	a := Args{"id": []string{"abc", "123"}}
+	matches := map[string]bool{
+		"abcdef": true,
+		"123456": true,
+		"bcdef":  false,
+		"ab":     false,
+	}
+	for source, expected := range matches {
+		if a.MatchPrefix("id", source) != expected {
+			t.Errorf("expected MatchPrefix(%q) to be %v", source, expected)
+		}
+	}
+	if !a.MatchPrefix("unset", "anything") {
+		t.Errorf("a field without filters should match anything")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	accepted := map[string]bool{
+		"label":  true,
+		"status": true,
+	}
+	if err := (Args{"label": []string{"foo"}, "status": []string{"running"}}).Validate(accepted); err != nil {
+		t.Errorf("expected accepted filters to validate, got %s", err)
+	}
+	if err := (Args{"unknown": []string{"foo"}}).Validate(accepted); err == nil {
+		t.Errorf("expected an unknown filter to fail validation")
+	}
+}
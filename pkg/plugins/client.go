@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// VersionMimetype is the content type of the requests and responses
+	// of the plugin protocol.
+	VersionMimetype = "application/vnd.docker.plugins.v1+json"
+
+	defaultTimeOut = 30 * time.Second
+)
+
+// requestTimeout bounds the time a plugin has to answer a call, so that a
+// hung plugin does not block the daemon forever. Mounting volumes on remote
+// storage can take a while.
+var requestTimeout = 2 * time.Minute
+
+// Client calls the methods of a plugin, as HTTP POST requests with JSON
+// bodies.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a client for the plugin listening on addr, either
+// unix:///path/to/socket or tcp://host:port.
+func NewClient(addr string) (*Client, error) {
+	protoAddrParts := strings.SplitN(addr, "://", 2)
+	if len(protoAddrParts) != 2 {
+		return nil, fmt.Errorf("Invalid plugin address: %s", addr)
+	}
+	proto, address := protoAddrParts[0], protoAddrParts[1]
+	if proto != "unix" && proto != "tcp" {
+		return nil, fmt.Errorf("Invalid plugin address: %s", addr)
+	}
+
+	tr := &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.DialTimeout(proto, address, defaultTimeOut)
+		},
+	}
+	return &Client{http: &http.Client{Transport: tr, Timeout: requestTimeout}}, nil
+}
+
+// Call calls the given method of the plugin with args encoded in JSON, and
+// decodes the JSON response in ret.
+func (c *Client) Call(method string, args interface{}, ret interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(args); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", "http://plugin/"+method, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", VersionMimetype)
+	req.Header.Set("Content-Type", VersionMimetype)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("Plugin error on %s: %s", method, resp.Status)
+		}
+		return fmt.Errorf("Plugin error on %s: %s", method, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
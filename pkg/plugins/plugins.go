@@ -0,0 +1,101 @@
+// Package plugins finds the plugins extending the daemon and talks to them.
+//
+// A plugin is a process listening on a Unix socket named after it in
+// SocketsPath. The daemon calls its methods as HTTP POST requests on
+// /<Subsystem>.<Method> with JSON bodies. The first call is always
+// /Plugin.Activate, which answers with the subsystems the plugin implements:
+//
+//	{"Implements": ["VolumeDriver"]}
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SocketsPath is the directory of the sockets of the plugins.
+var SocketsPath = "/run/docker/plugins"
+
+// ErrNotFound is returned when no plugin listens with the given name.
+var ErrNotFound = errors.New("Plugin not found")
+
+// Manifest is the answer of a plugin to its activation.
+type Manifest struct {
+	Implements []string
+}
+
+// Plugin is an activated plugin.
+type Plugin struct {
+	Name     string
+	Addr     string
+	Client   *Client
+	Manifest *Manifest
+}
+
+var (
+	activated     = make(map[string]*Plugin)
+	activatedLock sync.Mutex
+)
+
+// Get returns the plugin with the given name, activating it on first use,
+// if it implements the given subsystem.
+func Get(name, subsystem string) (*Plugin, error) {
+	p, err := get(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, implements := range p.Manifest.Implements {
+		if implements == subsystem {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("Plugin %s does not implement %s", name, subsystem)
+}
+
+func get(name string) (*Plugin, error) {
+	activatedLock.Lock()
+	p, exists := activated[name]
+	activatedLock.Unlock()
+	if exists {
+		return p, nil
+	}
+
+	if name == "" || filepath.Base(name) != name {
+		return nil, fmt.Errorf("Invalid plugin name: %s", name)
+	}
+	addr := filepath.Join(SocketsPath, name+".sock")
+	if _, err := os.Stat(addr); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	client, err := NewClient("unix://" + addr)
+	if err != nil {
+		return nil, err
+	}
+	// the activation is an HTTP call, the other plugins must not wait for it
+	manifest := &Manifest{}
+	if err := client.Call("Plugin.Activate", struct{}{}, manifest); err != nil {
+		return nil, err
+	}
+
+	activatedLock.Lock()
+	defer activatedLock.Unlock()
+	if p, exists := activated[name]; exists {
+		// activated concurrently by another caller
+		return p, nil
+	}
+	p = &Plugin{
+		Name:     name,
+		Addr:     addr,
+		Client:   client,
+		Manifest: manifest,
+	}
+	activated[name] = p
+	return p, nil
+}
@@ -0,0 +1,150 @@
+package plugins
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startPlugin serves the given methods on the socket of a plugin named name
+// in a temporary SocketsPath.
+func startPlugin(t *testing.T, name string, methods map[string]func(w http.ResponseWriter, r *http.Request)) func() {
+	tmp, err := ioutil.TempDir("", "docker-plugins-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldPath := SocketsPath
+	SocketsPath = tmp
+
+	l, err := net.Listen("unix", filepath.Join(tmp, name+".sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	for method, handler := range methods {
+		mux.HandleFunc("/"+method, handler)
+	}
+	go http.Serve(l, mux)
+
+	return func() {
+		l.Close()
+		os.RemoveAll(tmp)
+		SocketsPath = oldPath
+		activatedLock.Lock()
+		delete(activated, name)
+		activatedLock.Unlock()
+	}
+}
+
+func activateHandler(implements ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", VersionMimetype)
+		json.NewEncoder(w).Encode(&Manifest{Implements: implements})
+	}
+}
+
+func TestGetAndCall(t *testing.T) {
+	defer startPlugin(t, "echo", map[string]func(w http.ResponseWriter, r *http.Request){
+		"Plugin.Activate": activateHandler("Echo"),
+		"Echo.Say": func(w http.ResponseWriter, r *http.Request) {
+			if accept := r.Header.Get("Accept"); accept != VersionMimetype {
+				t.Errorf("Expected Accept: %s, got %s", VersionMimetype, accept)
+			}
+			var args map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+				t.Error(err)
+			}
+			json.NewEncoder(w).Encode(map[string]string{"Said": args["Say"]})
+		},
+	})()
+
+	p, err := Get("echo", "Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret map[string]string
+	if err := p.Client.Call("Echo.Say", map[string]string{"Say": "hello"}, &ret); err != nil {
+		t.Fatal(err)
+	}
+	if ret["Said"] != "hello" {
+		t.Fatalf("Expected hello, got %v", ret)
+	}
+
+	if _, err := Get("echo", "VolumeDriver"); err == nil {
+		t.Fatal("Expected an error for a subsystem the plugin does not implement")
+	}
+}
+
+func TestGetNotFound(t *testing.T) {
+	defer startPlugin(t, "echo", map[string]func(w http.ResponseWriter, r *http.Request){
+		"Plugin.Activate": activateHandler("Echo"),
+	})()
+
+	if _, err := Get("missing", "Echo"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := Get("../echo", "Echo"); err == nil {
+		t.Fatal("Expected an error for an invalid plugin name")
+	}
+}
+
+func TestCallError(t *testing.T) {
+	defer startPlugin(t, "failing", map[string]func(w http.ResponseWriter, r *http.Request){
+		"Plugin.Activate": activateHandler("Echo"),
+		"Echo.Say": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "cannot say", http.StatusInternalServerError)
+		},
+	})()
+
+	p, err := Get("failing", "Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret map[string]string
+	err = p.Client.Call("Echo.Say", map[string]string{"Say": "hello"}, &ret)
+	if err == nil || err.Error() != "Plugin error on Echo.Say: cannot say" {
+		t.Fatalf("Expected the error of the plugin, got %v", err)
+	}
+}
+
+func TestActivateTimeout(t *testing.T) {
+	oldTimeout := requestTimeout
+	requestTimeout = 100 * time.Millisecond
+	defer func() { requestTimeout = oldTimeout }()
+
+	release := make(chan struct{})
+	cleanup := startPlugin(t, "hung", map[string]func(w http.ResponseWriter, r *http.Request){
+		"Plugin.Activate": func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		},
+	})
+	defer cleanup()
+	defer close(release)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Get("hung", "Echo")
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("Expected an error when the plugin does not answer")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Activating a hung plugin did not time out")
+	}
+
+	// the failed activation is not recorded
+	activatedLock.Lock()
+	_, exists := activated["hung"]
+	activatedLock.Unlock()
+	if exists {
+		t.Fatal("Expected the hung plugin not to be activated")
+	}
+}
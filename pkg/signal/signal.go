@@ -1,10 +1,18 @@
 package signal
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
+// DefaultStopSignal is the signal sent to stop a container which does not
+// specify its own.
+const DefaultStopSignal = "SIGTERM"
+
 func CatchAll(sigc chan os.Signal) {
 	handledSigs := []os.Signal{}
 	for _, s := range SignalMap {
@@ -17,3 +25,19 @@ func StopCatch(sigc chan os.Signal) {
 	signal.Stop(sigc)
 	close(sigc)
 }
+
+// ParseSignal translates a signal given as a number or a name, with or
+// without the SIG prefix (e.g. "15", "TERM" or "SIGTERM"), to a signal.
+func ParseSignal(rawSignal string) (syscall.Signal, error) {
+	if s, err := strconv.Atoi(rawSignal); err == nil {
+		if s <= 0 {
+			return -1, fmt.Errorf("Invalid signal: %s", rawSignal)
+		}
+		return syscall.Signal(s), nil
+	}
+	s, ok := SignalMap[strings.TrimPrefix(strings.ToUpper(rawSignal), "SIG")]
+	if !ok {
+		return -1, fmt.Errorf("Invalid signal: %s", rawSignal)
+	}
+	return s, nil
+}
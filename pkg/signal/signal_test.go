@@ -0,0 +1,29 @@
+package signal
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for raw, expected := range map[string]syscall.Signal{
+		"15":      syscall.SIGTERM,
+		"TERM":    syscall.SIGTERM,
+		"SIGQUIT": syscall.SIGQUIT,
+		"sigint":  syscall.SIGINT,
+	} {
+		s, err := ParseSignal(raw)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %s", raw, err)
+		}
+		if s != expected {
+			t.Fatalf("Expected %s to be parsed as %d, got %d", raw, expected, s)
+		}
+	}
+
+	for _, raw := range []string{"", "0", "-1", "SIGFOO", "SIG"} {
+		if _, err := ParseSignal(raw); err == nil {
+			t.Fatalf("Expected an error parsing %q", raw)
+		}
+	}
+}
@@ -61,6 +61,11 @@ func getV2URL(e *Endpoint, routeName string, vars map[string]string) (*url.URL,
 	if err != nil {
 		return nil, fmt.Errorf("invalid registry url: %s", err)
 	}
+	// the official index delegates the v2 calls to its registry, any other
+	// endpoint serves them itself
+	if e.VersionString(1) != IndexServerAddress() {
+		u = e.URL
+	}
 
 	return &url.URL{
 		Scheme: u.Scheme,
@@ -191,6 +196,46 @@ func (r *Session) PostV2ImageMountBlob(imageName, sumType, sum string, token []s
 	return false, fmt.Errorf("Failed to mount %q - %s:%s : %d", imageName, sumType, sum, res.StatusCode)
 }
 
+// Check if the blob identified by sumType and sum is already present
+// in the image's namespace on the registry
+func (r *Session) HeadV2ImageBlob(imageName, sumType, sum string, token []string) (bool, error) {
+	vars := map[string]string{
+		"imagename": imageName,
+		"sumtype":   sumType,
+		"sum":       sum,
+	}
+
+	routeURL, err := getV2URL(r.indexEndpoint, "downloadBlob", vars)
+	if err != nil {
+		return false, err
+	}
+
+	method := "HEAD"
+	log.Debugf("[registry] Calling %q %s", method, routeURL.String())
+
+	req, err := r.reqFactory.NewRequest(method, routeURL.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	setTokenAuth(req, token)
+	res, _, err := r.doRequest(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case 200:
+		// the blob is already there, no push needed
+		return true, nil
+	case 404:
+		// the blob has to be pushed
+		return false, nil
+	case 401:
+		return false, errLoginRequired
+	}
+	return false, utils.NewHTTPRequestError(fmt.Sprintf("Server error: %d trying head request for %s - %s:%s", res.StatusCode, imageName, sumType, sum), res)
+}
+
 func (r *Session) GetV2ImageBlob(imageName, sumType, sum string, blobWrtr io.Writer, token []string) error {
 	vars := map[string]string{
 		"imagename": imageName,
@@ -1,6 +1,8 @@
 package runconfig
 
 import (
+	"time"
+
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
 )
@@ -33,6 +35,21 @@ type Config struct {
 	NetworkDisabled bool
 	OnBuild         []string
 	SecurityOpt     []string
+	Labels          map[string]string
+	Healthcheck     *HealthConfig
+	StopSignal      string // Signal sent to stop the container, SIGTERM if empty
+}
+
+// HealthConfig holds the configuration of the command run periodically
+// inside a container to check that it is healthy.
+type HealthConfig struct {
+	// Test is the check to run: ["NONE"] disables the check inherited from
+	// the image, ["CMD", args...] executes the command directly and
+	// ["CMD-SHELL", command] runs it with /bin/sh -c.
+	Test     []string
+	Interval time.Duration // Time between two checks, 0 means the default
+	Timeout  time.Duration // Time after which a check is considered failed, 0 means the default
+	Retries  int           // Consecutive failures needed to report unhealthy, 0 means the default
 }
 
 func ContainerConfigFromJob(job *engine.Job) *Config {
@@ -53,9 +70,12 @@ func ContainerConfigFromJob(job *engine.Job) *Config {
 		Image:           job.Getenv("Image"),
 		WorkingDir:      job.Getenv("WorkingDir"),
 		NetworkDisabled: job.GetenvBool("NetworkDisabled"),
+		StopSignal:      job.Getenv("StopSignal"),
 	}
 	job.GetenvJson("ExposedPorts", &config.ExposedPorts)
 	job.GetenvJson("Volumes", &config.Volumes)
+	job.GetenvJson("Labels", &config.Labels)
+	job.GetenvJson("Healthcheck", &config.Healthcheck)
 	config.SecurityOpt = job.GetenvList("SecurityOpt")
 	if PortSpecs := job.GetenvList("PortSpecs"); PortSpecs != nil {
 		config.PortSpecs = PortSpecs
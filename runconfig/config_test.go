@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/nat"
 )
@@ -161,6 +162,48 @@ func TestParseRunVolumes(t *testing.T) {
 	}
 }
 
+func TestParseRunLabels(t *testing.T) {
+	if config, _ := mustParse(t, ""); len(config.Labels) != 0 {
+		t.Fatalf("Error parsing labels, expected no labels, got %v", config.Labels)
+	}
+	config, _ := mustParse(t, "-l foo=bar --label empty= --label novalue -l foo=baz")
+	if len(config.Labels) != 3 {
+		t.Fatalf("Error parsing labels, expected 3 labels, got %v", config.Labels)
+	}
+	if config.Labels["foo"] != "baz" {
+		t.Fatalf("Error parsing labels, expected foo=baz, got foo=%s", config.Labels["foo"])
+	}
+	for _, key := range []string{"empty", "novalue"} {
+		if value, exists := config.Labels[key]; !exists || value != "" {
+			t.Fatalf("Error parsing labels, expected %s to be set to an empty value, got %v", key, config.Labels)
+		}
+	}
+}
+
+func TestParseRunHealthcheck(t *testing.T) {
+	if config, _ := mustParse(t, ""); config.Healthcheck != nil {
+		t.Fatalf("Error parsing healthcheck, expected none, got %v", config.Healthcheck)
+	}
+	config, _ := mustParse(t, "--health-cmd=true --health-interval=5s --health-timeout=2s --health-retries=4")
+	health := config.Healthcheck
+	if health == nil {
+		t.Fatal("Error parsing healthcheck, expected a healthcheck")
+	}
+	if len(health.Test) != 2 || health.Test[0] != "CMD-SHELL" || health.Test[1] != "true" {
+		t.Fatalf("Error parsing healthcheck, expected [CMD-SHELL true], got %v", health.Test)
+	}
+	if health.Interval != 5*time.Second || health.Timeout != 2*time.Second || health.Retries != 4 {
+		t.Fatalf("Error parsing healthcheck options, got %+v", health)
+	}
+	config, _ = mustParse(t, "--health-retries=2")
+	if config.Healthcheck == nil || len(config.Healthcheck.Test) != 0 || config.Healthcheck.Retries != 2 {
+		t.Fatalf("Error parsing healthcheck, expected only retries to be set, got %+v", config.Healthcheck)
+	}
+	if _, _, err := parse(t, "--health-interval=-1s"); err == nil {
+		t.Fatal("Expected an error with a negative health interval")
+	}
+}
+
 func TestCompare(t *testing.T) {
 	volumes1 := make(map[string]struct{})
 	volumes1["/test1"] = struct{}{}
@@ -187,6 +230,26 @@ func TestCompare(t *testing.T) {
 	if Compare(&config1, &config5) {
 		t.Fatalf("Compare should return false, Volumes are different")
 	}
+	config6 := config1
+	config6.Labels = map[string]string{"foo": "bar"}
+	config7 := config1
+	config7.Labels = map[string]string{"foo": "baz"}
+	if Compare(&config1, &config6) {
+		t.Fatalf("Compare should return false, Labels are different")
+	}
+	if Compare(&config6, &config7) {
+		t.Fatalf("Compare should return false, Label values are different")
+	}
+	config8 := config1
+	config8.Healthcheck = &HealthConfig{Test: []string{"CMD-SHELL", "true"}}
+	config9 := config1
+	config9.Healthcheck = &HealthConfig{Test: []string{"CMD-SHELL", "true"}, Retries: 2}
+	if Compare(&config1, &config8) {
+		t.Fatalf("Compare should return false, Healthcheck is only set on one side")
+	}
+	if Compare(&config8, &config9) {
+		t.Fatalf("Compare should return false, Healthchecks are different")
+	}
 	if !Compare(&config1, &config1) {
 		t.Fatalf("Compare should return true")
 	}
@@ -200,6 +263,12 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"1111:1111", "2222:2222"},
 		Env:       []string{"VAR1=1", "VAR2=2"},
 		Volumes:   volumesImage,
+		Labels:    map[string]string{"foo": "image", "bar": "image"},
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "true"},
+			Interval: time.Minute,
+			Retries:  5,
+		},
 	}
 
 	volumesUser := make(map[string]struct{})
@@ -208,6 +277,10 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"3333:2222", "3333:3333"},
 		Env:       []string{"VAR2=3", "VAR3=3"},
 		Volumes:   volumesUser,
+		Labels:    map[string]string{"foo": "user"},
+		Healthcheck: &HealthConfig{
+			Interval: time.Second,
+		},
 	}
 
 	if err := Merge(configUser, configImage); err != nil {
@@ -240,6 +313,15 @@ func TestMerge(t *testing.T) {
 		}
 	}
 
+	if len(configUser.Labels) != 2 || configUser.Labels["foo"] != "user" || configUser.Labels["bar"] != "image" {
+		t.Fatalf("Expected labels foo=user and bar=image, found %v", configUser.Labels)
+	}
+
+	health := configUser.Healthcheck
+	if len(health.Test) != 2 || health.Interval != time.Second || health.Retries != 5 {
+		t.Fatalf("Expected the healthcheck of the image with a 1s interval, found %+v", health)
+	}
+
 	ports, _, err := nat.ParsePortSpecs([]string{"0000"})
 	if err != nil {
 		t.Error(err)
@@ -1,10 +1,13 @@
 package runconfig
 
 import (
+	"fmt"
+	"path"
 	"strings"
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/utils"
 )
 
@@ -28,6 +31,12 @@ func (n NetworkMode) IsNone() bool {
 	return n == "none"
 }
 
+// IsUserDefined indicates whether the container is on a network created with
+// docker network create, the mode being the name of the network
+func (n NetworkMode) IsUserDefined() bool {
+	return n.IsPrivate() && n != "bridge" && n != ""
+}
+
 type DeviceMapping struct {
 	PathOnHost        string
 	PathInContainer   string
@@ -39,6 +48,11 @@ type RestartPolicy struct {
 	MaximumRetryCount int
 }
 
+type LogConfig struct {
+	Type   string
+	Config map[string]string
+}
+
 type HostConfig struct {
 	Binds           []string
 	ContainerIDFile string
@@ -56,6 +70,10 @@ type HostConfig struct {
 	CapAdd          []string
 	CapDrop         []string
 	RestartPolicy   RestartPolicy
+	LogConfig       LogConfig
+	VolumeDriver    string
+	ReadonlyRootfs  bool
+	Tmpfs           map[string]string // tmpfs mount points and their options
 }
 
 // This is used by the create command when you want to set both the
@@ -84,12 +102,16 @@ func ContainerHostConfigFromJob(job *engine.Job) *HostConfig {
 		Privileged:      job.GetenvBool("Privileged"),
 		PublishAllPorts: job.GetenvBool("PublishAllPorts"),
 		NetworkMode:     NetworkMode(job.Getenv("NetworkMode")),
+		VolumeDriver:    job.Getenv("VolumeDriver"),
+		ReadonlyRootfs:  job.GetenvBool("ReadonlyRootfs"),
 	}
 
 	job.GetenvJson("LxcConf", &hostConfig.LxcConf)
 	job.GetenvJson("PortBindings", &hostConfig.PortBindings)
 	job.GetenvJson("Devices", &hostConfig.Devices)
 	job.GetenvJson("RestartPolicy", &hostConfig.RestartPolicy)
+	job.GetenvJson("LogConfig", &hostConfig.LogConfig)
+	job.GetenvJson("Tmpfs", &hostConfig.Tmpfs)
 	if Binds := job.GetenvList("Binds"); Binds != nil {
 		hostConfig.Binds = Binds
 	}
@@ -117,3 +139,17 @@ func ContainerHostConfigFromJob(job *engine.Job) *HostConfig {
 
 	return hostConfig
 }
+
+// ValidateTmpfs checks the mount point and the options of a tmpfs mount.
+func ValidateTmpfs(dest, options string) error {
+	if !path.IsAbs(dest) {
+		return fmt.Errorf("Invalid tmpfs mount point %s: the path must be absolute", dest)
+	}
+	if path.Clean(dest) == "/" {
+		return fmt.Errorf("Invalid tmpfs mount point: path can't be '/'")
+	}
+	if _, _, err := mount.ParseTmpfsOptions(options); err != nil {
+		return fmt.Errorf("Invalid tmpfs mount %s: %v", dest, err)
+	}
+	return nil
+}
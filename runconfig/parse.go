@@ -1,7 +1,10 @@
 package runconfig
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"path"
 	"strconv"
 	"strings"
@@ -10,6 +13,7 @@ import (
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/utils"
@@ -31,6 +35,7 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		flVolumes = opts.NewListOpts(opts.ValidatePath)
 		flLinks   = opts.NewListOpts(opts.ValidateLink)
 		flEnv     = opts.NewListOpts(opts.ValidateEnv)
+		flLabels  = opts.NewListOpts(opts.ValidateLabel)
 		flDevices = opts.NewListOpts(opts.ValidatePath)
 
 		flPublish     = opts.NewListOpts(nil)
@@ -41,13 +46,17 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		flVolumesFrom = opts.NewListOpts(nil)
 		flLxcOpts     = opts.NewListOpts(nil)
 		flEnvFile     = opts.NewListOpts(nil)
+		flLabelsFile  = opts.NewListOpts(nil)
 		flCapAdd      = opts.NewListOpts(nil)
 		flCapDrop     = opts.NewListOpts(nil)
+		flTmpfs       = opts.NewListOpts(nil)
 		flSecurityOpt = opts.NewListOpts(nil)
+		flLoggingOpts = opts.NewMapOpts(nil, opts.ValidateLogOpt)
 
 		flNetwork         = cmd.Bool([]string{"#n", "#-networking"}, true, "Enable networking for this container")
 		flPrivileged      = cmd.Bool([]string{"#privileged", "-privileged"}, false, "Give extended privileges to this container")
 		flPublishAll      = cmd.Bool([]string{"P", "-publish-all"}, false, "Publish all exposed ports to the host interfaces")
+		flReadonlyRootfs  = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
 		flStdin           = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
 		flTty             = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
 		flContainerIDFile = cmd.String([]string{"#cidfile", "-cidfile"}, "", "Write the container ID to the file")
@@ -58,17 +67,26 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		flWorkingDir      = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
 		flCpuShares       = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
 		flCpuset          = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
-		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.")
+		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'<network-name>': connects the container to a network created with docker network create\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.")
 		flRestartPolicy   = cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits (no, on-failure[:max-retry], always)")
+		flLoggingDriver   = cmd.String([]string{"-log-driver"}, "", "Logging driver for container")
+		flVolumeDriver    = cmd.String([]string{"-volume-driver"}, "", "Driver of the volumes created for the container")
+		flHealthCmd       = cmd.String([]string{"-health-cmd"}, "", "Command to run to check the health of the container")
+		flHealthInterval  = cmd.Duration([]string{"-health-interval"}, 0, "Time between running the health check (e.g. 30s)")
+		flHealthTimeout   = cmd.Duration([]string{"-health-timeout"}, 0, "Maximum time to allow one health check to run (e.g. 30s)")
+		flHealthRetries   = cmd.Int([]string{"-health-retries"}, 0, "Consecutive failures needed to report unhealthy")
+		flStopSignal      = cmd.String([]string{"-stop-signal"}, "", fmt.Sprintf("Signal to stop the container, %s by default", signal.DefaultStopSignal))
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR.")
-	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, from Docker: -v /container)")
+	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, from Docker: -v /container, named: -v name:/container)")
 	cmd.Var(&flLinks, []string{"#link", "-link"}, "Add link to another container in the form of name:alias")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container (e.g. --device=/dev/sdc:/dev/xvdc:rwm)")
 
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
 	cmd.Var(&flEnvFile, []string{"-env-file"}, "Read in a line delimited file of environment variables")
+	cmd.Var(&flLabels, []string{"l", "-label"}, "Set meta data on a container")
+	cmd.Var(&flLabelsFile, []string{"-label-file"}, "Read in a line delimited file of labels")
 
 	cmd.Var(&flPublish, []string{"p", "-publish"}, fmt.Sprintf("Publish a container's port to the host\nformat: %s\n(use 'docker port' to see the actual mapping)", nat.PortSpecTemplateFormat))
 	cmd.Var(&flExpose, []string{"#expose", "-expose"}, "Expose a port or a range of ports (e.g. --expose=3300-3310) from the container without publishing it to your host")
@@ -76,11 +94,13 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 	cmd.Var(&flDnsSearch, []string{"-dns-search"}, "Set custom DNS search domains (Use --dns-search=. if you don't wish to set the search domain)")
 	cmd.Var(&flExtraHosts, []string{"-add-host"}, "Add a custom host-to-IP mapping (host:ip)")
 	cmd.Var(&flVolumesFrom, []string{"#volumes-from", "-volumes-from"}, "Mount volumes from the specified container(s)")
+	cmd.Var(&flTmpfs, []string{"-tmpfs"}, "Mount a tmpfs directory (e.g. --tmpfs /run:size=64m,mode=755)")
 	cmd.Var(&flLxcOpts, []string{"#lxc-conf", "-lxc-conf"}, "(lxc exec-driver only) Add custom lxc options --lxc-conf=\"lxc.cgroup.cpuset.cpus = 0,1\"")
 
 	cmd.Var(&flCapAdd, []string{"-cap-add"}, "Add Linux capabilities")
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
 	cmd.Var(&flSecurityOpt, []string{"-security-opt"}, "Security Options")
+	cmd.Var(flLoggingOpts, []string{"-log-opt"}, "Log driver options")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil, nil, cmd, err
@@ -102,7 +122,7 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		attachStderr = flAttach.Get("stderr")
 	)
 
-	if *flNetMode != "bridge" && *flNetMode != "none" && *flHostname != "" {
+	if (NetworkMode(*flNetMode).IsHost() || NetworkMode(*flNetMode).IsContainer()) && *flHostname != "" {
 		return nil, nil, cmd, ErrConflictNetworkHostname
 	}
 
@@ -156,6 +176,21 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		}
 	}
 
+	var tmpfs map[string]string
+	for _, spec := range flTmpfs.GetAll() {
+		dest, options := spec, ""
+		if arr := strings.SplitN(spec, ":", 2); len(arr) == 2 {
+			dest, options = arr[0], arr[1]
+		}
+		if err := ValidateTmpfs(dest, options); err != nil {
+			return nil, nil, cmd, err
+		}
+		if tmpfs == nil {
+			tmpfs = make(map[string]string)
+		}
+		tmpfs[path.Clean(dest)] = options
+	}
+
 	var (
 		parsedArgs = cmd.Args()
 		runCmd     []string
@@ -240,6 +275,18 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 	// parse the '-e' and '--env' after, to allow override
 	envVariables = append(envVariables, flEnv.GetAll()...)
 
+	// collect all the labels for the container, '-l' and '--label' override
+	// the ones read from label files
+	labels := []string{}
+	for _, lf := range flLabelsFile.GetAll() {
+		parsedLabels, err := opts.ParseLabelFile(lf)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		labels = append(labels, parsedLabels...)
+	}
+	labels = append(labels, flLabels.GetAll()...)
+
 	netMode, err := parseNetMode(*flNetMode)
 	if err != nil {
 		return nil, nil, cmd, fmt.Errorf("--net: invalid net mode: %v", err)
@@ -250,6 +297,38 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		return nil, nil, cmd, err
 	}
 
+	securityOpts, err := parseSecurityOpts(flSecurityOpt.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
+	if *flStopSignal != "" {
+		if _, err := signal.ParseSignal(*flStopSignal); err != nil {
+			return nil, nil, cmd, err
+		}
+	}
+
+	var healthConfig *HealthConfig
+	if *flHealthCmd != "" || *flHealthInterval != 0 || *flHealthTimeout != 0 || *flHealthRetries != 0 {
+		if *flHealthInterval < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-interval cannot be negative")
+		}
+		if *flHealthTimeout < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-timeout cannot be negative")
+		}
+		if *flHealthRetries < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-retries cannot be negative")
+		}
+		healthConfig = &HealthConfig{
+			Interval: *flHealthInterval,
+			Timeout:  *flHealthTimeout,
+			Retries:  *flHealthRetries,
+		}
+		if *flHealthCmd != "" {
+			healthConfig.Test = []string{"CMD-SHELL", *flHealthCmd}
+		}
+	}
+
 	config := &Config{
 		Hostname:        hostname,
 		Domainname:      domainname,
@@ -271,7 +350,10 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		Volumes:         flVolumes.GetMap(),
 		Entrypoint:      entrypoint,
 		WorkingDir:      *flWorkingDir,
-		SecurityOpt:     flSecurityOpt.GetAll(),
+		SecurityOpt:     securityOpts,
+		Labels:          convertKVStringsToMap(labels),
+		Healthcheck:     healthConfig,
+		StopSignal:      *flStopSignal,
 	}
 
 	hostConfig := &HostConfig{
@@ -291,6 +373,10 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		CapAdd:          flCapAdd.GetAll(),
 		CapDrop:         flCapDrop.GetAll(),
 		RestartPolicy:   restartPolicy,
+		LogConfig:       LogConfig{Type: *flLoggingDriver, Config: flLoggingOpts.GetAll()},
+		VolumeDriver:    *flVolumeDriver,
+		ReadonlyRootfs:  *flReadonlyRootfs,
+		Tmpfs:           tmpfs,
 	}
 
 	if sysInfo != nil && flMemory > 0 && !sysInfo.SwapLimit {
@@ -305,6 +391,45 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 	return config, hostConfig, cmd, nil
 }
 
+// parseSecurityOpts replaces the paths of the seccomp profiles with their
+// content, the daemon may not have access to the files of the client
+func parseSecurityOpts(securityOpts []string) ([]string, error) {
+	for i, opt := range securityOpts {
+		if !strings.HasPrefix(opt, "seccomp=") && !strings.HasPrefix(opt, "seccomp:") {
+			continue
+		}
+		profile := opt[len("seccomp="):]
+		if profile == "unconfined" {
+			continue
+		}
+		body, err := ioutil.ReadFile(profile)
+		if err != nil {
+			return nil, fmt.Errorf("Opening seccomp profile (%s) failed: %v", profile, err)
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, body); err != nil {
+			return nil, fmt.Errorf("Invalid seccomp profile (%s): %v", profile, err)
+		}
+		securityOpts[i] = "seccomp=" + buf.String()
+	}
+	return securityOpts, nil
+}
+
+// convertKVStringsToMap converts a list of "key=value" strings to a map,
+// a string without "=" is stored as a key with an empty value.
+func convertKVStringsToMap(values []string) map[string]string {
+	result := make(map[string]string, len(values))
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) == 1 {
+			result[kv[0]] = ""
+		} else {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
+
 // parseRestartPolicy returns the parsed policy or an error indicating what is incorrect
 func parseRestartPolicy(policy string) (RestartPolicy, error) {
 	p := RestartPolicy{}
@@ -385,7 +510,10 @@ func parseNetMode(netMode string) (NetworkMode, error) {
 			return "", fmt.Errorf("invalid container format container:<name|id>")
 		}
 	default:
-		return "", fmt.Errorf("invalid --net: %s", netMode)
+		// the name of a user-defined network, checked by the daemon
+		if len(parts) > 1 || mode == "" {
+			return "", fmt.Errorf("invalid --net: %s", netMode)
+		}
 	}
 	return NetworkMode(netMode), nil
 }
@@ -2,6 +2,7 @@ package runconfig
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
 
 	flag "github.com/docker/docker/pkg/mflag"
@@ -57,4 +58,126 @@ func TestNetHostname(t *testing.T) {
 	if _, _, _, err := parseRun([]string{"-h=name", "--net=container:other", "img", "cmd"}, nil); err != ErrConflictNetworkHostname {
 		t.Fatalf("Expected error ErrConflictNetworkHostname, got: %s", err)
 	}
+
+	if _, _, _, err := parseRun([]string{"-h=name", "--net=mynet", "img", "cmd"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestParseNetUserDefined(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--net=mynet", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostConfig.NetworkMode != "mynet" || !hostConfig.NetworkMode.IsUserDefined() || !hostConfig.NetworkMode.IsPrivate() {
+		t.Fatalf("Expected the user-defined network mynet, got %q", hostConfig.NetworkMode)
+	}
+
+	for _, mode := range []NetworkMode{"", "bridge", "host", "none", "container:other"} {
+		if mode.IsUserDefined() {
+			t.Fatalf("Expected %q not to be a user-defined network", mode)
+		}
+	}
+
+	if _, _, _, err := parseRun([]string{"--net=mynet:other", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error for --net=mynet:other")
+	}
+}
+
+func TestParseReadonlyRootfs(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostConfig.ReadonlyRootfs {
+		t.Fatal("Expected a writable root filesystem by default")
+	}
+	if _, hostConfig, _, err = parseRun([]string{"--read-only", "img", "cmd"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !hostConfig.ReadonlyRootfs {
+		t.Fatal("Expected a read only root filesystem with --read-only")
+	}
+}
+
+func TestParseTmpfs(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--tmpfs", "/run", "--tmpfs", "/tmp/:size=64m,exec", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hostConfig.Tmpfs) != 2 || hostConfig.Tmpfs["/run"] != "" || hostConfig.Tmpfs["/tmp"] != "size=64m,exec" {
+		t.Fatalf("Expected tmpfs mounts on /run and /tmp, got %v", hostConfig.Tmpfs)
+	}
+
+	for _, spec := range []string{"run", "/", "/run:size", "/run:bind", "/run:foo=bar"} {
+		if _, _, _, err := parseRun([]string{"--tmpfs", spec, "img", "cmd"}, nil); err == nil {
+			t.Fatalf("Expected an error for --tmpfs %s", spec)
+		}
+	}
+}
+
+func TestParseVolumeDriver(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--volume-driver=lvm", "-v", "data:/data", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostConfig.VolumeDriver != "lvm" {
+		t.Fatalf("Expected the volume driver lvm, got %q", hostConfig.VolumeDriver)
+	}
+	if len(hostConfig.Binds) != 1 || hostConfig.Binds[0] != "data:/data" {
+		t.Fatalf("Expected the named volume data in the binds, got %v", hostConfig.Binds)
+	}
+}
+
+func TestParseSeccompProfile(t *testing.T) {
+	f, err := ioutil.TempFile("", "seccomp-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("{\n\t\"defaultAction\": \"SCMP_ACT_ALLOW\"\n}\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	config, _, _, err := parseRun([]string{"--security-opt", "seccomp=" + f.Name(), "--security-opt", "apparmor:unconfined", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `seccomp={"defaultAction":"SCMP_ACT_ALLOW"}`
+	if len(config.SecurityOpt) != 2 || config.SecurityOpt[0] != expected || config.SecurityOpt[1] != "apparmor:unconfined" {
+		t.Fatalf("Expected the content of the profile in the security options, got %v", config.SecurityOpt)
+	}
+
+	if config, _, _, err = parseRun([]string{"--security-opt", "seccomp=unconfined", "img", "cmd"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.SecurityOpt) != 1 || config.SecurityOpt[0] != "seccomp=unconfined" {
+		t.Fatalf("Expected seccomp=unconfined to be kept, got %v", config.SecurityOpt)
+	}
+
+	if _, _, _, err := parseRun([]string{"--security-opt", "seccomp=/does/not/exist.json", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error for a missing seccomp profile")
+	}
+}
+
+func TestParseStopSignal(t *testing.T) {
+	config, _, _, err := parseRun([]string{"--stop-signal=SIGQUIT", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StopSignal != "SIGQUIT" {
+		t.Fatalf("Expected the stop signal SIGQUIT, got %q", config.StopSignal)
+	}
+
+	if config, _, _, err = parseRun([]string{"img", "cmd"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if config.StopSignal != "" {
+		t.Fatalf("Expected no stop signal, got %q", config.StopSignal)
+	}
+
+	if _, _, _, err := parseRun([]string{"--stop-signal=SIGFOO", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error for an invalid stop signal")
+	}
 }
@@ -0,0 +1,106 @@
+package volumes
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/plugins"
+)
+
+// DefaultDriver is the name of the driver keeping volumes in directories of
+// the docker root.
+const DefaultDriver = "local"
+
+// Driver manages the storage behind volumes.
+type Driver interface {
+	// Create creates the storage of the volume with the given name.
+	Create(name string) error
+	// Remove removes the volume and its data.
+	Remove(name string) error
+	// Path returns the path of the volume on the host, where it is
+	// available once mounted.
+	Path(name string) (string, error)
+	// Mount makes the volume available on the host before it is used by
+	// a container, and returns its path.
+	Mount(name string) (string, error)
+	// Unmount is called when a container using the volume stops.
+	Unmount(name string) error
+}
+
+var (
+	drivers     = make(map[string]Driver)
+	driversLock sync.Mutex
+)
+
+// RegisterDriver makes a volume driver available under the given name.
+func RegisterDriver(name string, driver Driver) error {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+
+	if _, exists := drivers[name]; exists || name == DefaultDriver {
+		return fmt.Errorf("Volume driver already registered: %s", name)
+	}
+	drivers[name] = driver
+	return nil
+}
+
+// lookupDriver returns the registered driver with the given name, or the
+// plugin of that name implementing the VolumeDriver protocol.
+func lookupDriver(name string) (Driver, error) {
+	driversLock.Lock()
+	driver, exists := drivers[name]
+	driversLock.Unlock()
+	if exists {
+		return driver, nil
+	}
+
+	// activating the plugin calls it, the lock is not held meanwhile so
+	// that a slow plugin does not block the other drivers
+	p, err := plugins.Get(name, pluginSubsystem)
+	if err != nil {
+		if err == plugins.ErrNotFound {
+			return nil, fmt.Errorf("No such volume driver: %s", name)
+		}
+		return nil, fmt.Errorf("Error looking up volume driver %s: %v", name, err)
+	}
+
+	driversLock.Lock()
+	defer driversLock.Unlock()
+	if driver, exists := drivers[name]; exists {
+		return driver, nil
+	}
+	driver = &pluginDriver{name: name, client: p.Client}
+	drivers[name] = driver
+	return driver, nil
+}
+
+// localDriver keeps volumes in the directories of a graph driver, vfs by
+// default.
+type localDriver struct {
+	driver graphdriver.Driver
+}
+
+func (d *localDriver) Create(name string) error {
+	return d.driver.Create(name, "")
+}
+
+func (d *localDriver) Remove(name string) error {
+	return d.driver.Remove(name)
+}
+
+func (d *localDriver) Path(name string) (string, error) {
+	path, err := d.driver.Get(name, "")
+	if err != nil {
+		return "", fmt.Errorf("Driver %s failed to get volume rootfs %s: %v", d.driver, name, err)
+	}
+	return path, nil
+}
+
+func (d *localDriver) Mount(name string) (string, error) {
+	return d.Path(name)
+}
+
+func (d *localDriver) Unmount(name string) error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package volumes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/docker/docker/pkg/plugins"
+)
+
+// pluginSubsystem is the protocol implemented by the volume driver plugins:
+//
+//	/VolumeDriver.Create   {"Name": "volume"} -> {"Err": ""}
+//	/VolumeDriver.Remove   {"Name": "volume"} -> {"Err": ""}
+//	/VolumeDriver.Path     {"Name": "volume"} -> {"Mountpoint": "/path", "Err": ""}
+//	/VolumeDriver.Mount    {"Name": "volume"} -> {"Mountpoint": "/path", "Err": ""}
+//	/VolumeDriver.Unmount  {"Name": "volume"} -> {"Err": ""}
+//
+// A non-empty Err fails the operation.
+const pluginSubsystem = "VolumeDriver"
+
+type pluginRequest struct {
+	Name string
+}
+
+type pluginResponse struct {
+	Mountpoint string
+	Err        string
+}
+
+// pluginDriver is a volume driver running out of the daemon.
+type pluginDriver struct {
+	name   string
+	client *plugins.Client
+}
+
+func (d *pluginDriver) call(method, name string) (string, error) {
+	var ret pluginResponse
+	if err := d.client.Call(pluginSubsystem+"."+method, &pluginRequest{Name: name}, &ret); err != nil {
+		return "", err
+	}
+	if ret.Err != "" {
+		return "", errors.New(ret.Err)
+	}
+	return ret.Mountpoint, nil
+}
+
+func (d *pluginDriver) Create(name string) error {
+	_, err := d.call("Create", name)
+	return err
+}
+
+func (d *pluginDriver) Remove(name string) error {
+	_, err := d.call("Remove", name)
+	return err
+}
+
+func (d *pluginDriver) Path(name string) (string, error) {
+	return d.mountpoint("Path", name)
+}
+
+func (d *pluginDriver) Mount(name string) (string, error) {
+	return d.mountpoint("Mount", name)
+}
+
+func (d *pluginDriver) Unmount(name string) error {
+	_, err := d.call("Unmount", name)
+	return err
+}
+
+func (d *pluginDriver) mountpoint(method, name string) (string, error) {
+	path, err := d.call(method, name)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("Volume driver %s returned no mountpoint for %s", d.name, name)
+	}
+	return path, nil
+}
@@ -14,12 +14,32 @@ import (
 
 type Repository struct {
 	configPath string
+	dataPath   string
 	driver     graphdriver.Driver
+	local      Driver
 	volumes    map[string]*Volume
-	lock       sync.Mutex
+	// orphans are the directories of dataPath which have no volume
+	// metadata, by id
+	orphans map[string]string
+	// pending are the names of the volumes being created or removed on
+	// their driver while the lock is released, the channel is closed once
+	// it is done
+	pending map[string]chan struct{}
+	lock    sync.Mutex
 }
 
-func NewRepository(configPath string, driver graphdriver.Driver) (*Repository, error) {
+// PrunedVolume is a volume removed by Prune, or which would be removed by it.
+type PrunedVolume struct {
+	ID   string
+	Name string
+	Path string
+	Size int64
+}
+
+// NewRepository restores the volumes whose metadata is kept in configPath.
+// dataPath is the directory where driver keeps the data of the volumes, the
+// directories it holds without metadata are detected when it is not empty.
+func NewRepository(configPath, dataPath string, driver graphdriver.Driver) (*Repository, error) {
 	abspath, err := filepath.Abs(configPath)
 	if err != nil {
 		return nil, err
@@ -32,45 +52,54 @@ func NewRepository(configPath string, driver graphdriver.Driver) (*Repository, e
 
 	repo := &Repository{
 		driver:     driver,
+		local:      &localDriver{driver: driver},
 		configPath: abspath,
+		dataPath:   dataPath,
 		volumes:    make(map[string]*Volume),
+		orphans:    make(map[string]string),
+		pending:    make(map[string]chan struct{}),
 	}
 
 	return repo, repo.restore()
 }
 
-func (r *Repository) newVolume(path string, writable bool) (*Volume, error) {
-	var (
-		isBindMount bool
-		err         error
-		id          = utils.GenerateRandomID()
-	)
-	if path != "" {
-		isBindMount = true
+// newVolume adds a new volume to the repository, creating it on its driver
+// when path is empty. It is called with the lock held, which is released
+// while the driver is called.
+func (r *Repository) newVolume(path, name, driverName string, writable bool) (*Volume, error) {
+	v := &Volume{
+		ID:          utils.GenerateRandomID(),
+		Name:        name,
+		repository:  r,
+		Writable:    writable,
+		containers:  make(map[string]struct{}),
+		IsBindMount: path != "",
+	}
+	v.configPath = r.configPath + "/" + v.ID
+	if !v.IsBindMount && driverName != DefaultDriver {
+		v.Driver = driverName
 	}
 
 	if path == "" {
-		path, err = r.createNewVolumePath(id)
+		err := r.callDriver(name, func() error {
+			var err error
+			path, err = r.createNewVolumePath(v)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
 	path = filepath.Clean(path)
 
-	path, err = filepath.EvalSymlinks(path)
-	if err != nil {
-		return nil, err
-	}
-
-	v := &Volume{
-		ID:          id,
-		Path:        path,
-		repository:  r,
-		Writable:    writable,
-		containers:  make(map[string]struct{}),
-		configPath:  r.configPath + "/" + id,
-		IsBindMount: isBindMount,
+	// The path of a volume on a driver may only exist once it is mounted
+	if !v.hasExternalDriver() {
+		var err error
+		if path, err = filepath.EvalSymlinks(path); err != nil {
+			return nil, err
+		}
 	}
+	v.Path = path
 
 	if err := v.initialize(); err != nil {
 		return nil, err
@@ -79,6 +108,48 @@ func (r *Repository) newVolume(path string, writable bool) (*Volume, error) {
 	return v, r.add(v)
 }
 
+// callDriver calls f without holding the lock, the driver it calls may be a
+// plugin which is slow to answer. The volume with the given name, if any, is
+// pending meanwhile. It is called with the lock held.
+func (r *Repository) callDriver(name string, f func() error) error {
+	var done chan struct{}
+	if name != "" {
+		done = make(chan struct{})
+		r.pending[name] = done
+	}
+	r.lock.Unlock()
+	err := f()
+	r.lock.Lock()
+	if done != nil {
+		delete(r.pending, name)
+		close(done)
+	}
+	return err
+}
+
+// waitPending waits until the volume with the given name is no longer being
+// created or removed. It is called with the lock held.
+func (r *Repository) waitPending(name string) {
+	for {
+		done, exists := r.pending[name]
+		if !exists {
+			return
+		}
+		r.lock.Unlock()
+		<-done
+		r.lock.Lock()
+	}
+}
+
+// getDriver returns the driver with the given name, the local one when the
+// name is empty.
+func (r *Repository) getDriver(name string) (Driver, error) {
+	if name == "" || name == DefaultDriver {
+		return r.local, nil
+	}
+	return lookupDriver(name)
+}
+
 func (r *Repository) restore() error {
 	dir, err := ioutil.ReadDir(r.configPath)
 	if err != nil {
@@ -87,22 +158,30 @@ func (r *Repository) restore() error {
 
 	for _, v := range dir {
 		id := v.Name()
-		path, err := r.driver.Get(id, "")
-		if err != nil {
-			log.Debugf("Could not find volume for %s: %v", id, err)
-			continue
-		}
 		vol := &Volume{
 			ID:         id,
 			configPath: r.configPath + "/" + id,
 			containers: make(map[string]struct{}),
-			Path:       path,
+			repository: r,
 		}
-		if err := vol.FromDisk(); err != nil {
-			if !os.IsNotExist(err) {
-				log.Debugf("Error restoring volume: %v", err)
+		err := vol.FromDisk()
+		if err != nil && !os.IsNotExist(err) {
+			log.Debugf("Error restoring volume: %v", err)
+			continue
+		}
+		// The volumes of external drivers are not looked up until they
+		// are used, the driver may not be running yet
+		if !vol.hasExternalDriver() {
+			path, err := r.driver.Get(id, "")
+			if err != nil {
+				log.Debugf("Could not find volume for %s: %v", id, err)
 				continue
 			}
+			if vol.Path == "" {
+				vol.Path = path
+			}
+		}
+		if os.IsNotExist(err) {
 			if err := vol.initialize(); err != nil {
 				log.Debugf("%s", err)
 				continue
@@ -112,6 +191,36 @@ func (r *Repository) restore() error {
 			log.Debugf("Error restoring volume: %v", err)
 		}
 	}
+	return r.restoreOrphans(dir)
+}
+
+// restoreOrphans looks for the directories of dataPath which do not belong to
+// any of the volumes in configDir, as left by a daemon which died while
+// removing a volume.
+func (r *Repository) restoreOrphans(configDir []os.FileInfo) error {
+	if r.dataPath == "" {
+		return nil
+	}
+	dir, err := ioutil.ReadDir(r.dataPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	known := make(map[string]struct{}, len(configDir))
+	for _, v := range configDir {
+		known[v.Name()] = struct{}{}
+	}
+	for _, v := range dir {
+		if _, exists := known[v.Name()]; !exists && v.IsDir() {
+			r.orphans[v.Name()] = filepath.Join(r.dataPath, v.Name())
+		}
+	}
+	if len(r.orphans) > 0 {
+		log.Infof("Found %d volume directories without metadata in %s, use docker volume prune to remove them", len(r.orphans), r.dataPath)
+	}
 	return nil
 }
 
@@ -123,6 +232,11 @@ func (r *Repository) Get(path string) *Volume {
 }
 
 func (r *Repository) get(path string) *Volume {
+	// The volumes of external drivers are known by the path the driver
+	// gave, which may not exist while they are not mounted
+	if v, exists := r.volumes[filepath.Clean(path)]; exists {
+		return v
+	}
 	path, err := filepath.EvalSymlinks(path)
 	if err != nil {
 		return nil
@@ -130,6 +244,39 @@ func (r *Repository) get(path string) *Volume {
 	return r.volumes[filepath.Clean(path)]
 }
 
+// GetByName returns the volume with the given name, or id for the volumes
+// which were created without a name. Bind-mounts are not returned.
+func (r *Repository) GetByName(name string) *Volume {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.getByName(name)
+}
+
+func (r *Repository) getByName(name string) *Volume {
+	if name == "" {
+		return nil
+	}
+	for _, v := range r.volumes {
+		if !v.IsBindMount && (v.Name == name || v.ID == name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// List returns the volumes of the repository, bind-mounts excluded.
+func (r *Repository) List() []*Volume {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var volumes []*Volume
+	for _, v := range r.volumes {
+		if !v.IsBindMount {
+			volumes = append(volumes, v)
+		}
+	}
+	return volumes
+}
+
 func (r *Repository) Add(volume *Volume) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -157,15 +304,14 @@ func (r *Repository) remove(volume *Volume) {
 func (r *Repository) Delete(path string) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	path, err := filepath.EvalSymlinks(path)
-	if err != nil {
-		return err
-	}
-	volume := r.get(filepath.Clean(path))
+	volume := r.get(path)
 	if volume == nil {
 		return fmt.Errorf("Volume %s does not exist", path)
 	}
+	return r.delete(volume)
+}
 
+func (r *Repository) delete(volume *Volume) error {
 	if volume.IsBindMount {
 		return fmt.Errorf("Volume %s is a bind-mount and cannot be removed", volume.Path)
 	}
@@ -174,44 +320,158 @@ func (r *Repository) Delete(path string) error {
 		return fmt.Errorf("Volume %s is being used and cannot be removed: used by containers %s", volume.Path, containers)
 	}
 
-	if err := os.RemoveAll(volume.configPath); err != nil {
+	// the volume is removed from the repository first so that no container
+	// can use it while the lock is released
+	r.remove(volume)
+	err := r.callDriver(volume.Name, func() error {
+		driver, err := r.getDriver(volume.Driver)
+		if err != nil {
+			return err
+		}
+		if err := driver.Remove(volume.driverKey()); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return os.RemoveAll(volume.configPath)
+	})
+	if err != nil {
+		r.volumes[volume.Path] = volume
 		return err
 	}
+	return nil
+}
 
-	if err := r.driver.Remove(volume.ID); err != nil {
-		if !os.IsNotExist(err) {
-			return err
+// Prune removes the volumes which are not used by any container, bind-mounts
+// excluded, and the directories without metadata found on startup. When
+// dryRun is set nothing is removed. The volumes which could not be removed are
+// left out of the returned ones.
+func (r *Repository) Prune(dryRun bool) []PrunedVolume {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// the lock is released while the volumes are removed, the candidates
+	// are collected first and checked again before their removal
+	var candidates []*Volume
+	for _, v := range r.volumes {
+		if !v.IsBindMount {
+			candidates = append(candidates, v)
 		}
 	}
 
-	r.remove(volume)
-	return nil
-}
+	var pruned []PrunedVolume
+	for _, v := range candidates {
+		if r.volumes[v.Path] != v || len(v.Containers()) > 0 {
+			continue
+		}
+		p := PrunedVolume{ID: v.ID, Name: v.Name, Path: v.Path}
+		// The volumes of external drivers may not be mounted, their size
+		// is not known
+		if !v.hasExternalDriver() {
+			p.Size = treeSize(v.Path)
+		}
+		if !dryRun {
+			if err := r.delete(v); err != nil {
+				log.Errorf("Error removing volume %s: %v", v.ID, err)
+				continue
+			}
+		}
+		pruned = append(pruned, p)
+	}
 
-func (r *Repository) createNewVolumePath(id string) (string, error) {
-	if err := r.driver.Create(id, ""); err != nil {
-		return "", err
+	for id, path := range r.orphans {
+		p := PrunedVolume{ID: id, Path: path, Size: treeSize(path)}
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				log.Errorf("Error removing volume directory %s: %v", path, err)
+				continue
+			}
+			delete(r.orphans, id)
+		}
+		pruned = append(pruned, p)
 	}
+	return pruned
+}
 
-	path, err := r.driver.Get(id, "")
+func treeSize(path string) int64 {
+	size, err := utils.TreeSize(path)
 	if err != nil {
-		return "", fmt.Errorf("Driver %s failed to get volume rootfs %s: %v", r.driver, id, err)
+		log.Debugf("Error computing the size of %s: %v", path, err)
 	}
+	return size
+}
 
-	return path, nil
+func (r *Repository) createNewVolumePath(v *Volume) (string, error) {
+	driver, err := r.getDriver(v.Driver)
+	if err != nil {
+		return "", err
+	}
+	if err := driver.Create(v.driverKey()); err != nil {
+		return "", err
+	}
+	return driver.Path(v.driverKey())
 }
 
-func (r *Repository) FindOrCreateVolume(path string, writable bool) (*Volume, error) {
+// FindOrCreateVolume returns the volume bind-mounting path, or creates a new
+// volume on the given driver when path is empty. The volume is referenced by
+// containerID before it is returned, so that Prune cannot remove it before
+// the container mounts it.
+func (r *Repository) FindOrCreateVolume(path, driverName string, writable bool, containerID string) (*Volume, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	v, err := r.findOrCreateVolume(path, driverName, writable)
+	if err != nil {
+		return nil, err
+	}
+	v.AddContainer(containerID)
+	return v, nil
+}
+
+func (r *Repository) findOrCreateVolume(path, driverName string, writable bool) (*Volume, error) {
 	if path == "" {
-		return r.newVolume(path, writable)
+		return r.newVolume(path, "", driverName, writable)
 	}
 
 	if v := r.get(path); v != nil {
 		return v, nil
 	}
 
-	return r.newVolume(path, writable)
+	return r.newVolume(path, "", "", writable)
+}
+
+// CreateNamedVolume creates a new volume with the given name on the given
+// driver.
+func (r *Repository) CreateNamedVolume(name, driverName string) (*Volume, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.waitPending(name)
+	if v := r.getByName(name); v != nil {
+		return nil, fmt.Errorf("Conflict, volume %s already exists", name)
+	}
+	return r.newVolume("", name, driverName, true)
+}
+
+// FindOrCreateNamedVolume returns the volume with the given name, creating
+// it on the given driver when it does not exist yet. Like with
+// FindOrCreateVolume, the volume is referenced by containerID.
+func (r *Repository) FindOrCreateNamedVolume(name, driverName, containerID string) (*Volume, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.waitPending(name)
+	v := r.getByName(name)
+	if v != nil {
+		if driverName != "" && v.DriverName() != driverName {
+			return nil, fmt.Errorf("Conflict, volume %s already exists with driver %s", name, v.DriverName())
+		}
+	} else {
+		var err error
+		if v, err = r.newVolume("", name, driverName, true); err != nil {
+			return nil, err
+		}
+	}
+	v.AddContainer(containerID)
+	return v, nil
 }
@@ -0,0 +1,233 @@
+package volumes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+)
+
+func newTestRepository(t *testing.T, root string) *Repository {
+	driver, err := vfs.Init(filepath.Join(root, "vfs"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewRepository(filepath.Join(root, "volumes"), filepath.Join(root, "vfs", "dir"), driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func prunedNames(pruned []PrunedVolume) []string {
+	var names []string
+	for _, v := range pruned {
+		if v.Name != "" {
+			names = append(names, v.Name)
+		} else {
+			names = append(names, v.ID)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPrune(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// A volume directory without metadata
+	orphan := filepath.Join(root, "vfs", "dir", "orphan")
+	if err := os.MkdirAll(orphan, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newTestRepository(t, root)
+	used, err := repo.CreateNamedVolume("used", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	used.AddContainer("container")
+	unused, err := repo.CreateNamedVolume("unused", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(unused.Path, "file"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned := repo.Prune(true)
+	if names := prunedNames(pruned); len(names) != 2 || names[0] != "orphan" || names[1] != "unused" {
+		t.Fatalf("Expected orphan and unused to be pruned, got %v", names)
+	}
+	for _, v := range pruned {
+		if v.Name == "unused" && v.Size != 10 {
+			t.Fatalf("Expected the size of unused to be 10, got %d", v.Size)
+		}
+	}
+	if repo.GetByName("unused") == nil {
+		t.Fatal("A dry run should not remove volumes")
+	}
+	if _, err := os.Stat(orphan); err != nil {
+		t.Fatalf("A dry run should not remove orphan directories: %v", err)
+	}
+
+	if names := prunedNames(repo.Prune(false)); len(names) != 2 {
+		t.Fatalf("Expected orphan and unused to be pruned, got %v", names)
+	}
+	if repo.GetByName("unused") != nil {
+		t.Fatal("Expected unused to be removed")
+	}
+	if _, err := os.Stat(unused.Path); !os.IsNotExist(err) {
+		t.Fatalf("Expected the directory of unused to be removed, got %v", err)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Fatalf("Expected the orphan directory to be removed, got %v", err)
+	}
+	if repo.GetByName("used") == nil {
+		t.Fatal("Expected used to be kept")
+	}
+	if pruned := repo.Prune(false); len(pruned) != 0 {
+		t.Fatalf("Expected nothing left to prune, got %v", prunedNames(pruned))
+	}
+}
+
+func TestRestoreOrphans(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-orphans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	repo := newTestRepository(t, root)
+	v, err := repo.CreateNamedVolume("kept", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.AddContainer("container")
+
+	// Restoring the repository finds no orphan among the directories of
+	// its volumes
+	repo = newTestRepository(t, root)
+	if len(repo.orphans) != 0 {
+		t.Fatalf("Expected no orphan, got %v", repo.orphans)
+	}
+	if repo.GetByName("kept") == nil {
+		t.Fatal("Expected kept to be restored")
+	}
+
+	if err := os.RemoveAll(filepath.Join(root, "volumes", v.ID)); err != nil {
+		t.Fatal(err)
+	}
+	repo = newTestRepository(t, root)
+	if path, exists := repo.orphans[v.ID]; !exists || path != v.Path {
+		t.Fatalf("Expected the directory of the volume without metadata to be an orphan, got %v", repo.orphans)
+	}
+}
+
+func TestPruneKeepsNewVolumes(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	repo := newTestRepository(t, root)
+	anonymous, err := repo.FindOrCreateVolume("", "", true, "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := repo.FindOrCreateNamedVolume("named", "", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the container has not mounted the volumes yet
+	if pruned := repo.Prune(false); len(pruned) != 0 {
+		t.Fatalf("Expected the volumes of the container to be kept, got %v", prunedNames(pruned))
+	}
+
+	anonymous.RemoveContainer("container")
+	named.RemoveContainer("container")
+	if names := prunedNames(repo.Prune(false)); len(names) != 2 {
+		t.Fatalf("Expected both volumes to be pruned once released, got %v", names)
+	}
+}
+
+// blockingDriver is a volume driver whose Create waits until create is closed
+type blockingDriver struct {
+	created chan struct{}
+	create  chan struct{}
+}
+
+func (d *blockingDriver) Create(name string) error {
+	d.created <- struct{}{}
+	<-d.create
+	return nil
+}
+
+func (d *blockingDriver) Remove(name string) error          { return nil }
+func (d *blockingDriver) Path(name string) (string, error)  { return "/blocking/" + name, nil }
+func (d *blockingDriver) Mount(name string) (string, error) { return "/blocking/" + name, nil }
+func (d *blockingDriver) Unmount(name string) error         { return nil }
+
+func TestCreateDoesNotHoldLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	driver := &blockingDriver{created: make(chan struct{}, 2), create: make(chan struct{})}
+	if err := RegisterDriver("blocking", driver); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		driversLock.Lock()
+		delete(drivers, "blocking")
+		driversLock.Unlock()
+	}()
+
+	repo := newTestRepository(t, root)
+	type result struct {
+		v   *Volume
+		err error
+	}
+	results := make(chan result, 2)
+	create := func(containerID string) {
+		v, err := repo.FindOrCreateNamedVolume("slow", "blocking", containerID)
+		results <- result{v, err}
+	}
+	go create("first")
+	<-driver.created
+	go create("second")
+
+	// the repository is usable while the driver creates the volume
+	if _, err := repo.CreateNamedVolume("other", ""); err != nil {
+		t.Fatal(err)
+	}
+	if repo.GetByName("slow") != nil {
+		t.Fatal("Expected slow to not be added before its driver created it")
+	}
+
+	close(driver.create)
+	first, second := <-results, <-results
+	if first.err != nil || second.err != nil {
+		t.Fatalf("Expected both creations to succeed, got %v and %v", first.err, second.err)
+	}
+	if first.v != second.v {
+		t.Fatal("Expected the volume to be created once")
+	}
+	if len(driver.created) != 0 {
+		t.Fatal("Expected the driver to create the volume once")
+	}
+	if containers := first.v.Containers(); len(containers) != 2 {
+		t.Fatalf("Expected the volume to be used by both containers, got %v", containers)
+	}
+}
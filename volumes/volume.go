@@ -2,6 +2,7 @@ package volumes
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -15,6 +16,8 @@ import (
 
 type Volume struct {
 	ID          string
+	Name        string // set for the volumes created by name, which are kept when their containers are removed
+	Driver      string // empty for bind-mounts and the volumes of the local driver
 	Path        string
 	IsBindMount bool
 	Writable    bool
@@ -53,6 +56,65 @@ func (v *Volume) Export(resource, name string) (io.ReadCloser, error) {
 	})
 }
 
+// DriverName returns the name of the driver managing the volume, empty for
+// bind-mounts.
+func (v *Volume) DriverName() string {
+	if v.IsBindMount {
+		return ""
+	}
+	if v.Driver == "" {
+		return DefaultDriver
+	}
+	return v.Driver
+}
+
+func (v *Volume) hasExternalDriver() bool {
+	return !v.IsBindMount && v.Driver != "" && v.Driver != DefaultDriver
+}
+
+// driverKey returns the name of the volume on its driver: the id for local
+// volumes, which are kept in directories named after it, and the name, if
+// any, on the other drivers.
+func (v *Volume) driverKey() string {
+	if v.Name != "" && v.hasExternalDriver() {
+		return v.Name
+	}
+	return v.ID
+}
+
+// Mount asks the driver of the volume to make it available on the host
+// before a container uses it.
+func (v *Volume) Mount() error {
+	if v.IsBindMount {
+		return nil
+	}
+	driver, err := v.repository.getDriver(v.Driver)
+	if err != nil {
+		return err
+	}
+	path, err := driver.Mount(v.driverKey())
+	if err != nil {
+		return err
+	}
+	if v.hasExternalDriver() && filepath.Clean(path) != v.Path {
+		driver.Unmount(v.driverKey())
+		return fmt.Errorf("Volume driver %s mounted %s on %s instead of %s", v.Driver, v.driverKey(), path, v.Path)
+	}
+	return nil
+}
+
+// Unmount tells the driver of the volume that a container using it stopped.
+func (v *Volume) Unmount() error {
+	if v.IsBindMount {
+		return nil
+	}
+	driver, err := v.repository.getDriver(v.Driver)
+	if err != nil {
+		return err
+	}
+	return driver.Unmount(v.driverKey())
+}
+
 func (v *Volume) IsDir() (bool, error) {
 	stat, err := os.Stat(v.Path)
 	if err != nil {
@@ -108,8 +170,10 @@ func (v *Volume) initialize() error {
 	v.lock.Lock()
 	defer v.lock.Unlock()
 
-	if err := v.createIfNotExist(); err != nil {
-		return err
+	if !v.hasExternalDriver() {
+		if err := v.createIfNotExist(); err != nil {
+			return err
+		}
 	}
 
 	if err := os.MkdirAll(v.configPath, 0755); err != nil {